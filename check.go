@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// errStale 已提交的生成文件与重新生成的结果不一致
+var errStale = errors.New("生成文件已过期")
+
+// runCheck 执行 --check，在内存中重新生成并与 out 目录下已有的文件比较
+//
+// 有差异时输出 unified diff 并返回 errStale。
+func runCheck(args []string, stdout io.Writer) error {
+	opts, err := parseStandalone("--check", args, stdout)
+	if err != nil {
+		return err
+	}
+	resp, err := standaloneResponse(opts)
+	if err != nil {
+		return err
+	}
+
+	stale := 0
+	for _, f := range resp.File {
+		// 插入点的目标文件由其他插件生成，单独运行时无法还原其完整内容
+		if f.GetInsertionPoint() != "" {
+			fmt.Fprintf(stdout, "跳过插入点 %s#%s\n", f.GetName(), f.GetInsertionPoint())
+			continue
+		}
+		path := filepath.Join(opts.out, f.GetName())
+		want := []byte(f.GetContent())
+		got, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			got = nil
+		} else if err != nil {
+			return fmt.Errorf("读取 %s 失败: %v", path, err)
+		}
+		if bytes.Equal(got, want) {
+			continue
+		}
+		stale++
+		fmt.Fprint(stdout, unifiedDiff(path, string(got), string(want)))
+	}
+	if stale > 0 {
+		return fmt.Errorf("%w: %d 个文件需要重新生成", errStale, stale)
+	}
+	return nil
+}
@@ -2,4 +2,23 @@ module github.com/lhdbsbz/protoc-gen-service-registry
 
 go 1.25.1
 
-require google.golang.org/protobuf v1.36.10
+require google.golang.org/protobuf v1.36.11
+
+require (
+	github.com/bufbuild/protocompile v0.14.1
+	github.com/google/cel-go v0.31.0
+	golang.org/x/tools v0.49.0
+	google.golang.org/genproto/googleapis/api v0.0.0-20260706201446-f0a921348800
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	cel.dev/expr v0.25.1 // indirect
+	github.com/antlr4-go/antlr/v4 v4.13.1 // indirect
+	go.yaml.in/yaml/v3 v3.0.4 // indirect
+	golang.org/x/exp v0.0.0-20240823005443-9b4947da3948 // indirect
+	golang.org/x/mod v0.39.0 // indirect
+	golang.org/x/sync v0.22.0 // indirect
+	golang.org/x/text v0.37.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260630182238-925bb5da69e7 // indirect
+)
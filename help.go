@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/lhdbsbz/protoc-gen-service-registry/pkg/generator"
+)
+
+// printVersion 输出版本号
+func printVersion(w io.Writer) {
+	fmt.Fprintf(w, "protoc-gen-service-registry %s\n", version)
+}
+
+// printHelp 输出用法、支持的插件参数、内置模板与模板数据结构
+func printHelp(w io.Writer) {
+	printVersion(w)
+	fmt.Fprint(w, `
+protoc 插件，根据 proto 服务定义渲染服务注册代码。通常由 protoc 或 buf 调用:
+
+  protoc --service-registry_out=. --service-registry_opt=template=local_service_center foo.proto
+
+命令行参数:
+  --version  输出版本号
+  --help     输出本帮助
+  --check    根据 FileDescriptorSet 在内存中重新生成，已提交的文件过期时输出 diff 并以非零状态退出:
+             --check -descriptor_set image.binpb -param template=... -out . [files...]
+  --list_outputs
+             根据 FileDescriptorSet 逐行输出将要生成的文件路径（不写出文件），参数同 --check
+  generate   不经过 protoc，根据 FileDescriptorSet 直接写出生成文件并执行 post_hook，参数同 --check；
+             -verify 在最后连同引用的 pb 包对生成的 Go 代码做类型检查，报告错误的位置而不是留给下一次 go build
+  schema     以 Markdown 输出模板数据结构与模板函数（-format json 输出 JSON Schema，-format dump_data 输出 dump_data 的 JSON Schema）
+  init       在当前目录写入示例模板、配置文件与 buf.gen.yaml（-dir 指定目录，-force 覆盖已有文件）
+
+插件参数（--service-registry_opt，格式 key1=value1,key2=value2）:
+`)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, opt := range generator.OptionDocs() {
+		def := opt.Default
+		if def == "" {
+			def = "-"
+		}
+		fmt.Fprintf(tw, "  %s\t默认: %s\t%s\n", opt.Name, def, opt.Usage)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w, "\n内置模板:")
+	for _, name := range generator.BuiltinTemplates() {
+		fmt.Fprintf(w, "  %s\n", name)
+	}
+
+	printSchema(w, "服务模板数据（每个服务渲染一次）", &generator.ServiceInfo{})
+	printSchema(w, "汇总模板数据（每次生成渲染一次）", &generator.AggregateInfo{})
+}
+
+// printSchema 输出模板数据字段
+func printSchema(w io.Writer, title string, data any) {
+	fmt.Fprintf(w, "\n%s:\n", title)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, field := range generator.DataSchema(data) {
+		fmt.Fprintf(tw, "  .%s\t%s\n", field.Name, field.Type)
+	}
+	tw.Flush()
+}
+
+// isTerminal 判断 stdin 是否为终端，此时不是由 protoc 调用
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
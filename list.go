@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lhdbsbz/protoc-gen-service-registry/pkg/generator"
+)
+
+// runListOutputs 执行 --list_outputs，按 dry_run 方式生成并逐行输出将要写出的文件路径
+//
+// 路径相对于输出根目录并按字典序排列，不包括插入点，供 Bazel 等构建系统预先声明输出。
+func runListOutputs(args []string, stdout io.Writer) error {
+	opts, err := parseStandalone("--list_outputs", args, stdout)
+	if err != nil {
+		return err
+	}
+	req, err := descriptorRequest(opts.descriptorSet, opts.param, opts.files)
+	if err != nil {
+		return err
+	}
+	gen, err := newPlugin(req)
+	if err != nil {
+		return err
+	}
+	config, err := generator.ParsePluginOptions(opts.param)
+	if err != nil {
+		return fmt.Errorf("解析插件参数失败: %v", err)
+	}
+	config.DryRun = true
+	config.AllowCommands = true
+	g, err := generator.New(config)
+	if err != nil {
+		return err
+	}
+	if err := g.Generate(gen); err != nil {
+		return err
+	}
+	for _, path := range g.OutputPaths() {
+		fmt.Fprintln(stdout, path)
+	}
+	return nil
+}
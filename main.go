@@ -1,186 +1,152 @@
 package main
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
-	"go/format"
+	"io"
 	"os"
 	"path/filepath"
-	"strings"
-	"text/template"
 
 	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"github.com/lhdbsbz/protoc-gen-service-registry/pkg/generator"
 )
 
-// 插件配置
-type PluginConfig struct {
-	TemplateFile string // 模板文件路径
-	OutputDir    string // 输出目录
-	PackageName  string // 生成的包名
-}
-
-// 服务信息结构体，用于模板渲染
-type ServiceInfo struct {
-	PackageName      string // 生成的包名
-	ServiceName      string // 服务名称
-	ProtoPackageName string // proto包名（用于代码中的类型引用，如 prepare_order.PrepareOrderServiceServer）
-	ProtoImportPath  string // proto导入路径（完整路径，用于 import 语句，如 git.dreame.tech/.../gen/proto/pages/prepare_order）
-}
+// version 插件版本号，发布时通过 -ldflags "-X main.version=v1.2.3" 注入
+var version = "dev"
 
 func main() {
-	protogen.Options{}.Run(func(gen *protogen.Plugin) error {
-		// 解析插件参数
-		var param string
-		if gen.Request.Parameter != nil {
-			param = *gen.Request.Parameter
-		}
-		config, err := parsePluginOptions(param)
-		if err != nil {
-			return fmt.Errorf("解析插件参数失败: %v", err)
-		}
-
-		for _, f := range gen.Files {
-			if !f.Generate {
-				continue
+	generator.PluginVersion = version
+
+	// protoc 调用插件时不带任何命令行参数
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "--version", "-version", "version":
+			printVersion(os.Stdout)
+			return
+		case "--help", "-help", "-h", "help":
+			printHelp(os.Stdout)
+			return
+		case "--check", "-check":
+			if err := runCheck(os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "check: %v\n", err)
+				os.Exit(1)
 			}
-
-			// 查找服务定义
-			for _, service := range f.Services {
-				// 生成服务注册文件
-				if err := generateServiceRegistry(gen, f, service, config); err != nil {
-					return err
-				}
+			return
+		case "--list_outputs", "-list_outputs":
+			if err := runListOutputs(os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "list_outputs: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "generate":
+			if err := runGenerate(os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "generate: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "schema":
+			if err := runSchema(os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "schema: %v\n", err)
+				os.Exit(1)
 			}
+			return
+		case "init":
+			if err := runInit(os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "init: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		default:
+			fmt.Fprintf(os.Stderr, "未知的命令行参数: %s\n\n", os.Args[1])
+			printHelp(os.Stderr)
+			os.Exit(2)
 		}
-		return nil
-	})
-}
-
-// parsePluginOptions 解析插件参数
-func parsePluginOptions(param string) (*PluginConfig, error) {
-	config := &PluginConfig{
-		TemplateFile: "",                     // 必须指定模板文件
-		OutputDir:    "local_service_center", // 默认输出目录
-		PackageName:  "local_service_center", // 默认包名
-	}
-
-	if param == "" {
-		return nil, fmt.Errorf("必须指定插件参数，至少需要 template_file")
 	}
 
-	// 解析参数，格式: key1=value1,key2=value2
-	pairs := strings.Split(param, ",")
-	for _, pair := range pairs {
-		kv := strings.SplitN(pair, "=", 2)
-		if len(kv) != 2 {
-			continue
-		}
-		key := strings.TrimSpace(kv[0])
-		value := strings.TrimSpace(kv[1])
-
-		switch key {
-		case "template_file":
-			config.TemplateFile = value
-		case "output_dir":
-			config.OutputDir = value
-		case "package_name":
-			config.PackageName = value
-		}
+	// 直接在终端运行时输出帮助，而不是一直等待 stdin
+	if isTerminal(os.Stdin) {
+		printHelp(os.Stderr)
+		os.Exit(2)
 	}
 
-	// 验证必需参数
-	if config.TemplateFile == "" {
-		return nil, fmt.Errorf("必须指定 template_file 参数")
+	if err := run(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", filepath.Base(os.Args[0]), err)
+		os.Exit(1)
 	}
-
-	return config, nil
 }
 
-// loadTemplate 加载模板内容
-func loadTemplate(config *PluginConfig) (string, error) {
-	// 检查模板文件是否存在
-	if _, err := os.Stat(config.TemplateFile); os.IsNotExist(err) {
-		return "", fmt.Errorf("模板文件不存在: %s", config.TemplateFile)
-	}
-
-	// 读取模板文件
-	content, err := os.ReadFile(config.TemplateFile)
+// run 从 stdin 读取 CodeGeneratorRequest，向 stdout 写出 CodeGeneratorResponse
+//
+// 这里没有使用 protogen.Options.Run，因为需要在响应中追加插入点文件。
+func run(in io.Reader, out io.Writer) error {
+	input, err := io.ReadAll(in)
 	if err != nil {
-		return "", fmt.Errorf("读取模板文件失败: %v", err)
+		return err
 	}
-
-	return string(content), nil
-}
-
-func generateServiceRegistry(gen *protogen.Plugin, file *protogen.File, service *protogen.Service, config *PluginConfig) error {
-	// 获取完整的导入路径（支持嵌套目录）
-	protoImportPath := string(file.GoImportPath)
-
-	// 使用 protogen 解析的包名（用于代码中的类型引用）
-	protoPackageName := string(file.GoPackageName)
-
-	// 服务名称（去掉 Service 后缀）
-	serviceName := strings.TrimSuffix(string(service.Desc.Name()), "Service")
-
-	// 准备模板数据
-	data := ServiceInfo{
-		PackageName:      config.PackageName,
-		ServiceName:      serviceName,
-		ProtoPackageName: protoPackageName,
-		ProtoImportPath:  protoImportPath,
+	req := &pluginpb.CodeGeneratorRequest{}
+	if err := proto.Unmarshal(input, req); err != nil {
+		return err
 	}
-
-	// 加载模板
-	tmplContent, err := loadTemplate(config)
+	gen, err := newPlugin(req)
 	if err != nil {
-		return fmt.Errorf("加载模板失败: %v", err)
+		return err
 	}
 
-	// 解析模板
-	tmpl, err := template.New("service_registry").Parse(tmplContent)
+	resp := generate(gen, false)
+	output, err := proto.Marshal(resp)
 	if err != nil {
-		return fmt.Errorf("解析模板失败: %v", err)
-	}
-
-	// 生成代码
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return fmt.Errorf("执行模板失败: %v", err)
+		return err
 	}
+	_, err = out.Write(output)
+	return err
+}
 
-	// 格式化代码
-	formatted, err := format.Source(buf.Bytes())
+// newPlugin 创建 protogen 插件并声明支持的特性
+func newPlugin(req *pluginpb.CodeGeneratorRequest) (*protogen.Plugin, error) {
+	gen, err := protogen.Options{}.New(req)
 	if err != nil {
-		return fmt.Errorf("格式化代码失败: %v", err)
+		return nil, err
 	}
 
-	// 生成文件名（转换为小驼峰格式）
-	fileName := fmt.Sprintf("%s.go", toCamelCase(serviceName))
-	outputPath := filepath.Join(config.OutputDir, fileName)
-
-	// 创建输出文件
-	g := gen.NewGeneratedFile(outputPath, "")
-	if _, err := g.Write(formatted); err != nil {
-		return fmt.Errorf("写入文件失败: %v", err)
-	}
-
-	return nil
+	// 声明支持的特性，避免 protoc/buf 拒绝 proto3 optional 与 editions 文件
+	gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL |
+		pluginpb.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS)
+	gen.SupportedEditionsMinimum = descriptorpb.Edition_EDITION_PROTO2
+	gen.SupportedEditionsMaximum = descriptorpb.Edition_EDITION_2023
+	return gen, nil
 }
 
-// toCamelCase 将大驼峰转换为小驼峰格式
-// 例如: "PrepareOrder" -> "prepareOrder", "Order" -> "order", "User" -> "user"
-func toCamelCase(s string) string {
-	if len(s) == 0 {
-		return s
+// generate 执行生成，错误通过响应的 error 字段返回给 protoc；
+// allowCommands 为 true 时允许执行 formatter、post_hook 等外部命令，仅独立运行的子命令传入 true
+func generate(gen *protogen.Plugin, allowCommands bool) *pluginpb.CodeGeneratorResponse {
+	// 解析插件参数
+	config, err := generator.ParsePluginOptions(gen.Request.GetParameter())
+	if err != nil {
+		gen.Error(fmt.Errorf("解析插件参数失败: %v", err))
+		return gen.Response()
 	}
+	config.AllowCommands = allowCommands
 
-	// 如果第一个字符是大写字母，将其转为小写
-	first := s[0]
-	if first >= 'A' && first <= 'Z' {
-		// 将第一个字符转为小写，其余保持不变
-		return string(first+32) + s[1:]
+	g, err := generator.New(config)
+	if err != nil {
+		gen.Error(err)
+		return gen.Response()
 	}
-
-	// 如果第一个字符已经小写，直接返回
-	return s
+	if err := g.Generate(gen); err != nil {
+		// fail_fast=false 收集的错误由 Response 与已生成的文件一起返回
+		var partial generator.GenerateErrors
+		if !errors.As(err, &partial) {
+			gen.Error(err)
+		}
+		return g.Response(gen)
+	}
+	// dry_run 时生成计划输出到 stderr，protoc 与 buf 会原样显示
+	if config.DryRun {
+		g.WritePlan(os.Stderr)
+	}
+	return g.Response(gen)
 }
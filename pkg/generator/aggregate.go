@@ -0,0 +1,531 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// opsTemplate 由 ops 参数启用的运维端点汇总模板
+const opsTemplate = "ops"
+
+// debugServicesTemplate 由 debug_services 参数启用的服务目录调试端点汇总模板
+const debugServicesTemplate = "debug_services"
+
+// provenanceTemplate 由 provenance 参数启用的服务来源汇总模板
+const provenanceTemplate = "provenance"
+
+// aggregateTemplate 汇总模板及其输出文件名
+type aggregateTemplate struct {
+	file string
+	tmpl *template.Template
+}
+
+// AggregateInfo 汇总模板的渲染数据，包含本次生成的所有服务
+type AggregateInfo struct {
+	PackageName string          // 生成的包名
+	Services    []*ServiceInfo  // 按注册顺序排列的服务
+	Versions    []*VersionGroup // 存在多个版本的逻辑服务
+	Imports     []ImportInfo    // 去重后的 proto 包导入
+	// MessageImports 方法请求与响应消息所在、但不在 Imports 中的包，如 google.golang.org/protobuf/types/known/emptypb
+	MessageImports []ImportInfo
+
+	Messages []*MessageType // 方法请求与响应直接或间接用到的消息
+	Enums    []*EnumType    // 上述消息字段用到的枚举
+
+	Tenants  []string      // 租户列表，来自插件参数 tenants
+	Locality *LocalityInfo // 实例的区域与可用区，来自插件参数 region 与 zone，都未指定时为 nil
+
+	Zookeeper *ZookeeperInfo // zookeeper_registry 汇总模板的节点路径与 ACL，来自插件参数 zk_root 与 zk_acl
+	DNSDomain string         // dns_resolver 汇总模板中的集群域名，来自插件参数 dns_domain
+
+	// RegistryBackends multi_registry 汇总模板同时注册的服务中心，如 consul、nacos，按 aggregate_template 中的顺序排列；
+	// 未启用 multi_registry 时为空。非空时各服务中心汇总模板的函数以服务中心名称为前缀，如 consulRegisterInstance
+	RegistryBackends []string
+
+	GatewayUpstreamPort int // apisix.yaml 与 kong.yaml 汇总模板中上游服务的 HTTP 端口，来自插件参数 gateway_upstream_port
+
+	Values map[string]string // 注入模板的常量，来自插件参数 set 与配置文件的 values
+
+	SourceVersion string // proto 源码的版本，来自插件参数 source_version
+	GeneratedAt   string // 生成时间（RFC 3339，UTC），仅在 provenance_timestamp=true 时非空
+
+	Files []*FileInfo // 本次需要生成的 proto 文件，按输入顺序排列，其中的服务与 Services 为同一对象
+
+	// Generated 渲染本模板前已输出的文件（含配置文件 passes 中之前各阶段的输出），按输出顺序排列，
+	// 可据此导入各服务文件所在的包，而不必假定其命名规则
+	Generated []GeneratedFile
+
+	outputDir string // 汇总文件的输出目录
+}
+
+// ImportInfo Go 包导入信息
+type ImportInfo struct {
+	Name string // 包名，与其他包同名时为别名
+	Path string // 导入路径
+}
+
+// NewAggregateInfo 按注册顺序（依赖优先，其次 priority）整理服务，并收集需要导入的 proto 包
+//
+// 服务依赖不存在或存在循环时返回错误。
+func NewAggregateInfo(services []*ServiceInfo, config *PluginConfig) (*AggregateInfo, error) {
+	sorted, err := orderServices(services)
+	if err != nil {
+		return nil, err
+	}
+
+	imports, messageImports := collectImports(sorted)
+	messages, enums := collectTypes(sorted)
+
+	return &AggregateInfo{
+		PackageName: config.PackageName,
+		Services:    sorted,
+		Versions:    versionGroups(sorted),
+		Imports:     imports,
+
+		MessageImports: messageImports,
+
+		Messages: messages,
+		Enums:    enums,
+
+		Tenants:  config.Tenants,
+		Locality: newLocality(config),
+		Values:   newValues(config),
+
+		Zookeeper: newZookeeper(config),
+		DNSDomain: config.DNSDomain,
+
+		RegistryBackends: registryBackends(config.AggregateTemplates),
+
+		GatewayUpstreamPort: config.GatewayUpstreamPort,
+
+		SourceVersion: config.SourceVersion,
+		GeneratedAt:   generatedAt(config),
+
+		outputDir: config.OutputDir,
+	}, nil
+}
+
+// generatedAt 返回 provenance_timestamp=true 时的生成时间
+func generatedAt(config *PluginConfig) string {
+	if !config.ProvenanceTimestamp {
+		return ""
+	}
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+// GeneratedImports 返回 Generated 中 Go 文件所在的包，不含汇总文件自身所在的包，按首次出现的顺序去重
+func (a *AggregateInfo) GeneratedImports() []ImportInfo {
+	var imports []ImportInfo
+	seen := map[string]bool{path.Clean(filepath.ToSlash(a.outputDir)): true}
+	for _, f := range a.Generated {
+		if f.PackageName == "" || seen[f.Dir] {
+			continue
+		}
+		seen[f.Dir] = true
+		imports = append(imports, ImportInfo{Name: f.PackageName, Path: f.ImportPath})
+	}
+	return imports
+}
+
+// HTTPServices 返回声明了 google.api.http 路由的服务，按注册顺序排列
+func (a *AggregateInfo) HTTPServices() []*ServiceInfo {
+	var services []*ServiceInfo
+	for _, s := range a.Services {
+		if s.HasHTTP() {
+			services = append(services, s)
+		}
+	}
+	return services
+}
+
+// GRPCWebServices 返回启用了 gRPC-Web 的服务，按注册顺序排列
+func (a *AggregateInfo) GRPCWebServices() []*ServiceInfo {
+	var services []*ServiceInfo
+	for _, s := range a.Services {
+		if len(s.GRPCWebOrigins) > 0 {
+			services = append(services, s)
+		}
+	}
+	return services
+}
+
+// TwirpServices 返回声明了 (registry.twirp) 的服务，按注册顺序排列
+func (a *AggregateInfo) TwirpServices() []*ServiceInfo {
+	var services []*ServiceInfo
+	for _, s := range a.Services {
+		if s.Twirp {
+			services = append(services, s)
+		}
+	}
+	return services
+}
+
+// FeatureFlagServices 返回服务或方法声明了功能开关的服务，按注册顺序排列
+func (a *AggregateInfo) FeatureFlagServices() []*ServiceInfo {
+	var services []*ServiceInfo
+	for _, s := range a.Services {
+		if s.UsesFeatureFlags() {
+			services = append(services, s)
+		}
+	}
+	return services
+}
+
+// TwirpImports 返回 Twirp 服务所在的包，按首次出现的顺序去重
+func (a *AggregateInfo) TwirpImports() []ImportInfo {
+	imports, _ := collectImports(a.TwirpServices())
+	return imports
+}
+
+// DubboImports 返回 protoc-gen-go-triple 为各服务生成的包，位于 proto Go 包下的 <包名>triple 目录，按首次出现的顺序去重
+//
+// 目录名取 proto 文件实际的 Go 包名，导入时的名称沿用 ProtoPackageName，与其他包同名时为别名加 triple。
+func (a *AggregateInfo) DubboImports() []ImportInfo {
+	var imports []ImportInfo
+	seen := make(map[string]bool)
+	for _, s := range a.Services {
+		if seen[s.ProtoImportPath] {
+			continue
+		}
+		seen[s.ProtoImportPath] = true
+		imports = append(imports, ImportInfo{Name: s.ProtoPackageName + "triple", Path: s.ProtoImportPath + "/" + s.goPackage + "triple"})
+	}
+	return imports
+}
+
+// CacheServices 返回有方法声明了 (registry.cache) 的服务，按注册顺序排列
+func (a *AggregateInfo) CacheServices() []*ServiceInfo {
+	return a.methodServices(func(m *MethodInfo) bool { return m.Cache != nil })
+}
+
+// CacheImports 返回缓存装饰器需要导入的包：服务所在包，以及缓存方法请求与响应消息所在的其他包
+func (a *AggregateInfo) CacheImports() []ImportInfo {
+	return a.methodImports(func(m *MethodInfo) bool { return m.Cache != nil }, true)
+}
+
+// IdempotencyServices 返回有方法声明了 (registry.idempotency) 的服务，按注册顺序排列
+func (a *AggregateInfo) IdempotencyServices() []*ServiceInfo {
+	return a.methodServices(func(m *MethodInfo) bool { return m.Idempotency != nil })
+}
+
+// IdempotencyImports 返回幂等拦截器需要导入的包：声明了幂等键的方法的请求与响应消息所在的包
+func (a *AggregateInfo) IdempotencyImports() []ImportInfo {
+	return a.methodImports(func(m *MethodInfo) bool { return m.Idempotency != nil }, false)
+}
+
+// LROServices 返回有方法返回 google.longrunning.Operation 的服务，按注册顺序排列
+func (a *AggregateInfo) LROServices() []*ServiceInfo {
+	return a.methodServices(func(m *MethodInfo) bool { return m.IsLRO })
+}
+
+// LROImports 返回长时间运行操作轮询函数需要导入的包：服务所在包，以及这些方法请求与响应消息所在的其他包
+func (a *AggregateInfo) LROImports() []ImportInfo {
+	return a.methodImports(func(m *MethodInfo) bool { return m.IsLRO }, true)
+}
+
+// LROOperation 返回 google.longrunning.Operation 消息的 Go 类型信息，没有方法返回该消息时为 nil
+func (a *AggregateInfo) LROOperation() *MessageInfo {
+	for _, s := range a.Services {
+		for _, m := range s.Methods {
+			if m.IsLRO {
+				return m.Output
+			}
+		}
+	}
+	return nil
+}
+
+// FieldMaskServices 返回有遵循 AIP-134 的字段掩码 Update 方法的服务，按注册顺序排列
+func (a *AggregateInfo) FieldMaskServices() []*ServiceInfo {
+	return a.methodServices(func(m *MethodInfo) bool { return m.FieldMask != nil })
+}
+
+// methodServices 返回有方法满足 match 的服务，按注册顺序排列
+func (a *AggregateInfo) methodServices(match func(*MethodInfo) bool) []*ServiceInfo {
+	var services []*ServiceInfo
+	for _, s := range a.Services {
+		if slices.ContainsFunc(s.Methods, match) {
+			services = append(services, s)
+		}
+	}
+	return services
+}
+
+// methodImports 返回满足 match 的方法的请求与响应消息所在的包，withServices 为 true 时先加入这些方法所在服务的包，
+// 按首次出现的顺序去重
+func (a *AggregateInfo) methodImports(match func(*MethodInfo) bool, withServices bool) []ImportInfo {
+	var imports []ImportInfo
+	seen := make(map[string]bool)
+	add := func(name, path string) {
+		if !seen[path] {
+			seen[path] = true
+			imports = append(imports, ImportInfo{Name: name, Path: path})
+		}
+	}
+	services := a.methodServices(match)
+	if withServices {
+		for _, s := range services {
+			add(s.ProtoPackageName, s.ProtoImportPath)
+		}
+	}
+	for _, s := range services {
+		for _, m := range s.Methods {
+			if match(m) {
+				add(m.Input.PackageName, m.Input.ImportPath)
+				add(m.Output.PackageName, m.Output.ImportPath)
+			}
+		}
+	}
+	return imports
+}
+
+// StreamingServices 返回包含流式方法的服务，按注册顺序排列
+func (a *AggregateInfo) StreamingServices() []*ServiceInfo {
+	var services []*ServiceInfo
+	for _, s := range a.Services {
+		for _, m := range s.Methods {
+			if m.ClientStreaming || m.ServerStreaming {
+				services = append(services, s)
+				break
+			}
+		}
+	}
+	return services
+}
+
+// StreamingPayloads 返回流式方法的请求与响应消息，按首次出现的顺序去重
+func (a *AggregateInfo) StreamingPayloads() []*MessageInfo {
+	var payloads []*MessageInfo
+	seen := map[string]bool{}
+	for _, s := range a.Services {
+		for _, m := range s.Methods {
+			if !m.ClientStreaming && !m.ServerStreaming {
+				continue
+			}
+			for _, msg := range []*MessageInfo{m.Input, m.Output} {
+				if !seen[msg.FullName] {
+					seen[msg.FullName] = true
+					payloads = append(payloads, msg)
+				}
+			}
+		}
+	}
+	return payloads
+}
+
+// StreamingMessages 返回流式方法的请求与响应直接或间接用到的消息，不包括 map 键值对消息与 well-known types
+func (a *AggregateInfo) StreamingMessages() []*MessageType {
+	reachable := a.streamingTypes()
+	var messages []*MessageType
+	for _, t := range a.Messages {
+		if reachable[t.FullName] && !t.MapEntry && !isJSONWellKnown(t.FullName) {
+			messages = append(messages, t)
+		}
+	}
+	return messages
+}
+
+// StreamingEnums 返回 StreamingMessages 字段用到的枚举
+func (a *AggregateInfo) StreamingEnums() []*EnumType {
+	reachable := a.streamingTypes()
+	var enums []*EnumType
+	for _, e := range a.Enums {
+		if reachable[e.FullName] {
+			enums = append(enums, e)
+		}
+	}
+	return enums
+}
+
+// streamingTypes 返回从流式方法的请求与响应可达的消息与枚举全限定名
+func (a *AggregateInfo) streamingTypes() map[string]bool {
+	messages := make(map[string]*MessageType, len(a.Messages))
+	for _, t := range a.Messages {
+		messages[t.FullName] = t
+	}
+	reachable := map[string]bool{}
+	var visit func(fullName string)
+	visit = func(fullName string) {
+		if reachable[fullName] {
+			return
+		}
+		reachable[fullName] = true
+		if t, ok := messages[fullName]; ok {
+			for _, f := range t.Fields {
+				if f.TypeFull != "" {
+					visit(f.TypeFull)
+				}
+			}
+		}
+	}
+	for _, s := range a.Services {
+		for _, m := range s.Methods {
+			if m.ClientStreaming || m.ServerStreaming {
+				visit(m.Input.FullName)
+				visit(m.Output.FullName)
+			}
+		}
+	}
+	return reachable
+}
+
+// HTTPImports 返回 HTTP 路由代码需要导入的包：服务所在包，以及一元 HTTP 方法请求消息所在的其他包
+func (a *AggregateInfo) HTTPImports() []ImportInfo {
+	var imports []ImportInfo
+	seen := make(map[string]bool)
+	add := func(name, path string) {
+		if !seen[path] {
+			seen[path] = true
+			imports = append(imports, ImportInfo{Name: name, Path: path})
+		}
+	}
+	services := a.HTTPServices()
+	for _, s := range services {
+		add(s.ProtoPackageName, s.ProtoImportPath)
+	}
+	for _, s := range services {
+		for _, m := range s.Methods {
+			if len(m.HTTP) > 0 && !m.ClientStreaming && !m.ServerStreaming {
+				add(m.Input.PackageName, m.Input.ImportPath)
+			}
+		}
+	}
+	return imports
+}
+
+// UnaryImports 返回引用一元方法请求与响应类型时需要导入的包：所有服务所在包，以及消息所在的其他包
+func (a *AggregateInfo) UnaryImports() []ImportInfo {
+	imports := append([]ImportInfo(nil), a.Imports...)
+	seen := make(map[string]bool)
+	for _, imp := range imports {
+		seen[imp.Path] = true
+	}
+	for _, s := range a.Services {
+		for _, m := range s.Methods {
+			if m.ClientStreaming || m.ServerStreaming {
+				continue
+			}
+			for _, msg := range []*MessageInfo{m.Input, m.Output} {
+				if !seen[msg.ImportPath] {
+					seen[msg.ImportPath] = true
+					imports = append(imports, ImportInfo{Name: msg.PackageName, Path: msg.ImportPath})
+				}
+			}
+		}
+	}
+	return imports
+}
+
+// collectImports 收集服务所在的包与方法消息所在的其他包，均按首次出现的顺序去重
+func collectImports(services []*ServiceInfo) (imports, messageImports []ImportInfo) {
+	seen := make(map[string]bool)
+	for _, s := range services {
+		if seen[s.ProtoImportPath] {
+			continue
+		}
+		seen[s.ProtoImportPath] = true
+		imports = append(imports, ImportInfo{Name: s.ProtoPackageName, Path: s.ProtoImportPath})
+	}
+
+	for _, s := range services {
+		for _, m := range s.Methods {
+			for _, msg := range []*MessageInfo{m.Input, m.Output} {
+				if seen[msg.ImportPath] {
+					continue
+				}
+				seen[msg.ImportPath] = true
+				messageImports = append(messageImports, ImportInfo{Name: msg.PackageName, Path: msg.ImportPath})
+			}
+		}
+	}
+	return imports, messageImports
+}
+
+// loadAggregates 加载汇总模板，输出为小驼峰形式的模板名，如 register_all -> registerAll.go
+//
+// 模板文件去掉扩展名后仍带有扩展名时（如 catalog.yaml.tmpl）直接以其作为输出文件名，
+// 用于生成 YAML、JSON 等非 Go 文件。
+func (g *Generator) loadAggregates() error {
+	add := func(name, content string) error {
+		tmpl, err := newTemplate(name).Parse(content)
+		if err != nil {
+			return fmt.Errorf("解析汇总模板 %s 失败: %v", name, err)
+		}
+		file := name
+		if filepath.Ext(name) == "" {
+			file = snakeToCamel(name) + ".go"
+		}
+		g.aggregates = append(g.aggregates, &aggregateTemplate{file: file, tmpl: tmpl})
+		return nil
+	}
+
+	for _, name := range g.config.AggregateTemplates {
+		content, err := LoadBuiltinTemplate(name)
+		if err != nil {
+			return fmt.Errorf("加载汇总模板失败: %v", err)
+		}
+		if err := add(name, content); err != nil {
+			return err
+		}
+		g.recordTemplate(name, content)
+	}
+	if path := g.config.AggregateTemplateFile; path != "" {
+		content, err := loadTemplateFile(path)
+		if err != nil {
+			return fmt.Errorf("加载汇总模板失败: %v", err)
+		}
+		if err := add(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), content); err != nil {
+			return err
+		}
+		g.warnDeprecatedFields(g.aggregates[len(g.aggregates)-1].tmpl, path)
+		g.recordTemplate(path, content)
+	}
+
+	if g.config.AggregateFile != "" && len(g.aggregates) == 1 {
+		g.aggregates[0].file = g.config.AggregateFile
+	}
+
+	// ops、debug_services、provenance 参数追加的汇总模板不计入 aggregate_file 的判断
+	for _, extra := range []struct {
+		enabled bool
+		name    string
+	}{
+		{g.config.Ops, opsTemplate},
+		{g.config.DebugServices, debugServicesTemplate},
+		{g.config.Provenance, provenanceTemplate},
+	} {
+		if !extra.enabled || slices.Contains(g.config.AggregateTemplates, extra.name) {
+			continue
+		}
+		content, err := LoadBuiltinTemplate(extra.name)
+		if err != nil {
+			return fmt.Errorf("加载汇总模板失败: %v", err)
+		}
+		if err := add(extra.name, content); err != nil {
+			return err
+		}
+		g.recordTemplate(extra.name, content)
+	}
+	return nil
+}
+
+// generateAggregate 渲染汇总模板
+func (g *Generator) generateAggregate(gen *protogen.Plugin, a *aggregateTemplate, data *AggregateInfo) error {
+	raw, err := execute(a.tmpl, data)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(g.config.OutputDir, a.file)
+	formatted, err := g.format(path, raw)
+	if err != nil {
+		return err
+	}
+	return g.writeFile(gen, path, formatted, nil, a.tmpl.Name())
+}
@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// PluginVersion 写入 attestation 的插件版本号，由 main 包在启动时设置
+var PluginVersion = "dev"
+
+// attestationVersion attestation 文件格式的版本，字段不兼容变更时递增
+const attestationVersion = 1
+
+// Attestation 单次生成的来源证明，供发布流水线签名与复现校验
+//
+// 内容只取决于插件版本、参数、模板与输入，不含时间戳；reproducible=true 时不含绝对路径。
+type Attestation struct {
+	Version         int               `json:"version"`          // 文件格式版本
+	Plugin          AttestationPlugin `json:"plugin"`           // 插件名称与版本
+	Parameter       string            `json:"parameter"`        // 插件参数
+	ParameterSHA256 string            `json:"parameter_sha256"` // 插件参数的 SHA-256
+	Templates       []Digest          `json:"templates"`        // 实际使用的模板，按名称排序
+	Inputs          AttestationInputs `json:"inputs"`           // 输入的 proto 文件
+	Outputs         []Digest          `json:"outputs"`          // 生成的文件（插入点以 路径#插入点 表示），按名称排序
+}
+
+// AttestationPlugin 生成所用的插件
+type AttestationPlugin struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// AttestationInputs 生成的输入
+type AttestationInputs struct {
+	Files               []string `json:"files"`                 // 需要生成的 proto 文件，按路径排序
+	DescriptorSetSHA256 string   `json:"descriptor_set_sha256"` // 请求中全部文件描述符（含依赖）按确定性编码后的 SHA-256
+	DataFiles           []Digest `json:"data_files"`            // 模板通过 datafile 读取的数据文件，按名称排序
+}
+
+// Digest 带名称的内容摘要
+type Digest struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+}
+
+// sha256Hex 返回内容的 SHA-256 十六进制摘要
+func sha256Hex(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// recordTemplate 记录实际使用的模板内容摘要，source 为模板名或模板文件路径
+func (g *Generator) recordTemplate(source, content string) {
+	digest := Digest{Name: g.config.stablePath(source), SHA256: sha256Hex([]byte(content))}
+	if !slices.Contains(g.templates, digest) {
+		g.templates = append(g.templates, digest)
+	}
+}
+
+// writeAttestation 将来源证明以 JSON 输出到 attestation 参数指定的文件
+func (g *Generator) writeAttestation(gen *protogen.Plugin) error {
+	if g.config.AttestationFile == "" || g.config.DryRun {
+		return nil
+	}
+	descriptors, err := proto.MarshalOptions{Deterministic: true}.Marshal(&descriptorpb.FileDescriptorSet{File: gen.Request.GetProtoFile()})
+	if err != nil {
+		return fmt.Errorf("编码文件描述符失败: %v", err)
+	}
+	param := g.parameter(gen)
+	attestation := Attestation{
+		Version:         attestationVersion,
+		Plugin:          AttestationPlugin{Name: "protoc-gen-service-registry", Version: PluginVersion},
+		Parameter:       param,
+		ParameterSHA256: sha256Hex([]byte(param)),
+		Templates:       slices.Clone(g.templates),
+		Inputs: AttestationInputs{
+			Files:               slices.Sorted(slices.Values(gen.Request.GetFileToGenerate())),
+			DescriptorSetSHA256: sha256Hex(descriptors),
+			DataFiles:           dataFileDigests(),
+		},
+		Outputs: []Digest{},
+	}
+	for _, f := range g.outputs {
+		name := f.Path
+		if f.InsertionPoint != "" {
+			name += "#" + f.InsertionPoint
+		}
+		attestation.Outputs = append(attestation.Outputs, Digest{Name: name, SHA256: f.SHA256})
+	}
+	byName := func(a, b Digest) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.SHA256, b.SHA256))
+	}
+	slices.SortFunc(attestation.Templates, byName)
+	slices.SortFunc(attestation.Outputs, byName)
+
+	content, err := json.MarshalIndent(attestation, "", "  ")
+	if err != nil {
+		return fmt.Errorf("生成 attestation 失败: %v", err)
+	}
+	if _, err := gen.NewGeneratedFile(g.config.AttestationFile, "").Write(append(content, '\n')); err != nil {
+		return fmt.Errorf("写入文件失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// auditTemplate 审计汇总模板，内置服务模板注册声明了审计的服务时依赖其中的拦截器
+const auditTemplate = "audit_events"
+
+// checkAudit 服务有方法声明了审计，但基础配置与各渲染阶段都未启用 audit_events 汇总模板时：
+// 服务使用的内置模板（包括 (registry.template) 指定的）引用的 CheckAudit 与审计拦截器不存在，生成的代码无法编译，返回错误；
+// 自定义模板只提示，审计拦截器须自行提供
+func (g *Generator) checkAudit(s *ServiceInfo) error {
+	if !s.HasAudit() || g.usesAggregateTemplate(auditTemplate) {
+		return nil
+	}
+	if g.usesBuiltinTemplate(s) {
+		return fmt.Errorf("服务 %s 有方法声明了审计，内置服务模板依赖 audit_events 汇总模板中的审计拦截器，须启用 aggregate_template=audit_events", s.FullName)
+	}
+	g.warn(s.pos, "服务有方法声明了审计，但未启用 audit_events 汇总模板，审计拦截器须自行提供", "service", s.FullName)
+	return nil
+}
+
+// AuditInfo 方法的审计配置
+type AuditInfo struct {
+	Resources []*AuditResource // 审计事件中的资源字段，按声明顺序排列
+
+	err error // 配置不合法，生成时报错
+}
+
+// AuditResource 审计事件中的一个资源字段
+type AuditResource struct {
+	Path  string // 请求字段路径，如 order.id，同时作为审计事件中资源的名称
+	Value string // 从请求 req 中取字段值的 Go 表达式，如 req.GetOrder().GetId()
+}
+
+// newAudit 转换 (registry.audit) 与 (registry.audit_resource)，按请求消息 input 解析资源字段
+func newAudit(enabled bool, resources []string, input *protogen.Message, streaming bool) *AuditInfo {
+	if !enabled && len(resources) == 0 {
+		return nil
+	}
+	info := &AuditInfo{}
+	if streaming && len(resources) > 0 {
+		info.err = fmt.Errorf("流式方法不能声明 audit_resource")
+		return info
+	}
+	for _, path := range resources {
+		path = strings.TrimSpace(path)
+		field, value, err := requestField(input, path)
+		switch {
+		case err != nil:
+			info.err = fmt.Errorf("audit_resource 中的%v", err)
+		case field.Desc.IsMap() || field.Message != nil:
+			info.err = fmt.Errorf("audit_resource 中的字段 %s 只能是标量、枚举或它们的 repeated 字段", path)
+		case slices.ContainsFunc(info.Resources, func(r *AuditResource) bool { return r.Path == path }):
+			info.err = fmt.Errorf("audit_resource 重复声明了 %s", path)
+		}
+		if info.err != nil {
+			return info
+		}
+		info.Resources = append(info.Resources, &AuditResource{Path: path, Value: value})
+	}
+	return info
+}
@@ -0,0 +1,69 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/lhdbsbz/protoc-gen-service-registry/registry"
+)
+
+// CacheInfo 一元方法的响应缓存
+type CacheInfo struct {
+	TTL       time.Duration    // 缓存时长
+	KeyFields []*CacheKeyField // 组成缓存键的请求字段，按声明顺序排列，为空时以整个请求消息为键
+
+	err error // 配置不合法，生成时报错
+}
+
+// CacheKeyField 组成缓存键的请求字段
+type CacheKeyField struct {
+	Path  string // 字段路径，如 filter.tags
+	Value string // 从请求 req 中取字段值的 Go 表达式，如 req.GetFilter().GetTags()
+}
+
+// newCache 转换响应缓存配置，按请求消息 input 解析 key_fields
+func newCache(cache *registry.Cache, input *protogen.Message, streaming bool) *CacheInfo {
+	if cache == nil {
+		return nil
+	}
+	info := &CacheInfo{}
+	ttl, err := parseTimeout(strings.TrimSpace(cache.GetTtl()))
+	switch {
+	case streaming:
+		info.err = fmt.Errorf("流式方法不支持响应缓存")
+	case err != nil || ttl == 0:
+		info.err = fmt.Errorf("ttl 不是合法的正时长: %q", cache.GetTtl())
+	}
+	info.TTL = ttl
+	for _, path := range cache.GetKeyFields() {
+		path = strings.TrimSpace(path)
+		field, err := cacheKeyField(input, path)
+		if err != nil && info.err == nil {
+			info.err = err
+		}
+		if field == nil {
+			continue
+		}
+		if slices.ContainsFunc(info.KeyFields, func(f *CacheKeyField) bool { return f.Path == path }) && info.err == nil {
+			info.err = fmt.Errorf("key_fields 重复声明了 %s", path)
+		}
+		info.KeyFields = append(info.KeyFields, field)
+	}
+	return info
+}
+
+// cacheKeyField 解析 key_fields 中的字段路径，最后一个字段须为标量、枚举或它们的 repeated 字段
+func cacheKeyField(message *protogen.Message, path string) (*CacheKeyField, error) {
+	field, value, err := requestField(message, path)
+	if err != nil {
+		return nil, fmt.Errorf("key_fields 中的%v", err)
+	}
+	if field.Desc.IsMap() || field.Message != nil {
+		return nil, fmt.Errorf("key_fields 中的字段 %s 只能是标量、枚举或它们的 repeated 字段", path)
+	}
+	return &CacheKeyField{Path: path, Value: value}, nil
+}
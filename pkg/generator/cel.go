@@ -0,0 +1,251 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/ext"
+)
+
+// 模板函数 cel 对模板数据求值 CEL（Common Expression Language）表达式，
+// 用于命名规则、过滤等复杂条件，不必为此新增 Go 代码:
+//
+//	{{if cel "RegisteredName.startsWith('pay-') && Methods.exists(m, m.ServerStreaming)" .}}...{{end}}
+//	{{range cel "Methods.filter(m, size(m.HTTP) > 0)" .}}...{{end}}
+//
+// 表达式由 github.com/google/cel-go 编译求值，支持标准 CEL 语法与宏，
+// 另启用字符串扩展（lowerAscii/upperAscii/trim/replace/split/join 等）。
+//
+// 顶层标识符为模板数据的导出字段，self 为模板数据本身，字段名与 Go 字段名一致；
+// time.Duration 字段为 CEL duration，未设置的指针字段读取为零值结构体。
+// 模板数据上只有 celMethods 列出的无副作用方法可以调用，如 self.HasHTTP()。
+
+// celMethods 可在 CEL 中调用的模板数据方法，均为无参数的判断或筛选方法
+var celMethods = map[reflect.Type][]string{
+	reflect.TypeFor[*ServiceInfo](): {
+		"HasHTTP", "HasHTTPRules", "HasStreaming", "HasUnary", "HasDeprecatedMethods",
+		"HasCircuitBreaker", "HasMethodFeatureFlags", "HasAudit", "HasTenantField", "UsesFeatureFlags",
+	},
+	reflect.TypeFor[*AggregateInfo](): {
+		"HTTPServices", "GRPCWebServices", "TwirpServices", "FeatureFlagServices", "CacheServices",
+		"IdempotencyServices", "LROServices", "FieldMaskServices", "StreamingServices",
+	},
+}
+
+// celEnvs 每种模板数据类型对应的 CEL 环境，值为 celEnvEntry
+var celEnvs sync.Map
+
+// celPrograms 已编译的表达式，同一表达式通常会对每个服务求值一次
+var celPrograms sync.Map
+
+type celEnvEntry struct {
+	env *cel.Env
+	err error
+}
+
+type celProgramKey struct {
+	data reflect.Type
+	expr string
+}
+
+// celEval 模板函数 cel 的实现
+func celEval(expr string, data any) (any, error) {
+	dataType := reflect.TypeOf(data)
+	key := celProgramKey{data: dataType, expr: expr}
+	var program cel.Program
+	if cached, ok := celPrograms.Load(key); ok {
+		program = cached.(cel.Program)
+	} else {
+		env, err := celEnv(dataType)
+		if err != nil {
+			return nil, fmt.Errorf("创建 CEL 环境失败: %v", err)
+		}
+		ast, issues := env.Compile(expr)
+		if issues.Err() != nil {
+			return nil, fmt.Errorf("解析 CEL 表达式 %q 失败: %v", expr, issues.Err())
+		}
+		program, err = env.Program(ast)
+		if err != nil {
+			return nil, fmt.Errorf("解析 CEL 表达式 %q 失败: %v", expr, err)
+		}
+		celPrograms.Store(key, program)
+	}
+	out, _, err := program.Eval(celActivation(data))
+	if err != nil {
+		return nil, fmt.Errorf("求值 CEL 表达式 %q 失败: %v", expr, err)
+	}
+	return celNative(out), nil
+}
+
+// celEnv 返回模板数据类型对应的 CEL 环境：声明 self 与各导出字段为变量，
+// 并以成员函数的形式提供 celMethods 中的方法
+func celEnv(dataType reflect.Type) (*cel.Env, error) {
+	if cached, ok := celEnvs.Load(dataType); ok {
+		entry := cached.(celEnvEntry)
+		return entry.env, entry.err
+	}
+	env, err := newCELEnv(dataType)
+	celEnvs.Store(dataType, celEnvEntry{env: env, err: err})
+	return env, err
+}
+
+func newCELEnv(dataType reflect.Type) (*cel.Env, error) {
+	structType := celStructType(dataType)
+	nativeTypes := make([]any, 0, len(celMethods)+1)
+	if structType != nil {
+		nativeTypes = append(nativeTypes, structType)
+	}
+	for receiver := range celMethods {
+		nativeTypes = append(nativeTypes, receiver)
+	}
+	env, err := cel.NewEnv(ext.NativeTypes(nativeTypes...), ext.Strings())
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []cel.EnvOption
+	if structType == nil {
+		opts = append(opts, cel.Variable("self", cel.DynType))
+	} else {
+		typeName := celTypeName(structType)
+		opts = append(opts, cel.Variable("self", cel.ObjectType(typeName)))
+		for _, field := range reflect.VisibleFields(structType) {
+			if !field.IsExported() || field.Name == "self" {
+				continue
+			}
+			if ft, ok := env.CELTypeProvider().FindStructFieldType(typeName, field.Name); ok {
+				opts = append(opts, cel.Variable(field.Name, ft.Type))
+			}
+		}
+	}
+
+	adapter := env.CELTypeAdapter()
+	overloads := make(map[string][]cel.FunctionOpt)
+	for receiver, names := range celMethods {
+		receiverType := cel.ObjectType(celTypeName(celStructType(receiver)))
+		for _, name := range names {
+			method, ok := receiver.MethodByName(name)
+			if !ok || method.Type.NumIn() != 1 || method.Type.NumOut() != 1 {
+				return nil, fmt.Errorf("%s 没有可在 CEL 中调用的方法 %s()", receiver, name)
+			}
+			resultType, err := celResultType(method.Type.Out(0))
+			if err != nil {
+				return nil, fmt.Errorf("方法 %s.%s(): %v", receiver, name, err)
+			}
+			overloads[name] = append(overloads[name], cel.MemberOverload(
+				celTypeName(celStructType(receiver))+"_"+name,
+				[]*cel.Type{receiverType}, resultType,
+				cel.UnaryBinding(celMethodBinding(adapter, receiver, method)),
+			))
+		}
+	}
+	names := make([]string, 0, len(overloads))
+	for name := range overloads {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		opts = append(opts, cel.Function(name, overloads[name]...))
+	}
+	return env.Extend(opts...)
+}
+
+// celMethodBinding 在 CEL 值对应的 Go 值上调用 method
+func celMethodBinding(adapter types.Adapter, receiver reflect.Type, method reflect.Method) func(ref.Val) ref.Val {
+	return func(arg ref.Val) ref.Val {
+		v := reflect.ValueOf(arg.Value())
+		if v.Type() != receiver {
+			if reflect.PointerTo(v.Type()) != receiver {
+				return types.NewErr("%s 没有方法 %s()", v.Type(), method.Name)
+			}
+			ptr := reflect.New(v.Type())
+			ptr.Elem().Set(v)
+			v = ptr
+		}
+		return adapter.NativeToValue(method.Func.Call([]reflect.Value{v})[0].Interface())
+	}
+}
+
+// celResultType 方法返回值在 CEL 中的类型，只支持 celMethods 用到的 bool 与结构体列表
+func celResultType(t reflect.Type) (*cel.Type, error) {
+	switch {
+	case t.Kind() == reflect.Bool:
+		return cel.BoolType, nil
+	case t.Kind() == reflect.Slice && celStructType(t.Elem()) != nil:
+		return cel.ListType(cel.ObjectType(celTypeName(celStructType(t.Elem())))), nil
+	}
+	return nil, fmt.Errorf("不支持返回类型 %s", t)
+}
+
+// celActivation 求值时的变量：self 与模板数据的各导出字段；
+// 与 ext.NativeTypes 读取嵌套字段一致，未设置的结构体指针按零值结构体处理
+func celActivation(data any) map[string]any {
+	vars := map[string]any{"self": data}
+	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return vars
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return vars
+	}
+	for _, field := range reflect.VisibleFields(v.Type()) {
+		if field.IsExported() && field.Name != "self" {
+			fv := v.FieldByIndex(field.Index)
+			if fv.Kind() == reflect.Pointer && fv.IsNil() && fv.Type().Elem().Kind() == reflect.Struct {
+				fv = reflect.New(fv.Type().Elem())
+			}
+			vars[field.Name] = fv.Interface()
+		}
+	}
+	return vars
+}
+
+// celNative 将求值结果转换为模板可用的 Go 值：列表为 []any，map 为 map[any]any，
+// 模板数据中的结构体仍为原来的 Go 值
+func celNative(v ref.Val) any {
+	switch x := v.(type) {
+	case traits.Lister:
+		var list []any
+		for it := x.Iterator(); it.HasNext() == types.True; {
+			list = append(list, celNative(it.Next()))
+		}
+		return list
+	case traits.Mapper:
+		m := make(map[any]any)
+		for it := x.Iterator(); it.HasNext() == types.True; {
+			key := it.Next()
+			m[celNative(key)] = celNative(x.Get(key))
+		}
+		return m
+	}
+	if v == types.NullValue {
+		return nil
+	}
+	return v.Value()
+}
+
+// celStructType 返回结构体或结构体指针对应的结构体类型，其他类型返回 nil
+func celStructType(t reflect.Type) reflect.Type {
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// celTypeName 结构体在 CEL 中的类型名，与 ext.NativeTypes 的命名一致，如 generator.ServiceInfo
+func celTypeName(t reflect.Type) string {
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func celTestService() *ServiceInfo {
+	return &ServiceInfo{
+		ServiceName:       "PayService",
+		RegisteredName:    "pay-service",
+		Tags:              []string{"core", "billing"},
+		Metadata:          map[string]string{"team": "payments"},
+		Weight:            10,
+		HeartbeatInterval: 5 * time.Second,
+		Methods: []*MethodInfo{
+			{Name: "Charge", HTTP: []*HTTPBinding{{Method: "POST", Path: "/v1/charges"}}},
+			{Name: "Watch", ServerStreaming: true},
+			{Name: "Upload", ClientStreaming: true},
+		},
+	}
+}
+
+func TestCELEval(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want any
+	}{
+		// 运算符优先级
+		{"乘法优先于加法", "1 + 2 * 3", int64(7)},
+		{"括号", "(1 + 2) * 3", int64(9)},
+		{"与优先于或", "true || false && false", true},
+		{"比较优先于逻辑", "Weight > 5 && Weight < 20", true},
+		{"一元取反", "!(Weight == 10)", false},
+		{"取负", "-Weight + 12", int64(2)},
+		// null
+		{"null 相等", "null == null", true},
+		{"未设置的指针字段为零值", "Methods[0].RateLimit.QPS == 0.0", true},
+		{"has 检查 map 键", "has(Metadata.team) && !has(Metadata.owner)", true},
+		// in
+		{"列表 in", "'core' in Tags", true},
+		{"列表 not in", "!('edge' in Tags)", true},
+		{"map in", "'team' in Metadata", true},
+		// 三元运算
+		{"三元", "Weight > 5 ? 'heavy' : 'light'", "heavy"},
+		{"嵌套三元", "Weight > 50 ? 'a' : Weight > 5 ? 'b' : 'c'", "b"},
+		// 宏
+		{"exists", "Methods.exists(m, m.ServerStreaming)", true},
+		{"all", "Methods.all(m, m.Name != '')", true},
+		{"exists_one", "Methods.exists_one(m, m.ClientStreaming)", true},
+		{"filter 与 map", "Methods.filter(m, size(m.HTTP) > 0).map(m, m.Name)", []any{"Charge"}},
+		{"map 下标", "Metadata['team']", "payments"},
+		// 字段、字符串与方法
+		{"字符串函数", "RegisteredName.startsWith('pay-') && ServiceName.lowerAscii() == 'payservice'", true},
+		{"split 与 join", "RegisteredName.split('-').join('_')", "pay_service"},
+		{"self", "self.ServiceName", "PayService"},
+		{"duration", "HeartbeatInterval == duration('5s')", true},
+		{"允许的方法", "self.HasHTTP() && !self.HasCircuitBreaker()", true},
+		{"列表元素上的方法", "Methods[0].HTTP[0].Path", "/v1/charges"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := celEval(tt.expr, celTestService())
+			if err != nil {
+				t.Fatalf("celEval(%q) 失败: %v", tt.expr, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("celEval(%q) = %#v，期望 %#v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCELEvalStructResult(t *testing.T) {
+	svc := celTestService()
+	got, err := celEval("Methods.filter(m, m.ClientStreaming)", svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, ok := got.([]any)
+	if !ok || len(list) != 1 || list[0] != svc.Methods[2] {
+		t.Errorf("结果应为原 *MethodInfo，实际为 %#v", got)
+	}
+}
+
+func TestCELEvalAggregateMethods(t *testing.T) {
+	agg := &AggregateInfo{Services: []*ServiceInfo{celTestService(), {ServiceName: "EmptyService"}}}
+	got, err := celEval("self.StreamingServices().map(s, s.ServiceName)", agg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []any{"PayService"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("得到 %#v，期望 %#v", got, want)
+	}
+	if _, err := celEval("self.HTTPImports()", agg); err == nil || !strings.Contains(err.Error(), "解析 CEL 表达式") {
+		t.Errorf("不在白名单的方法应无法解析，实际错误为 %v", err)
+	}
+}
+
+func TestCELEvalNilPointerField(t *testing.T) {
+	got, err := celEval("RateLimit.Burst == 0 && Name == 'Charge'", &MethodInfo{Name: "Charge"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != true {
+		t.Errorf("未设置的顶层指针字段应读取为零值结构体，得到 %#v", got)
+	}
+}
+
+func TestCELEvalErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want string
+	}{
+		{"语法错误", "Weight +", "解析 CEL 表达式"},
+		{"未知标识符", "NoSuchField == 1", "解析 CEL 表达式"},
+		{"未导出字段", "pos == ''", "解析 CEL 表达式"},
+		{"类型不匹配", "Weight + 'a'", "解析 CEL 表达式"},
+		{"小写字段名", "serviceName == ''", "解析 CEL 表达式"},
+		{"除零", "Weight / 0", "求值 CEL 表达式"},
+		{"下标越界", "Methods[5].Name", "求值 CEL 表达式"},
+		{"map 缺少键", "Metadata['owner']", "求值 CEL 表达式"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := celEval(tt.expr, celTestService())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("celEval(%q) 的错误为 %v，期望包含 %q", tt.expr, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package generator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// compactPackage compact=package 时同一目录下的 Go 输出合并为一个文件
+const compactPackage = "package"
+
+// compactFileName 合并后的 Go 文件名
+const compactFileName = "registry_compact.go"
+
+// compactTemplate 合并后的文件在报告中记录的模板
+const compactTemplate = "compact"
+
+// protocResponseLimit protoc 读取插件响应的上限：protobuf 消息不能超过 2 GiB
+const protocResponseLimit = math.MaxInt32
+
+// deferred 输出是否留待合并，compact=package 时 Go 文件在全部阶段结束后按目录合并输出
+func (g *Generator) deferred(path string) bool {
+	return g.config.Compact == compactPackage && filepath.Ext(path) == ".go"
+}
+
+// gzipArtifact gzip_artifacts=true 时以 gzip 压缩非 Go 文件，输出路径追加 .gz
+func (g *Generator) gzipArtifact(path string, content []byte) (string, []byte, error) {
+	if !g.config.GzipArtifacts || filepath.Ext(path) == ".go" {
+		return path, content, nil
+	}
+	var buf bytes.Buffer
+	// 不写入文件名与修改时间，压缩结果只取决于内容
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := zw.Write(content); err != nil {
+		return "", nil, fmt.Errorf("压缩 %s 失败: %v", path, err)
+	}
+	if err := zw.Close(); err != nil {
+		return "", nil, fmt.Errorf("压缩 %s 失败: %v", path, err)
+	}
+	return path + ".gz", buf.Bytes(), nil
+}
+
+// compactOutputs compact=package 时将各阶段留待合并的 Go 文件按目录合并输出，
+// 目录下只有一个文件时按原路径输出；合并后的文件替代原文件计入报告与清单
+func (g *Generator) compactOutputs(gen *protogen.Plugin) error {
+	var dirs []string
+	byDir := map[string][]PlannedFile{}
+	for _, f := range g.outputs {
+		if f.content == nil {
+			continue
+		}
+		dir := path.Dir(filepath.ToSlash(f.Path))
+		if _, ok := byDir[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		byDir[dir] = append(byDir[dir], f)
+	}
+
+	// proto 生成的 Go 包名不一定与导入路径的最后一段相同，如 order/v1 的包名可能为 orderv1
+	packageNames := map[string]string{}
+	for _, f := range gen.Files {
+		packageNames[string(f.GoImportPath)] = string(f.GoPackageName)
+	}
+
+	merged := map[string]PlannedFile{}
+	for _, dir := range dirs {
+		files := byDir[dir]
+		if len(files) == 1 {
+			continue
+		}
+		target := path.Join(dir, compactFileName)
+		if slices.ContainsFunc(g.outputs, func(f PlannedFile) bool { return f.Path == target }) {
+			return fmt.Errorf("compact=package 合并 %s 失败: 已存在同名输出 %s", dir, target)
+		}
+		content, err := mergeGoFiles(files, packageNames)
+		if err != nil {
+			return fmt.Errorf("compact=package 合并 %s 失败: %v", dir, err)
+		}
+		f := newPlannedFile(target, content, nil, compactTemplate)
+		f.content = content
+		merged[dir] = f
+	}
+
+	outputs := make([]PlannedFile, 0, len(g.outputs))
+	written := map[string]bool{}
+	for _, f := range g.outputs {
+		if f.content == nil {
+			outputs = append(outputs, f)
+			continue
+		}
+		if m, ok := merged[path.Dir(filepath.ToSlash(f.Path))]; ok {
+			// 同一目录的文件只在第一个文件的位置输出一次合并结果
+			if written[m.Path] {
+				continue
+			}
+			written[m.Path] = true
+			f = m
+		}
+		g.log.Debug("生成文件", "path", f.Path, "template", f.Template, "dry_run", g.config.DryRun)
+		if !g.config.DryRun {
+			if _, err := gen.NewGeneratedFile(f.Path, "").Write(f.content); err != nil {
+				return fmt.Errorf("写入文件失败: %v", err)
+			}
+		}
+		f.content = nil
+		outputs = append(outputs, f)
+	}
+	g.outputs = outputs
+	return nil
+}
+
+// mergeGoFiles 将同一包的多个 Go 文件合并为一个，导入去重，导入名冲突时改用别名；
+// 顶层标识符重复时返回错误，而不是输出无法编译的代码
+//
+// packageNames 为已知导入路径的包名，其他未指定导入名的包按导入路径推断包名。
+func mergeGoFiles(files []PlannedFile, packageNames map[string]string) ([]byte, error) {
+	type goImport struct{ name, path string }
+	var (
+		pkg     string
+		header  []byte
+		imports []goImport
+		bodies  bytes.Buffer
+	)
+	importPaths := map[string]string{} // 导入名 -> 导入路径
+	declared := map[string]string{}    // 顶层标识符 -> 所在文件
+	for i, f := range files {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, f.Path, f.content, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("解析 %s 失败: %v", f.Path, err)
+		}
+		if i == 0 {
+			pkg = file.Name.Name
+			header = f.content[:fset.Position(file.Package).Offset]
+		} else if file.Name.Name != pkg {
+			return nil, fmt.Errorf("%s 的包名 %s 与 %s 的包名 %s 不同", f.Path, file.Name.Name, files[0].Path, pkg)
+		}
+
+		// 导入名与之前的文件冲突时改用带序号的别名，并改写本文件中对该包的引用
+		renames := map[string]string{}
+		for _, spec := range file.Imports {
+			importPath, _ := strconv.Unquote(spec.Path.Value)
+			name, ok := packageNames[importPath]
+			if !ok {
+				name = importName(importPath)
+			}
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			if name != "_" && name != "." {
+				if other, ok := importPaths[name]; ok && other != importPath {
+					alias := name
+					for n := 2; importPaths[alias] != "" && importPaths[alias] != importPath; n++ {
+						alias = name + strconv.Itoa(n)
+					}
+					renames[name] = alias
+					name = alias
+				}
+				importPaths[name] = importPath
+			}
+			if imp := (goImport{name, importPath}); !slices.Contains(imports, imp) {
+				imports = append(imports, imp)
+			}
+		}
+
+		for _, name := range topLevelNames(file) {
+			if other, ok := declared[name]; ok {
+				return nil, fmt.Errorf("%s 与 %s 都声明了 %s", other, f.Path, name)
+			}
+			declared[name] = f.Path
+		}
+
+		// 正文从最后一个 import 声明（没有时为 package 声明）之后开始
+		end := file.Name.End()
+		for _, decl := range file.Decls {
+			if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+				end = gen.End()
+			}
+		}
+		fmt.Fprintf(&bodies, "\n// 以下来自 %s\n", f.Path)
+		bodies.Write(renamePackageRefs(file, fset, f.content, fset.Position(end).Offset, renames))
+	}
+
+	var out bytes.Buffer
+	out.Write(header)
+	fmt.Fprintf(&out, "package %s\n", pkg)
+	// 标准库在前，其余包在后，各自按导入路径排序
+	isStd := func(imp goImport) bool { return !strings.Contains(strings.Split(imp.path, "/")[0], ".") }
+	slices.SortStableFunc(imports, func(a, b goImport) int {
+		if isStd(a) != isStd(b) {
+			if isStd(a) {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.path, b.path)
+	})
+	if len(imports) > 0 {
+		out.WriteString("\nimport (\n")
+		for i, imp := range imports {
+			if i > 0 && isStd(imports[i-1]) != isStd(imp) {
+				out.WriteString("\n")
+			}
+			known, ok := packageNames[imp.path]
+			if !ok {
+				known = path.Base(imp.path)
+			}
+			if imp.name == known {
+				fmt.Fprintf(&out, "\t%q\n", imp.path)
+			} else {
+				fmt.Fprintf(&out, "\t%s %q\n", imp.name, imp.path)
+			}
+		}
+		out.WriteString(")\n")
+	}
+	out.Write(bodies.Bytes())
+	return formatSource(out.Bytes())
+}
+
+// renamePackageRefs 返回 content 从 start 开始的部分，其中对包的引用 pkg.X 按 renames 改写包名
+func renamePackageRefs(file *ast.File, fset *token.FileSet, content []byte, start int, renames map[string]string) []byte {
+	if len(renames) == 0 {
+		return content[start:]
+	}
+	var out bytes.Buffer
+	at := start
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		// 未解析到本地声明的 pkg.X 中的 pkg 视为对包的引用
+		id, ok := sel.X.(*ast.Ident)
+		if !ok || id.Obj != nil {
+			return true
+		}
+		alias, ok := renames[id.Name]
+		if offset := fset.Position(id.Pos()).Offset; ok && offset >= at {
+			out.Write(content[at:offset])
+			out.WriteString(alias)
+			at = offset + len(id.Name)
+		}
+		return true
+	})
+	out.Write(content[at:])
+	return out.Bytes()
+}
+
+// topLevelNames 返回文件中的顶层标识符，不含方法、init 与 _
+func topLevelNames(file *ast.File) []string {
+	var names []string
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil && decl.Name.Name != "init" {
+				names = append(names, decl.Name.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					names = append(names, spec.Name.Name)
+				case *ast.ValueSpec:
+					for _, id := range spec.Names {
+						names = append(names, id.Name)
+					}
+				}
+			}
+		}
+	}
+	return slices.DeleteFunc(names, func(name string) bool { return name == "_" })
+}
+
+// checkResponseSize 检查插件响应的大小，超过 max_response_size 时返回错误，接近时提示
+//
+// 超过 protoc 上限的响应会使 protoc 只报告无法解析插件输出，这里提前给出原因与可用的缩减方式。
+func (g *Generator) checkResponseSize(size int) error {
+	limit := g.config.MaxResponseSize
+	if limit <= 0 {
+		limit = protocResponseLimit
+	}
+	hint := "可指定 compact=package 按目录合并 Go 文件、gzip_artifacts=true 压缩非 Go 文件，或拆分输入的 proto 文件分多次生成"
+	if size > limit {
+		return fmt.Errorf("插件响应大小 %s 超过上限 %s，%s", formatSize(size), formatSize(limit), hint)
+	}
+	if size > limit/10*8 {
+		g.log.Warn("插件响应接近大小上限，"+hint, "size", formatSize(size), "limit", formatSize(limit))
+	}
+	return nil
+}
+
+// sizeUnits max_response_size 支持的单位
+var sizeUnits = []struct {
+	suffix string
+	bytes  int
+}{
+	{"GiB", 1 << 30},
+	{"MiB", 1 << 20},
+	{"KiB", 1 << 10},
+	{"B", 1},
+}
+
+// parseSize 解析字节数，可带 KiB、MiB、GiB 单位，如 512MiB
+func parseSize(v string) (int, error) {
+	number, unit := v, 1
+	for _, u := range sizeUnits {
+		if trimmed, ok := strings.CutSuffix(v, u.suffix); ok {
+			number, unit = trimmed, u.bytes
+			break
+		}
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(number))
+	if err != nil || n < 0 || n > protocResponseLimit/unit {
+		return 0, fmt.Errorf("不是合法的大小: %s", v)
+	}
+	return n * unit, nil
+}
+
+// formatSize 以最大的整除单位输出字节数，与 parseSize 互逆
+func formatSize(n int) string {
+	for _, u := range sizeUnits {
+		if n >= u.bytes && n%u.bytes == 0 {
+			return strconv.Itoa(n/u.bytes) + u.suffix
+		}
+	}
+	return strconv.Itoa(n) + "B"
+}
@@ -0,0 +1,976 @@
+package generator
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTemplate 未指定任何模板时使用的内置模板
+const DefaultTemplate = "local_service_center"
+
+// PluginConfig 插件配置
+type PluginConfig struct {
+	Template       string // 内置模板名称
+	TemplateFile   string // 模板文件路径，优先于内置模板
+	TemplateInline string // base64 编码的模板内容，优先级最高
+	OutputDir      string // 输出目录
+	Scope          string // 主模板的渲染范围: service 每个服务一次，file 每个 proto 文件一次，all 每次生成一次
+	OutputExt      string // 服务模板输出文件的扩展名，默认 .go，非 .go 时不执行 gofmt
+	PackageName    string // 生成的包名
+	PackageMode    string // 服务文件的 Go 包划分方式: single 或 per_proto
+
+	GoMod         string         // 生成的 go.mod 的模块路径，非空时在输出目录生成 go.mod 与 doc.go
+	GoModRequires []GoModRequire // 写入 go.mod 的依赖
+
+	FuncsPlugins []string // 提供自定义模板函数的 Go 插件（.so）路径
+
+	DataDir string // 模板函数 datafile 读取数据文件的目录，为空时不能使用 datafile
+
+	GRPCStubs        string // 服务是否生成了 gRPC 代码: assume、detect 或 none
+	GRPCStubsMissing string // 缺少 gRPC 代码的服务的处理方式: skip 或 render
+
+	Values map[string]string // 注入模板的常量，来自 set 参数与配置文件的 values，模板中以 .Values.key 引用
+
+	ServiceTemplates []string // 额外的服务级内置模板，参数中以 + 分隔，每个服务各输出一个文件，如 deploy.yaml
+
+	AggregateTemplates    []string // 汇总模板的内置模板名称，参数中以 + 分隔，如 registry_lifecycle+consul_registry
+	AggregateTemplateFile string   // 汇总模板文件路径，可与内置汇总模板同时使用
+	AggregateFile         string   // 汇总文件名，仅在只有一个汇总模板时可用，默认由模板名推导
+
+	HeartbeatInterval time.Duration // 服务中心心跳间隔，为 0 时由各服务中心模板决定
+
+	Tenants        []string                     // 租户列表，非空时模板为每个服务额外生成按租户注册的代码
+	TenantMetadata map[string]map[string]string // 各租户附加的服务元数据，键为租户名
+
+	Locality LocalityInfo // 服务实例的区域与可用区，来自 region 与 zone
+
+	ConnPoolSize int // conn_manager 汇总模板中每个服务的默认连接数
+
+	ZookeeperRoot string          // zookeeper_registry 汇总模板中实例节点的根路径
+	ZookeeperACLs []*ZookeeperACL // zookeeper_registry 汇总模板创建节点使用的 ACL
+
+	DNSDomain string // dns_resolver 与 istio.yaml 模板中服务域名的集群域名后缀
+
+	SPIFFETrustDomain string // 服务 SPIFFE ID 的信任域
+
+	GatewayUpstreamPort int // apisix.yaml 与 kong.yaml 汇总模板中上游服务的 HTTP 端口
+
+	SchemaModule string // schema_manifest.yaml 汇总模板中服务默认推送到的 schema registry 模块
+
+	Registration string // 服务的注册方式: lazy 只生成显式调用的 Register 函数，eager 在 init 中登记；为空时由模板决定
+	ForbidInit   bool   // 生成的 Go 代码（含插入点代码）中出现 init 函数时生成失败
+
+	Ops bool // 额外生成运维端点注册文件 ops.go（pprof、healthz、readyz、构建信息）
+
+	DebugServices bool // 额外生成 debugServices.go，以 JSON 或 HTML 输出服务目录
+
+	Provenance          bool   // 额外生成 provenance.go，记录每个服务来自哪个 proto 文件
+	SourceVersion       string // proto 源码的版本，如 git describe 的输出，写入 provenance.go
+	ProvenanceTimestamp bool   // 在 provenance.go 中记录生成时间，会使每次生成的内容不同
+
+	Reproducible bool // 输出中不包含时间戳、绝对路径等与机器和环境有关的内容，保证不同机器上逐字节一致
+
+	PackageIndex bool // 为包含多个服务的 proto 包额外生成 <包名>_index.go
+
+	DocsDir string // 服务文档输出目录，非空时为每个服务生成 <注册名>/README.md 与 OWNERS
+
+	FailFast bool // 遇到第一个错误即停止；为 false 时收集各服务的错误，其余服务照常生成
+
+	DataVersion int // 模板数据模型的版本，见 DataVersion1、DataVersion2
+
+	Warnings string // 警告的处理方式: warn 输出到 stderr，error 时生成失败，off 不输出
+
+	DryRun   bool       // 只输出生成计划，不写出任何文件
+	LogLevel slog.Level // 输出到 stderr 的日志级别
+
+	ReportFile string // JSON 生成报告的输出路径，为空时不生成
+
+	ManifestFile string // JSON 输入输出清单的输出路径，为空时不生成
+
+	AttestationFile string // JSON 来源证明的输出路径，为空时不生成
+
+	Compact         string // 为 package 时同一目录下的 Go 输出合并为一个文件，减少响应中的文件数
+	GzipArtifacts   bool   // 以 gzip 压缩非 Go 输出，输出路径追加 .gz
+	MaxResponseSize int    // 插件响应的大小上限（字节），超过时报错，为 0 时使用 protoc 的上限
+
+	DumpDataFile string // 模板数据的 JSON 输出路径，为空时不生成
+
+	PostHooks []string // 生成后执行的命令，仅 generate 子命令执行，插件模式下报错
+
+	Formatters map[string]string // 按扩展名（如 .yaml）指定的外部格式化命令，.go 默认使用 gofmt
+
+	// AllowCommands 是否允许通过 shell 执行 formatter、post_hook 等外部命令，不能通过插件参数设置：
+	// 由 generate、--check 等子命令置为 true；protoc/buf 调用插件时参数可能来自远程构建，
+	// 且 buf 远程插件镜像中没有 shell，因此保持 false
+	AllowCommands bool
+
+	NamingRules []NamingRule // 命名规则，违反时生成失败
+
+	ConfigFile string // YAML 配置文件路径，其中的参数会被命令行插件参数覆盖
+
+	// Passes 配置文件中 passes 声明的额外渲染阶段，在基础渲染之后依次执行，
+	// 例如同一次调用中先按服务生成、再生成包索引与全局 RegisterAll
+	Passes     []*PluginConfig
+	passValues []map[string]any // 配置文件中 passes 的原始参数，命令行参数应用后再生成 Passes
+
+	InsertionSuffix string // 插入点目标文件后缀，没有默认值，模板定义插入点时必须指定
+	Module          string // protogen 的 module 参数，用于计算插入点目标文件名
+}
+
+// DefaultPluginConfig 返回填充了默认值的插件配置
+func DefaultPluginConfig() *PluginConfig {
+	return &PluginConfig{
+		Template:    DefaultTemplate,        // 默认使用内置模板
+		OutputDir:   "local_service_center", // 默认输出目录
+		OutputExt:   ".go",                  // 默认输出 Go 代码
+		Scope:       scopeService,           // 默认每个服务渲染一次
+		PackageName: "local_service_center", // 默认包名
+		PackageMode: packageModeSingle,      // 默认所有服务输出到同一个包
+
+		ConnPoolSize: 1, // 默认每个服务一个连接
+
+		ZookeeperRoot: defaultZookeeperRoot, // 默认实例节点为 /services/<注册名称>/<host:port>
+		DNSDomain:     defaultDNSDomain,     // 默认为 Kubernetes 的 cluster.local
+
+		SPIFFETrustDomain: defaultSPIFFETrustDomain, // 默认与 Istio 的信任域 cluster.local 一致
+
+		GatewayUpstreamPort: 8080, // 默认 HTTP 转码服务监听 8080 端口
+
+		DataVersion: DataVersion1,   // 默认保持原有数据结构
+		FailFast:    true,           // 默认遇到错误立即停止
+		Warnings:    warningsWarn,   // 默认只输出警告
+		LogLevel:    slog.LevelWarn, // 默认只输出警告
+	}
+}
+
+// pluginOption 插件参数定义，同时用于解析与 --help 输出
+type pluginOption struct {
+	name     string                                    // 参数名
+	usage    string                                    // 参数说明
+	set      func(c *PluginConfig, value string) error // 写入配置
+	get      func(c *PluginConfig) string              // 读取配置，用于展示默认值
+	repeated bool                                      // 可重复指定，每次追加一项，配置文件中对应列表
+}
+
+// pluginOptions 支持的插件参数，按说明文档中的展示顺序排列
+var pluginOptions = []pluginOption{
+	{
+		name:  "config",
+		usage: "YAML 配置文件路径，键名与插件参数相同，命令行参数优先",
+		set: func(c *PluginConfig, v string) error {
+			return fmt.Errorf("config 不能在配置文件中嵌套使用")
+		},
+		get: func(c *PluginConfig) string { return c.ConfigFile },
+	},
+	{
+		name:  passesKey,
+		usage: "额外的渲染阶段列表，每一项为一组插件参数（如 scope、template_file、aggregate_template），只能在配置文件中声明",
+		set: func(c *PluginConfig, v string) error {
+			return fmt.Errorf("passes 只能在配置文件中声明")
+		},
+		get: func(c *PluginConfig) string { return "" },
+	},
+	{
+		name:  "template",
+		usage: "内置模板名称，none 表示不生成服务文件，只输出 service_template、docs_dir 与汇总模板",
+		set:   func(c *PluginConfig, v string) error { c.Template = v; return nil },
+		get:   func(c *PluginConfig) string { return c.Template },
+	},
+	{
+		name:  "template_file",
+		usage: "模板文件路径，优先于 template",
+		set:   func(c *PluginConfig, v string) error { c.TemplateFile = v; return nil },
+		get:   func(c *PluginConfig) string { return c.TemplateFile },
+	},
+	{
+		name:  "data_dir",
+		usage: "模板函数 datafile 读取 YAML 或 JSON 数据文件的目录，如端口分配表；datafile 只能读取该目录下的文件",
+		set:   func(c *PluginConfig, v string) error { c.DataDir = v; return nil },
+		get:   func(c *PluginConfig) string { return c.DataDir },
+	},
+	{
+		name:  "set",
+		usage: "注入模板的常量，格式为 key=value，可重复指定，模板中以 {{.Values.key}} 引用，如 set=cluster=prod-sh；配置文件中可写作 values 映射",
+		set: func(c *PluginConfig, v string) error {
+			key, value, err := parseValue(v)
+			if err != nil {
+				return err
+			}
+			if c.Values == nil {
+				c.Values = map[string]string{}
+			}
+			c.Values[key] = value
+			return nil
+		},
+		get: func(c *PluginConfig) string {
+			var items []string
+			for key, value := range c.Values {
+				items = append(items, key+"="+value)
+			}
+			sort.Strings(items)
+			return strings.Join(items, ";")
+		},
+		repeated: true,
+	},
+	{
+		name:  "grpc_stubs",
+		usage: "服务是否生成了 protoc-gen-go-grpc 代码: assume 假定都已生成；detect 在当前目录所在的 Go 模块中查找 Register*Server 函数，只能检测到此前已生成的代码，无法定位的包视为已生成；none 都未生成。默认 assume，结果见模板数据 HasGRPCStubs",
+		set: func(c *PluginConfig, v string) error {
+			switch v {
+			case grpcStubsAssume, grpcStubsDetect, grpcStubsNone:
+				c.GRPCStubs = v
+				return nil
+			}
+			return fmt.Errorf("grpc_stubs 应为 assume、detect 或 none: %s", v)
+		},
+		get: func(c *PluginConfig) string { return c.GRPCStubs },
+	},
+	{
+		name:  "grpc_stubs_missing",
+		usage: "缺少 gRPC 代码的服务的处理方式: skip 跳过并警告；render 照常渲染，由模板按 HasGRPCStubs 分支。默认 skip",
+		set: func(c *PluginConfig, v string) error {
+			switch v {
+			case grpcStubsMissingSkip, grpcStubsMissingRender:
+				c.GRPCStubsMissing = v
+				return nil
+			}
+			return fmt.Errorf("grpc_stubs_missing 应为 skip 或 render: %s", v)
+		},
+		get: func(c *PluginConfig) string { return c.GRPCStubsMissing },
+	},
+	{
+		name:  "template_inline",
+		usage: "base64 编码的模板内容，优先级最高",
+		set:   func(c *PluginConfig, v string) error { c.TemplateInline = v; return nil },
+		get:   func(c *PluginConfig) string { return c.TemplateInline },
+	},
+	{
+		name:  "scope",
+		usage: "主模板的渲染范围: service 每个服务输出一个文件；file 以 FileInfo 为数据每个 proto 文件输出一个文件，如 order_v1_order.go；all 以含 Files 的 AggregateInfo 为数据只输出一个文件，文件名取自模板文件名，如 bootstrap.tmpl 输出 bootstrap.go",
+		set: func(c *PluginConfig, v string) error {
+			switch v {
+			case scopeService, scopeFile, scopeAll:
+				c.Scope = v
+				return nil
+			}
+			return fmt.Errorf("scope 应为 service、file 或 all: %s", v)
+		},
+		get: func(c *PluginConfig) string { return c.Scope },
+	},
+	{
+		name:  "output_dir",
+		usage: "输出目录",
+		set:   func(c *PluginConfig, v string) error { c.OutputDir = v; return nil },
+		get:   func(c *PluginConfig) string { return c.OutputDir },
+	},
+	{
+		name:  "output_ext",
+		usage: "服务模板输出文件的扩展名，如 .ts；非 .go 时不执行 gofmt，可通过 formatter 指定格式化命令",
+		set: func(c *PluginConfig, v string) error {
+			if !strings.HasPrefix(v, ".") || len(v) < 2 {
+				return fmt.Errorf("output_ext 应以 . 开头，如 .ts: %s", v)
+			}
+			c.OutputExt = v
+			return nil
+		},
+		get: func(c *PluginConfig) string { return c.OutputExt },
+	},
+	{
+		name:  "package_name",
+		usage: "生成的包名",
+		set:   func(c *PluginConfig, v string) error { c.PackageName = v; return nil },
+		get:   func(c *PluginConfig) string { return c.PackageName },
+	},
+	{
+		name:  "package_mode",
+		usage: "服务文件的 Go 包划分方式: single 输出到 output_dir 下的 package_name 包；per_proto 输出到 proto 文件的 Go 包目录并使用其包名，不支持汇总模板",
+		set: func(c *PluginConfig, v string) error {
+			switch v {
+			case packageModeSingle, packageModePerProto:
+				c.PackageMode = v
+				return nil
+			}
+			return fmt.Errorf("package_mode 应为 single 或 per_proto: %s", v)
+		},
+		get: func(c *PluginConfig) string { return c.PackageMode },
+	},
+	{
+		name:  "go_mod",
+		usage: "模块路径，非空时在 output_dir 生成 go.mod 与 doc.go，供输出目录位于当前模块之外、作为独立模块被多个服务引用时使用",
+		set:   func(c *PluginConfig, v string) error { c.GoMod = v; return nil },
+		get:   func(c *PluginConfig) string { return c.GoMod },
+	},
+	{
+		name:  "go_mod_require",
+		usage: "写入 go.mod 的依赖，格式为 模块路径@版本号，可重复指定；generate 子命令更新已有的 go.mod 时保留其中的其他依赖与 replace 等指令",
+		set: func(c *PluginConfig, v string) error {
+			r, err := parseGoModRequire(v)
+			if err != nil {
+				return err
+			}
+			c.GoModRequires = append(c.GoModRequires, r)
+			return nil
+		},
+		get: func(c *PluginConfig) string {
+			requires := make([]string, len(c.GoModRequires))
+			for i, r := range c.GoModRequires {
+				requires[i] = r.String()
+			}
+			return strings.Join(requires, ";")
+		},
+		repeated: true,
+	},
+	{
+		name:     "funcs_plugin",
+		usage:    "提供自定义模板函数的 Go 插件路径（go build -buildmode=plugin），导出 TemplateFuncs，可重复指定；仅 CGO_ENABLED=1 构建的 linux、darwin、freebsd 版本支持，Docker 镜像等静态构建会拒绝该参数",
+		set:      func(c *PluginConfig, v string) error { c.FuncsPlugins = append(c.FuncsPlugins, v); return nil },
+		get:      func(c *PluginConfig) string { return strings.Join(c.FuncsPlugins, ";") },
+		repeated: true,
+	},
+	{
+		name:  "service_template",
+		usage: "额外的服务级内置模板，多个以 + 分隔，每个服务各输出一个文件，如 deploy.yaml 输出 user.deploy.yaml",
+		set:   func(c *PluginConfig, v string) error { c.ServiceTemplates = strings.Split(v, "+"); return nil },
+		get:   func(c *PluginConfig) string { return strings.Join(c.ServiceTemplates, "+") },
+	},
+	{
+		name:  "aggregate_template",
+		usage: "汇总模板的内置模板名称，多个以 + 分隔",
+		set:   func(c *PluginConfig, v string) error { c.AggregateTemplates = strings.Split(v, "+"); return nil },
+		get:   func(c *PluginConfig) string { return strings.Join(c.AggregateTemplates, "+") },
+	},
+	{
+		name:  "aggregate_template_file",
+		usage: "汇总模板文件路径",
+		set:   func(c *PluginConfig, v string) error { c.AggregateTemplateFile = v; return nil },
+		get:   func(c *PluginConfig) string { return c.AggregateTemplateFile },
+	},
+	{
+		name:  "aggregate_file",
+		usage: "汇总文件名，仅在只有一个汇总模板时可用",
+		set:   func(c *PluginConfig, v string) error { c.AggregateFile = v; return nil },
+		get:   func(c *PluginConfig) string { return c.AggregateFile },
+	},
+	{
+		name:  "heartbeat_interval",
+		usage: "服务中心心跳间隔，如 5s",
+		set: func(c *PluginConfig, v string) error {
+			interval, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("heartbeat_interval 不是合法的时间间隔: %s", v)
+			}
+			c.HeartbeatInterval = interval
+			return nil
+		},
+		get: func(c *PluginConfig) string { return durationString(c.HeartbeatInterval) },
+	},
+	{
+		name:  "registration",
+		usage: "服务的注册方式: lazy 只生成显式调用的 Register 函数，生成的代码中出现 init 函数时报错；eager 额外在每个服务文件的 init 中登记注册函数，通过 StartServices 统一注册",
+		set: func(c *PluginConfig, v string) error {
+			switch v {
+			case registrationLazy, registrationEager:
+				c.Registration = v
+				return nil
+			}
+			return fmt.Errorf("registration 应为 lazy 或 eager: %s", v)
+		},
+		get: func(c *PluginConfig) string { return c.Registration },
+	},
+	{
+		name:  "forbid_init",
+		usage: "为 true 时检查所有模板生成的 Go 代码（含插入点代码），出现 init 函数即生成失败，用于强制显式注册",
+		set: func(c *PluginConfig, v string) error {
+			forbid, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("forbid_init 不是合法的布尔值: %s", v)
+			}
+			c.ForbidInit = forbid
+			return nil
+		},
+		get: func(c *PluginConfig) string { return strconv.FormatBool(c.ForbidInit) },
+	},
+	{
+		name:  "tenants",
+		usage: "租户列表，多个以 + 分隔，如 acme+globex；非空时 registry_backend 等模板额外生成按租户注册的函数，注册名称为 租户名-服务注册名称",
+		set:   func(c *PluginConfig, v string) error { c.Tenants = strings.Split(v, "+"); return nil },
+		get:   func(c *PluginConfig) string { return strings.Join(c.Tenants, "+") },
+	},
+	{
+		name:  "tenant_metadata",
+		usage: "租户附加的服务元数据，格式为 租户名:key=value，可重复指定",
+		set: func(c *PluginConfig, v string) error {
+			tenant, key, value, err := parseTenantMetadata(v)
+			if err != nil {
+				return err
+			}
+			if c.TenantMetadata == nil {
+				c.TenantMetadata = map[string]map[string]string{}
+			}
+			if c.TenantMetadata[tenant] == nil {
+				c.TenantMetadata[tenant] = map[string]string{}
+			}
+			c.TenantMetadata[tenant][key] = value
+			return nil
+		},
+		get: func(c *PluginConfig) string {
+			var items []string
+			for tenant, metadata := range c.TenantMetadata {
+				for key, value := range metadata {
+					items = append(items, tenant+":"+key+"="+value)
+				}
+			}
+			sort.Strings(items)
+			return strings.Join(items, ";")
+		},
+		repeated: true,
+	},
+	{
+		name:  "region",
+		usage: "服务实例所在区域，注册时写入元数据 region；env:NAME 表示运行时读取环境变量 NAME",
+		set: func(c *PluginConfig, v string) error {
+			var err error
+			c.Locality.Region, c.Locality.RegionEnv, err = parseLocality("region", v)
+			return err
+		},
+		get: func(c *PluginConfig) string { return localityString(c.Locality.Region, c.Locality.RegionEnv) },
+	},
+	{
+		name:  "zone",
+		usage: "服务实例所在可用区，注册时写入元数据 zone；env:NAME 表示运行时读取环境变量 NAME",
+		set: func(c *PluginConfig, v string) error {
+			var err error
+			c.Locality.Zone, c.Locality.ZoneEnv, err = parseLocality("zone", v)
+			return err
+		},
+		get: func(c *PluginConfig) string { return localityString(c.Locality.Zone, c.Locality.ZoneEnv) },
+	},
+	{
+		name:  "conn_pool_size",
+		usage: "conn_manager 汇总模板中每个服务的连接数，服务上的 (registry.conn_pool_size) 优先",
+		set: func(c *PluginConfig, v string) error {
+			size, err := strconv.Atoi(v)
+			if err != nil || size < 1 {
+				return fmt.Errorf("conn_pool_size 应为正整数: %s", v)
+			}
+			c.ConnPoolSize = size
+			return nil
+		},
+		get: func(c *PluginConfig) string { return strconv.Itoa(c.ConnPoolSize) },
+	},
+	{
+		name:  "zk_root",
+		usage: "zookeeper_registry 汇总模板中实例节点的根路径，实例注册为临时节点 <zk_root>/<注册名称>/<host:port>",
+		set: func(c *PluginConfig, v string) error {
+			root, err := parseZookeeperRoot(v)
+			c.ZookeeperRoot = root
+			return err
+		},
+		get: func(c *PluginConfig) string { return c.ZookeeperRoot },
+	},
+	{
+		name:  "zk_acl",
+		usage: "zookeeper_registry 汇总模板创建节点使用的 ACL，格式为 scheme:id:perms，如 world:anyone:r、digest:user:BASE64HASH:crwda，可重复指定；未指定时为 world:anyone:crwda",
+		set: func(c *PluginConfig, v string) error {
+			acl, err := parseZookeeperACL(v)
+			if err != nil {
+				return err
+			}
+			c.ZookeeperACLs = append(c.ZookeeperACLs, acl)
+			return nil
+		},
+		get:      func(c *PluginConfig) string { return zookeeperACLString(c.ZookeeperACLs) },
+		repeated: true,
+	},
+	{
+		name:  "dns_domain",
+		usage: "dns_resolver 汇总模板与 istio.yaml 服务模板中的集群域名，服务的域名为 <注册名称>.<命名空间>.svc.<dns_domain>",
+		set: func(c *PluginConfig, v string) error {
+			domain, err := parseDNSDomain(v)
+			c.DNSDomain = domain
+			return err
+		},
+		get: func(c *PluginConfig) string { return c.DNSDomain },
+	},
+	{
+		name:  "spiffe_trust_domain",
+		usage: "服务 SPIFFE ID 的信任域，用于 (registry.spiffe_id) 只写路径或未声明的服务，写入 spiffe.json 服务模板与 spiffe_tls 汇总模板",
+		set: func(c *PluginConfig, v string) error {
+			td, err := parseSPIFFETrustDomain(v)
+			c.SPIFFETrustDomain = td
+			return err
+		},
+		get: func(c *PluginConfig) string { return c.SPIFFETrustDomain },
+	},
+	{
+		name:  "gateway_upstream_port",
+		usage: "apisix.yaml 与 kong.yaml 汇总模板中上游服务的 HTTP 端口，即 http_routes 转码服务监听的端口，上游地址为 <注册名称>.<命名空间>.svc.<dns_domain>:<端口>",
+		set: func(c *PluginConfig, v string) error {
+			port, err := strconv.Atoi(v)
+			if err != nil || port < 1 || port > 65535 {
+				return fmt.Errorf("gateway_upstream_port 应为 1 到 65535 之间的端口号: %s", v)
+			}
+			c.GatewayUpstreamPort = port
+			return nil
+		},
+		get: func(c *PluginConfig) string { return strconv.Itoa(c.GatewayUpstreamPort) },
+	},
+	{
+		name:  "schema_module",
+		usage: "schema_manifest.yaml 汇总模板中服务默认推送到的 schema registry 模块，如 buf.build/acme/payments，服务上的 (registry.schema_module) 优先",
+		set: func(c *PluginConfig, v string) error {
+			c.SchemaModule = strings.TrimSpace(v)
+			return nil
+		},
+		get: func(c *PluginConfig) string { return c.SchemaModule },
+	},
+	{
+		name:  "ops",
+		usage: "为 true 时额外生成 ops.go，通过 RegisterOps 一次注册 pprof、/healthz、/readyz 与 /buildinfo",
+		set: func(c *PluginConfig, v string) error {
+			ops, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("ops 不是合法的布尔值: %s", v)
+			}
+			c.Ops = ops
+			return nil
+		},
+		get: func(c *PluginConfig) string { return strconv.FormatBool(c.Ops) },
+	},
+	{
+		name:  "debug_services",
+		usage: "为 true 时额外生成 debugServices.go，通过 RegisterDebugServices 在 /debug/services 以 JSON 或 HTML 输出服务、方法、版本与元数据",
+		set: func(c *PluginConfig, v string) error {
+			debugServices, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("debug_services 不是合法的布尔值: %s", v)
+			}
+			c.DebugServices = debugServices
+			return nil
+		},
+		get: func(c *PluginConfig) string { return strconv.FormatBool(c.DebugServices) },
+	},
+	{
+		name:  "provenance",
+		usage: "为 true 时额外生成 provenance.go，记录每个注册服务对应的 proto 文件、服务全限定名与源码版本，可按注册名称或 gRPC 方法路径查询",
+		set: func(c *PluginConfig, v string) error {
+			provenance, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("provenance 不是合法的布尔值: %s", v)
+			}
+			c.Provenance = provenance
+			return nil
+		},
+		get: func(c *PluginConfig) string { return strconv.FormatBool(c.Provenance) },
+	},
+	{
+		name:  "source_version",
+		usage: "proto 源码的版本，如 source_version=$(git describe --tags --always --dirty)，写入 provenance.go 与汇总模板数据",
+		set:   func(c *PluginConfig, v string) error { c.SourceVersion = v; return nil },
+		get:   func(c *PluginConfig) string { return c.SourceVersion },
+	},
+	{
+		name:  "provenance_timestamp",
+		usage: "为 true 时在 provenance.go 中记录生成时间（UTC），此时每次生成的内容都不同",
+		set: func(c *PluginConfig, v string) error {
+			timestamp, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("provenance_timestamp 不是合法的布尔值: %s", v)
+			}
+			c.ProvenanceTimestamp = timestamp
+			return nil
+		},
+		get: func(c *PluginConfig) string { return strconv.FormatBool(c.ProvenanceTimestamp) },
+	},
+	{
+		name:  "reproducible",
+		usage: "为 true 时所有输出（含 report、manifest 与 dump_data）不包含时间戳与绝对路径，绝对路径转换为相对于当前目录的路径，不能与 provenance_timestamp=true 同时使用",
+		set: func(c *PluginConfig, v string) error {
+			reproducible, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("reproducible 不是合法的布尔值: %s", v)
+			}
+			c.Reproducible = reproducible
+			return nil
+		},
+		get: func(c *PluginConfig) string { return strconv.FormatBool(c.Reproducible) },
+	},
+	{
+		name:  "package_index",
+		usage: "为 true 时为包含多个服务的 proto 包生成 <包名>_index.go，列出包内服务并提供 Register<包名>Services",
+		set: func(c *PluginConfig, v string) error {
+			index, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("package_index 不是合法的布尔值: %s", v)
+			}
+			c.PackageIndex = index
+			return nil
+		},
+		get: func(c *PluginConfig) string { return strconv.FormatBool(c.PackageIndex) },
+	},
+	{
+		name:  "docs_dir",
+		usage: "服务文档输出目录（相对于输出根目录），为每个服务生成 <注册名>/README.md 与 OWNERS",
+		set:   func(c *PluginConfig, v string) error { c.DocsDir = v; return nil },
+		get:   func(c *PluginConfig) string { return c.DocsDir },
+	},
+	{
+		name:  "fail_fast",
+		usage: "为 false 时收集所有服务的错误后一并报告，其余服务照常生成（protoc 遇到错误时不写出文件，generate 子命令会写出）",
+		set: func(c *PluginConfig, v string) error {
+			failFast, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("fail_fast 不是合法的布尔值: %s", v)
+			}
+			c.FailFast = failFast
+			return nil
+		},
+		get: func(c *PluginConfig) string { return strconv.FormatBool(c.FailFast) },
+	},
+	{
+		name:  "data_version",
+		usage: "模板数据模型的版本: 1 为原有的扁平结构，2 增加 .Proto 等结构化字段，并对模板中使用的废弃字段给出警告",
+		set: func(c *PluginConfig, v string) error {
+			version, err := strconv.Atoi(v)
+			if err != nil || version < DataVersion1 || version > DataVersion2 {
+				return fmt.Errorf("data_version 应为 1 或 2: %s", v)
+			}
+			c.DataVersion = version
+			return nil
+		},
+		get: func(c *PluginConfig) string { return strconv.Itoa(c.DataVersion) },
+	},
+	{
+		name:  "warnings",
+		usage: "警告的处理方式: warn 输出到 stderr（带 proto 文件位置），error 时存在警告即生成失败，off 不输出",
+		set: func(c *PluginConfig, v string) error {
+			switch v {
+			case warningsWarn, warningsError, warningsOff:
+				c.Warnings = v
+				return nil
+			}
+			return fmt.Errorf("warnings 应为 warn、error 或 off: %s", v)
+		},
+		get: func(c *PluginConfig) string { return c.Warnings },
+	},
+	{
+		name:  "dry_run",
+		usage: "为 true 时只向 stderr 输出将要生成的文件，不写出任何文件",
+		set: func(c *PluginConfig, v string) error {
+			dryRun, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("dry_run 不是合法的布尔值: %s", v)
+			}
+			c.DryRun = dryRun
+			return nil
+		},
+		get: func(c *PluginConfig) string { return strconv.FormatBool(c.DryRun) },
+	},
+	{
+		name:  "report",
+		usage: "JSON 生成报告的输出路径（相对于输出根目录），列出每个文件的来源、模板与 SHA-256",
+		set:   func(c *PluginConfig, v string) error { c.ReportFile = v; return nil },
+		get:   func(c *PluginConfig) string { return c.ReportFile },
+	},
+	{
+		name:  "manifest",
+		usage: "JSON 输入输出清单的输出路径（相对于输出根目录），内容不含摘要，便于构建系统预先声明输出",
+		set:   func(c *PluginConfig, v string) error { c.ManifestFile = v; return nil },
+		get:   func(c *PluginConfig) string { return c.ManifestFile },
+	},
+	{
+		name:  "attestation",
+		usage: "JSON 来源证明的输出路径（相对于输出根目录），记录插件版本、参数摘要、所用模板摘要、输入描述符摘要与各输出文件摘要，供签名与复现校验",
+		set:   func(c *PluginConfig, v string) error { c.AttestationFile = v; return nil },
+		get:   func(c *PluginConfig) string { return c.AttestationFile },
+	},
+	{
+		name:  "compact",
+		usage: "为 package 时同一目录（即同一 Go 包）下的 Go 输出在所有渲染阶段结束后合并为 registry_compact.go，导入名冲突或标识符重复时报错；报告与清单记录合并后的文件",
+		set: func(c *PluginConfig, v string) error {
+			if v != "" && v != compactPackage {
+				return fmt.Errorf("compact 应为 package: %s", v)
+			}
+			c.Compact = v
+			return nil
+		},
+		get: func(c *PluginConfig) string { return c.Compact },
+	},
+	{
+		name:  "gzip_artifacts",
+		usage: "为 true 时以 gzip 压缩非 Go 输出（文档、JSON、YAML 等），输出路径追加 .gz；report、manifest 等清单文件不压缩",
+		set: func(c *PluginConfig, v string) error {
+			gzipArtifacts, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("gzip_artifacts 不是合法的布尔值: %s", v)
+			}
+			c.GzipArtifacts = gzipArtifacts
+			return nil
+		},
+		get: func(c *PluginConfig) string { return strconv.FormatBool(c.GzipArtifacts) },
+	},
+	{
+		name:  "max_response_size",
+		usage: "插件响应的大小上限，可带 KiB、MiB、GiB 单位，如 512MiB；超过时报错并提示缩减方式，超过 80% 时警告，默认为 protoc 的上限（2GiB 减 1 字节）",
+		set: func(c *PluginConfig, v string) error {
+			size, err := parseSize(v)
+			if err != nil {
+				return fmt.Errorf("max_response_size %v", err)
+			}
+			c.MaxResponseSize = size
+			return nil
+		},
+		get: func(c *PluginConfig) string {
+			if c.MaxResponseSize == 0 {
+				return ""
+			}
+			return formatSize(c.MaxResponseSize)
+		},
+	},
+	{
+		name:  "dump_data",
+		usage: "模板数据的 JSON 输出路径（相对于输出根目录），格式由 schema -format dump_data 给出的 JSON Schema 描述",
+		set:   func(c *PluginConfig, v string) error { c.DumpDataFile = v; return nil },
+		get:   func(c *PluginConfig) string { return c.DumpDataFile },
+	},
+	{
+		name:     "post_hook",
+		usage:    "生成后执行的命令，可重复指定；命令通过 shell 执行，且 protoc 调用时由 protoc 写文件，因此仅 generate 子命令执行，protoc/buf 插件模式下报错",
+		set:      func(c *PluginConfig, v string) error { c.PostHooks = append(c.PostHooks, v); return nil },
+		get:      func(c *PluginConfig) string { return strings.Join(c.PostHooks, ";") },
+		repeated: true,
+	},
+	{
+		name:  "formatter",
+		usage: "按扩展名指定外部格式化命令，如 .yaml:prettier --parser yaml，可重复指定；命令通过 shell 执行，仅 generate、--check、--list_outputs 子命令支持，protoc/buf 插件模式下报错",
+		set: func(c *PluginConfig, v string) error {
+			ext, command, err := parseFormatter(v)
+			if err != nil {
+				return err
+			}
+			if c.Formatters == nil {
+				c.Formatters = map[string]string{}
+			}
+			c.Formatters[ext] = command
+			return nil
+		},
+		get: func(c *PluginConfig) string {
+			exts := make([]string, 0, len(c.Formatters))
+			for ext := range c.Formatters {
+				exts = append(exts, ext+":"+c.Formatters[ext])
+			}
+			sort.Strings(exts)
+			return strings.Join(exts, ";")
+		},
+		repeated: true,
+	},
+	{
+		name:  "enforce_naming",
+		usage: "命名规则，格式为 service|method|package|file:正则表达式，违反时生成失败并给出 proto 文件行号，可重复指定；含逗号的正则请写在配置文件中",
+		set: func(c *PluginConfig, v string) error {
+			rule, err := parseNamingRule(v)
+			if err != nil {
+				return err
+			}
+			c.NamingRules = append(c.NamingRules, rule)
+			return nil
+		},
+		get: func(c *PluginConfig) string {
+			rules := make([]string, len(c.NamingRules))
+			for i, rule := range c.NamingRules {
+				rules[i] = rule.String()
+			}
+			return strings.Join(rules, ";")
+		},
+		repeated: true,
+	},
+	{
+		name:  "log",
+		usage: "输出到 stderr 的日志级别: debug、info、warn 或 error",
+		set: func(c *PluginConfig, v string) error {
+			if err := c.LogLevel.UnmarshalText([]byte(v)); err != nil {
+				return fmt.Errorf("log 不是合法的日志级别: %s", v)
+			}
+			return nil
+		},
+		get: func(c *PluginConfig) string { return strings.ToLower(c.LogLevel.String()) },
+	},
+	{
+		name:  "insertion_suffix",
+		usage: "插入点目标文件后缀，目标文件须含 @@protoc_insertion_point 标记，模板定义 insert: 代码块时必须指定",
+		set:   func(c *PluginConfig, v string) error { c.InsertionSuffix = v; return nil },
+		get:   func(c *PluginConfig) string { return c.InsertionSuffix },
+	},
+	{
+		name:  "module",
+		usage: "与 protoc-gen-go 相同的 module 参数，用于计算插入点目标文件名",
+		set:   func(c *PluginConfig, v string) error { c.Module = v; return nil },
+		get:   func(c *PluginConfig) string { return c.Module },
+	},
+}
+
+// OptionDoc 插件参数说明
+type OptionDoc struct {
+	Name    string // 参数名
+	Default string // 默认值，为空表示无默认值
+	Usage   string // 参数说明
+}
+
+// OptionDocs 返回所有支持的插件参数及其默认值
+func OptionDocs() []OptionDoc {
+	defaults := DefaultPluginConfig()
+	docs := make([]OptionDoc, len(pluginOptions))
+	for i, opt := range pluginOptions {
+		docs[i] = OptionDoc{Name: opt.name, Default: opt.get(defaults), Usage: opt.usage}
+	}
+	return docs
+}
+
+// ParsePluginOptions 解析插件参数，格式: key1=value1,key2=value2
+//
+// 指定了 config 时先加载配置文件，再用其余插件参数覆盖。
+func ParsePluginOptions(param string) (*PluginConfig, error) {
+	config := DefaultPluginConfig()
+
+	type keyValue struct{ key, value string }
+	var params []keyValue
+	pairs := strings.Split(param, ",")
+	for _, pair := range pairs {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("插件参数格式错误，应为 key=value: %s", pair)
+		}
+		key := strings.TrimSpace(kv[0])
+		value := strings.TrimSpace(kv[1])
+
+		if key == "config" {
+			config.ConfigFile = value
+			continue
+		}
+		params = append(params, keyValue{key, value})
+	}
+
+	if config.ConfigFile != "" {
+		if err := config.loadConfigFile(config.ConfigFile); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range params {
+		if err := config.set(p.key, p.value); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.buildPasses(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// option 按名称查找插件参数定义
+func option(name string) (pluginOption, bool) {
+	for _, opt := range pluginOptions {
+		if opt.name == name {
+			return opt, true
+		}
+	}
+	return pluginOption{}, false
+}
+
+// set 设置单个插件参数，protogen 自行处理的参数直接忽略
+func (c *PluginConfig) set(key, value string) error {
+	if opt, ok := option(key); ok {
+		return opt.set(c, value)
+	}
+	if isProtogenParam(key) {
+		return nil
+	}
+	return fmt.Errorf("未知的插件参数: %s", key)
+}
+
+// HasAggregate 是否需要生成汇总文件
+func (c *PluginConfig) HasAggregate() bool {
+	return c.aggregateCount() > 0
+}
+
+// aggregateCount 汇总模板数量
+func (c *PluginConfig) aggregateCount() int {
+	n := len(c.AggregateTemplates)
+	if c.AggregateTemplateFile != "" {
+		n++
+	}
+	return n
+}
+
+// Validate 验证必需参数
+func (c *PluginConfig) Validate() error {
+	if c.TemplateInline == "" && c.TemplateFile == "" && c.Template == "" {
+		return fmt.Errorf("必须指定 template、template_file 或 template_inline 参数")
+	}
+	if c.AggregateFile != "" && c.aggregateCount() != 1 {
+		return fmt.Errorf("aggregate_file 只能在指定了一个汇总模板时使用")
+	}
+	if len(c.FuncsPlugins) > 0 && !funcsPluginSupported {
+		return fmt.Errorf("当前构建不支持 Go 插件（需以 CGO_ENABLED=1 在 linux、darwin 或 freebsd 上构建），不能使用 funcs_plugin；可改用库模式调用 RegisterTemplateFunc")
+	}
+	// 内置模板均以 ServiceInfo 为数据
+	if c.Scope != scopeService && c.TemplateFile == "" && c.TemplateInline == "" {
+		return fmt.Errorf("scope=%s 需要通过 template_file 或 template_inline 指定模板", c.Scope)
+	}
+	if c.PackageMode == packageModePerProto && c.GoMod != "" {
+		return fmt.Errorf("package_mode=per_proto 时服务输出到 proto 的 Go 包目录，不能使用 go_mod")
+	}
+	if len(c.GoModRequires) > 0 && c.GoMod == "" {
+		return fmt.Errorf("go_mod_require 需要同时指定 go_mod")
+	}
+	// 汇总模板以同包的方式引用各服务的注册函数，服务分散在多个包中时无法编译
+	if c.PackageMode == packageModePerProto && (c.HasAggregate() || c.PackageIndex) {
+		return fmt.Errorf("package_mode=per_proto 时服务分布在多个 Go 包中，不能使用汇总模板与 package_index")
+	}
+	if c.Reproducible && c.ProvenanceTimestamp {
+		return fmt.Errorf("reproducible=true 时输出不能包含生成时间，不能同时指定 provenance_timestamp=true")
+	}
+	if slices.Contains(c.AggregateTemplates, multiRegistryTemplate) && len(registryBackends(c.AggregateTemplates)) == 0 {
+		return fmt.Errorf("multi_registry 汇总模板须与 consul_registry 等服务中心汇总模板一起使用")
+	}
+	// 未启用 multi_registry 时各服务中心汇总模板都声明 registerInstance 与 watchInstances，同时启用会重复声明
+	if backends := enabledRegistryBackends(c.AggregateTemplates); len(backends) > 1 && !slices.Contains(c.AggregateTemplates, multiRegistryTemplate) {
+		return fmt.Errorf("同时启用了多个服务中心汇总模板（%s），须在 aggregate_template 中加入 multi_registry，如 aggregate_template=%s_registry+multi_registry",
+			strings.Join(backends, "、"), strings.Join(backends, "_registry+"))
+	}
+	if slices.Contains(c.AggregateTemplates, "register_all") && slices.Contains(c.AggregateTemplates, "registry_lifecycle") {
+		return fmt.Errorf("register_all 与 registry_lifecycle 汇总模板都声明了 Implementations 与 RegisterAll，不能同时使用；配合 registry_backend 时只需 registry_lifecycle")
+	}
+	if c.ForbidInit && c.Registration == registrationEager {
+		return fmt.Errorf("registration=eager 在 init 中登记服务，不能同时指定 forbid_init=true")
+	}
+	return c.validateTenants()
+}
+
+// isProtogenParam 判断是否为 protogen 自行处理的参数
+func isProtogenParam(key string) bool {
+	switch key {
+	case "paths", "annotate_code", "default_api_level":
+		return true
+	}
+	return strings.HasPrefix(key, "M") || strings.HasPrefix(key, "apilevelM")
+}
+
+// durationString 格式化时间间隔，零值显示为空
+func durationString(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
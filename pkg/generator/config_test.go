@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePluginOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		param  string
+		check  func(*PluginConfig) bool
+		errMsg string // 非空时期望解析失败，错误信息包含该内容
+	}{
+		{name: "默认配置", param: "", check: func(c *PluginConfig) bool {
+			return c.Template == DefaultTemplate && c.FailFast && c.InsertionSuffix == ""
+		}},
+		{name: "忽略空项与空白", param: " template = registry_backend ,, ", check: func(c *PluginConfig) bool {
+			return c.Template == "registry_backend"
+		}},
+		{name: "可重复参数", param: "formatter=.yaml:prettier --parser yaml,formatter=.json:jq .", check: func(c *PluginConfig) bool {
+			return c.Formatters[".yaml"] == "prettier --parser yaml" && c.Formatters[".json"] == "jq ."
+		}},
+		{name: "汇总模板以 + 分隔", param: "aggregate_template=consul_registry+multi_registry", check: func(c *PluginConfig) bool {
+			return len(c.AggregateTemplates) == 2 && c.HasAggregate()
+		}},
+		{name: "protogen 参数", param: "paths=source_relative,module=example.com/x", check: func(c *PluginConfig) bool {
+			return c.Module == "example.com/x"
+		}},
+		{name: "缺少等号", param: "template", errMsg: "应为 key=value"},
+		{name: "未知参数", param: "no_such_option=1", errMsg: "未知的插件参数: no_such_option"},
+		{name: "非法取值", param: "registration=sometimes", errMsg: "registration"},
+		{name: "formatter 格式错误", param: "formatter=yaml", errMsg: "formatter 格式错误"},
+		{name: "未指定模板", param: "template=", errMsg: "必须指定 template"},
+
+		// 参数之间的冲突
+		{name: "aggregate_file 需要一个汇总模板", param: "aggregate_file=all.go", errMsg: "aggregate_file 只能在指定了一个汇总模板时使用"},
+		{name: "aggregate_file 与多个汇总模板", param: "aggregate_file=all.go,aggregate_template=consul_registry+etcd_registry", errMsg: "aggregate_file"},
+		{name: "scope 需要自定义模板", param: "scope=file", errMsg: "scope=file 需要通过 template_file 或 template_inline 指定模板"},
+		{name: "per_proto 与 go_mod", param: "package_mode=per_proto,go_mod=example.com/reg", errMsg: "不能使用 go_mod"},
+		{name: "go_mod_require 需要 go_mod", param: "go_mod_require=google.golang.org/grpc@v1.70.0", errMsg: "go_mod_require 需要同时指定 go_mod"},
+		{name: "per_proto 与汇总模板", param: "package_mode=per_proto,aggregate_template=consul_registry", errMsg: "不能使用汇总模板"},
+		{name: "per_proto 与 package_index", param: "package_mode=per_proto,package_index=true", errMsg: "package_index"},
+		{name: "reproducible 与 provenance_timestamp", param: "reproducible=true,provenance_timestamp=true", errMsg: "provenance_timestamp"},
+		{name: "multi_registry 需要服务中心模板", param: "aggregate_template=multi_registry", errMsg: "multi_registry 汇总模板须与"},
+		{name: "多个服务中心需要 multi_registry", param: "aggregate_template=etcd_registry+nacos_registry", errMsg: "aggregate_template=etcd_registry+nacos_registry+multi_registry"},
+		{name: "多个服务中心与 multi_registry", param: "aggregate_template=zookeeper_registry+consul_registry+multi_registry", check: func(c *PluginConfig) bool {
+			return len(registryBackends(c.AggregateTemplates)) == 2
+		}},
+		{name: "register_all 与 registry_lifecycle", param: "aggregate_template=register_all+registry_lifecycle+consul_registry", errMsg: "不能同时使用"},
+		{name: "forbid_init 与 eager", param: "forbid_init=true,registration=eager", errMsg: "forbid_init=true"},
+		{name: "租户名不合法", param: "tenants=Acme", errMsg: "租户名"},
+		{name: "租户重复", param: "tenants=acme+acme", errMsg: "租户重复"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParsePluginOptions(tt.param)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("ParsePluginOptions(%q) 的错误为 %v，期望包含 %q", tt.param, err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePluginOptions(%q) 失败: %v", tt.param, err)
+			}
+			if !tt.check(c) {
+				t.Errorf("ParsePluginOptions(%q) 得到的配置不符合预期: %+v", tt.param, c)
+			}
+		})
+	}
+}
+
+func TestParsePluginOptionsFuncsPlugin(t *testing.T) {
+	_, err := ParsePluginOptions("funcs_plugin=/tmp/funcs.so")
+	if funcsPluginSupported {
+		if err != nil {
+			t.Errorf("支持 Go 插件时解析 funcs_plugin 不应失败: %v", err)
+		}
+		return
+	}
+	if err == nil || !strings.Contains(err.Error(), "不能使用 funcs_plugin") {
+		t.Errorf("不支持 Go 插件时应拒绝 funcs_plugin，实际错误为 %v", err)
+	}
+}
+
+func TestNewRejectsCommandsInPluginMode(t *testing.T) {
+	for _, param := range []string{"formatter=.yaml:prettier", "post_hook=make fmt"} {
+		c, err := ParsePluginOptions(param)
+		if err != nil {
+			t.Fatalf("ParsePluginOptions(%q) 失败: %v", param, err)
+		}
+		if _, err := New(c); err == nil || !strings.Contains(err.Error(), "通过 shell 执行外部命令") {
+			t.Errorf("插件模式下 %s 应报错，实际错误为 %v", param, err)
+		}
+		c.AllowCommands = true
+		if _, err := New(c); err != nil {
+			t.Errorf("AllowCommands=true 时 %s 不应报错: %v", param, err)
+		}
+	}
+}
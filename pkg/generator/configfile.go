@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadConfigFile 从 YAML 配置文件读取插件参数，键名与插件参数相同，例如:
+//
+//	template_file: templates/service.tmpl
+//	output_dir: internal/registry
+//	aggregate_template: [registry_lifecycle, consul_registry]
+//
+// passes 声明额外的渲染阶段，见 PluginConfig.Passes；values 声明注入模板的常量，见 valuesKey。
+func (c *PluginConfig) loadConfigFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	var values map[string]any
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+	}
+	if err := c.applyConfigValues(values); err != nil {
+		return fmt.Errorf("配置文件 %s: %v", path, err)
+	}
+	return nil
+}
+
+// applyConfigValues 按配置文件中的键值设置插件参数
+func (c *PluginConfig) applyConfigValues(values map[string]any) error {
+	// 按键名排序，保证错误信息稳定
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if key == passesKey {
+			passes, err := passValues(values[key])
+			if err != nil {
+				return err
+			}
+			c.passValues = passes
+			continue
+		}
+		if key == valuesKey {
+			if err := c.applyValues(values[key]); err != nil {
+				return err
+			}
+			continue
+		}
+		// 可重复的参数在配置文件中写作列表，逐项设置
+		if list, ok := values[key].([]any); ok {
+			if opt, found := option(key); found && opt.repeated {
+				for _, item := range list {
+					if err := c.set(key, fmt.Sprint(item)); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+		}
+		if err := c.set(key, configValue(values[key])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configValue 将 YAML 值转换为插件参数值，列表以 + 连接
+func configValue(v any) string {
+	list, ok := v.([]any)
+	if !ok {
+		return fmt.Sprint(v)
+	}
+	items := make([]string, len(list))
+	for i, item := range list {
+		items[i] = fmt.Sprint(item)
+	}
+	return strings.Join(items, "+")
+}
@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// messageOptions 返回消息上声明的自定义选项，键为扩展的全限定名，如 cache.ttl，未声明时为 nil
+//
+// 插件只链接了 registry.* 的扩展类型，其他扩展在解析请求时作为未知字段保留，
+// 因此以消息所在文件（含间接导入）中声明的 MessageOptions 扩展重新解析选项。
+func messageOptions(message *protogen.Message) map[string]any {
+	opts, ok := message.Desc.Options().(*descriptorpb.MessageOptions)
+	if !ok || opts == nil {
+		return nil
+	}
+	raw, err := proto.Marshal(opts)
+	if err != nil {
+		return nil
+	}
+
+	types := new(protoregistry.Types)
+	visitFiles(message.Desc.ParentFile(), map[string]bool{}, func(fd protoreflect.FileDescriptor) {
+		rangeExtensions(fd.Extensions(), fd.Messages(), func(xd protoreflect.ExtensionDescriptor) {
+			if xd.ContainingMessage().FullName() == "google.protobuf.MessageOptions" {
+				_ = types.RegisterExtension(dynamicpb.NewExtensionType(xd))
+			}
+		})
+	})
+
+	resolved := new(descriptorpb.MessageOptions)
+	if err := (proto.UnmarshalOptions{Resolver: types}).Unmarshal(raw, resolved); err != nil {
+		return nil
+	}
+	var options map[string]any
+	resolved.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.IsExtension() {
+			if options == nil {
+				options = make(map[string]any)
+			}
+			options[string(fd.FullName())] = optionValue(fd, v)
+		}
+		return true
+	})
+	return options
+}
+
+// visitFiles 依次访问 fd 及其直接与间接导入的文件，每个文件只访问一次
+func visitFiles(fd protoreflect.FileDescriptor, seen map[string]bool, visit func(protoreflect.FileDescriptor)) {
+	if seen[fd.Path()] {
+		return
+	}
+	seen[fd.Path()] = true
+	visit(fd)
+	for i := 0; i < fd.Imports().Len(); i++ {
+		visitFiles(fd.Imports().Get(i).FileDescriptor, seen, visit)
+	}
+}
+
+// rangeExtensions 访问文件顶层与嵌套在消息中声明的扩展
+func rangeExtensions(exts protoreflect.ExtensionDescriptors, msgs protoreflect.MessageDescriptors, visit func(protoreflect.ExtensionDescriptor)) {
+	for i := 0; i < exts.Len(); i++ {
+		visit(exts.Get(i))
+	}
+	for i := 0; i < msgs.Len(); i++ {
+		rangeExtensions(msgs.Get(i).Extensions(), msgs.Get(i).Messages(), visit)
+	}
+}
+
+// optionValue 将选项值转为模板可直接使用的值：枚举为值的名称，消息为以字段名为键的 map[string]any，
+// repeated 字段为 []any，map 字段为以键的字符串形式为键的 map[string]any，其余为对应的 Go 基本类型
+func optionValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch {
+	case fd.IsList():
+		list := v.List()
+		items := make([]any, list.Len())
+		for i := range items {
+			items[i] = optionScalar(fd, list.Get(i))
+		}
+		return items
+	case fd.IsMap():
+		entries := make(map[string]any)
+		v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			entries[fmt.Sprint(k.Interface())] = optionScalar(fd.MapValue(), v)
+			return true
+		})
+		return entries
+	}
+	return optionScalar(fd, v)
+}
+
+// optionScalar 转换单个值，见 optionValue
+func optionScalar(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return int32(v.Enum())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		fields := make(map[string]any)
+		v.Message().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+			fields[string(fd.Name())] = optionValue(fd, v)
+			return true
+		})
+		return fields
+	}
+	return v.Interface()
+}
@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 模板函数 datafile 读取 data_dir 下的 YAML 或 JSON 数据文件，用于把外部维护的数据（如端口分配）
+// 与 proto 中的声明合并:
+//
+//	{{with index (datafile "ports.yaml") .RegisteredName}}Port: {{.}}{{end}}
+//
+// 文件按 YAML 解析（JSON 是 YAML 的子集），映射为 map[string]any，列表为 []any，
+// 整数为 int，因此 JSON 中的 8080 与 YAML 中的一样可直接用于 printf "%d"。
+
+// dataFiles 已读取的数据文件，同一文件在一次生成中只读取一次
+var dataFiles = struct {
+	sync.Mutex
+	dir   string              // 数据文件目录，来自插件参数 data_dir
+	files map[string]dataFile // 键为相对于 dir 的文件名
+}{files: map[string]dataFile{}}
+
+// dataFile 已读取的数据文件
+type dataFile struct {
+	value  any    // 解析后的内容
+	sha256 string // 文件内容的 SHA-256，写入 attestation
+}
+
+// setDataDir 设置 datafile 读取的目录，目录变化时清空已读取的文件
+func setDataDir(dir string) {
+	dataFiles.Lock()
+	defer dataFiles.Unlock()
+	if dataFiles.dir != dir {
+		dataFiles.dir = dir
+		clear(dataFiles.files)
+	}
+}
+
+// readDataFile 模板函数 datafile 的实现，name 为相对于 data_dir 的路径，不能指向 data_dir 之外
+func readDataFile(name string) (any, error) {
+	dataFiles.Lock()
+	defer dataFiles.Unlock()
+	if dataFiles.dir == "" {
+		return nil, fmt.Errorf("datafile %q: 未指定 data_dir 参数", name)
+	}
+	name = filepath.ToSlash(filepath.Clean(name))
+	if !filepath.IsLocal(name) {
+		return nil, fmt.Errorf("datafile %q: 只能读取 data_dir 下的文件", name)
+	}
+	if f, ok := dataFiles.files[name]; ok {
+		return f.value, nil
+	}
+
+	root, err := os.OpenRoot(dataFiles.dir)
+	if err != nil {
+		return nil, fmt.Errorf("datafile %q: 打开 data_dir 失败: %v", name, err)
+	}
+	defer root.Close()
+	// os.Root 同时拒绝经由符号链接逃出 data_dir 的路径
+	content, err := root.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("datafile %q: 读取失败: %v", name, err)
+	}
+	var value any
+	if err := yaml.Unmarshal(content, &value); err != nil {
+		return nil, fmt.Errorf("datafile %q: 解析失败: %v", name, err)
+	}
+	dataFiles.files[name] = dataFile{value: value, sha256: sha256Hex(content)}
+	return value, nil
+}
+
+// dataFileDigests 返回已读取的数据文件摘要，按名称排序
+func dataFileDigests() []Digest {
+	dataFiles.Lock()
+	defer dataFiles.Unlock()
+	digests := []Digest{}
+	for _, name := range slices.Sorted(maps.Keys(dataFiles.files)) {
+		digests = append(digests, Digest{Name: name, SHA256: dataFiles.files[name].sha256})
+	}
+	return digests
+}
@@ -0,0 +1,142 @@
+package generator
+
+import (
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// 模板数据模型的版本，来自插件参数 data_version
+const (
+	// DataVersion1 与引入版本号前相同的扁平结构，已有模板无需修改
+	DataVersion1 = 1
+	// DataVersion2 在 ServiceInfo 中增加结构化的 Proto 字段，对应的扁平字段标记为废弃
+	DataVersion2 = 2
+)
+
+// ProtoInfo 服务所在 proto 文件及其 Go 包的信息，data_version=2 起可用
+type ProtoInfo struct {
+	File          string // proto 文件路径，如 pages/prepare_order/prepare_order.proto
+	Package       string // proto 包名，如 pages.prepare_order
+	GoPackageName string // 生成的 Go 包名，如 prepare_order
+	GoImportPath  string // 生成的 Go 包导入路径
+}
+
+// deprecatedField 在某个数据版本中废弃的模板字段
+type deprecatedField struct {
+	Type        string // 所在结构体类型名
+	Name        string // 字段名
+	Since       int    // 从该数据版本起废弃
+	Replacement string // 替代写法
+}
+
+// deprecatedFields 已废弃的模板字段；废弃字段在所有版本中保留，data_version 不低于 Since 时使用会产生警告
+var deprecatedFields = []deprecatedField{
+	{Type: "ServiceInfo", Name: "ProtoFile", Since: DataVersion2, Replacement: ".Proto.File"},
+	{Type: "ServiceInfo", Name: "ProtoPackageName", Since: DataVersion2, Replacement: ".Proto.GoPackageName"},
+	{Type: "ServiceInfo", Name: "ProtoImportPath", Since: DataVersion2, Replacement: ".Proto.GoImportPath"},
+}
+
+// findDeprecatedField 查找结构体字段的废弃说明
+func findDeprecatedField(typeName, name string) (deprecatedField, bool) {
+	for _, f := range deprecatedFields {
+		if f.Type == typeName && f.Name == name {
+			return f, true
+		}
+	}
+	return deprecatedField{}, false
+}
+
+// applyDataVersion 按 data_version 补充数据：1 保持原有结构，Proto 为 nil
+func applyDataVersion(s *ServiceInfo, version int, protoPackage string) {
+	if version < DataVersion2 {
+		return
+	}
+	s.Proto = &ProtoInfo{
+		File:          s.ProtoFile,
+		Package:       protoPackage,
+		GoPackageName: s.ProtoPackageName,
+		GoImportPath:  s.ProtoImportPath,
+	}
+}
+
+// warnDeprecatedFields 检查用户模板中引用的废弃字段，给出模板位置与替代写法
+//
+// 模板没有类型信息，按字段名匹配；内置模板随插件维护，不做检查。
+func (g *Generator) warnDeprecatedFields(tmpl *template.Template, source string) {
+	active := map[string]deprecatedField{}
+	for _, f := range deprecatedFields {
+		if g.config.DataVersion >= f.Since {
+			active[f.Name] = f
+		}
+	}
+	if len(active) == 0 {
+		return
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil || t.Tree.Root == nil {
+			continue
+		}
+		check := func(node parse.Node, idents []string) {
+			for _, ident := range idents {
+				f, ok := active[ident]
+				if !ok {
+					continue
+				}
+				location, _ := t.Tree.ErrorContext(node)
+				if t.Name() == tmpl.Name() {
+					location = source + strings.TrimPrefix(location, t.Name())
+				}
+				g.warn(location, "模板使用了废弃字段", "field", f.Type+"."+f.Name,
+					"since", f.Since, "replacement", f.Replacement)
+			}
+		}
+		var walk func(node parse.Node)
+		walk = func(node parse.Node) {
+			switch n := node.(type) {
+			case *parse.ListNode:
+				if n == nil {
+					return
+				}
+				for _, child := range n.Nodes {
+					walk(child)
+				}
+			case *parse.ActionNode:
+				walk(n.Pipe)
+			case *parse.IfNode:
+				walk(n.Pipe)
+				walk(n.List)
+				walk(n.ElseList)
+			case *parse.WithNode:
+				walk(n.Pipe)
+				walk(n.List)
+				walk(n.ElseList)
+			case *parse.RangeNode:
+				walk(n.Pipe)
+				walk(n.List)
+				walk(n.ElseList)
+			case *parse.TemplateNode:
+				walk(n.Pipe)
+			case *parse.PipeNode:
+				if n == nil {
+					return
+				}
+				for _, cmd := range n.Cmds {
+					walk(cmd)
+				}
+			case *parse.CommandNode:
+				for _, arg := range n.Args {
+					walk(arg)
+				}
+			case *parse.ChainNode:
+				walk(n.Node)
+				check(n, n.Field)
+			case *parse.FieldNode:
+				check(n, n.Ident)
+			case *parse.VariableNode:
+				check(n, n.Ident[1:])
+			}
+		}
+		walk(t.Tree.Root)
+	}
+}
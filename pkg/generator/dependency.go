@@ -0,0 +1,223 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// orderServices 按依赖关系对服务进行拓扑排序，
+// 同时可注册的服务之间按 priority 升序、再按声明顺序排列
+func orderServices(services []*ServiceInfo) ([]*ServiceInfo, error) {
+	deps, err := resolveDependencies(services)
+	if err != nil {
+		return nil, err
+	}
+
+	// 每个服务尚未注册的依赖数量
+	pending := make([]int, len(services))
+	dependents := make([][]int, len(services))
+	for i, ds := range deps {
+		pending[i] = len(ds)
+		for _, d := range ds {
+			dependents[d] = append(dependents[d], i)
+		}
+	}
+
+	ordered := make([]*ServiceInfo, 0, len(services))
+	done := make([]bool, len(services))
+	for len(ordered) < len(services) {
+		next := -1
+		for i, s := range services {
+			if done[i] || pending[i] > 0 {
+				continue
+			}
+			if next == -1 || s.Priority < services[next].Priority {
+				next = i
+			}
+		}
+		if next == -1 {
+			return nil, fmt.Errorf("服务依赖存在循环: %s", findCycle(services, deps, done))
+		}
+
+		done[next] = true
+		ordered = append(ordered, services[next])
+		for _, d := range dependents[next] {
+			pending[d]--
+		}
+	}
+	return ordered, nil
+}
+
+// ClientDependency (registry.depends_on_clients) 声明的下游服务
+type ClientDependency struct {
+	FullName       string // proto 服务全限定名
+	GoName         string // 下游服务的标识符前缀，用于调用其 Dial 函数
+	RegisteredName string // 下游服务的注册名称
+	Namespace      string // 下游服务的命名空间
+}
+
+// serviceIndex 按全限定名或服务名查找服务
+type serviceIndex struct {
+	byFullName map[string]int
+	byName     map[string][]int
+}
+
+// newServiceIndex 为 services 建立索引
+func newServiceIndex(services []*ServiceInfo) *serviceIndex {
+	idx := &serviceIndex{byFullName: map[string]int{}, byName: map[string][]int{}}
+	for i, s := range services {
+		idx.byFullName[s.FullName] = i
+		name := s.FullName[strings.LastIndex(s.FullName, ".")+1:]
+		idx.byName[name] = append(idx.byName[name], i)
+	}
+	return idx
+}
+
+// lookup 返回服务 s 在 option 中声明的依赖 dep 在 services 中的下标
+func (idx *serviceIndex) lookup(s *ServiceInfo, option, dep string) (int, error) {
+	if d, ok := idx.byFullName[dep]; ok {
+		return d, nil
+	}
+	switch candidates := idx.byName[dep]; len(candidates) {
+	case 0:
+		return 0, fmt.Errorf("服务 %s 的 (registry.%s) 依赖的服务 %s 不存在或未参与本次生成", s.FullName, option, dep)
+	case 1:
+		return candidates[0], nil
+	default:
+		return 0, fmt.Errorf("服务 %s 的 (registry.%s) 依赖的服务名 %s 存在歧义，请使用全限定名", s.FullName, option, dep)
+	}
+}
+
+// resolveDependencies 将 (registry.depends_on) 中的服务名解析为 services 中的下标
+func resolveDependencies(services []*ServiceInfo) ([][]int, error) {
+	idx := newServiceIndex(services)
+	deps := make([][]int, len(services))
+	for i, s := range services {
+		for _, dep := range s.DependsOn {
+			d, err := idx.lookup(s, "depends_on", dep)
+			if err != nil {
+				return nil, err
+			}
+			deps[i] = append(deps[i], d)
+		}
+	}
+	return deps, nil
+}
+
+// resolveClientDependencies 将各服务 (registry.depends_on_clients) 中的服务名解析为 DependsOnClients
+//
+// 下游服务须参与本次生成并输出到同一个包，生成的就绪检查才能调用其 Dial 函数；
+// 服务相互等待对方就绪会使双方都无法报告 SERVING，因此依赖之间不能存在循环。
+func resolveClientDependencies(services []*ServiceInfo) error {
+	idx := newServiceIndex(services)
+	deps := make([][]int, len(services))
+	for i, s := range services {
+		s.DependsOnClients = nil
+		for _, dep := range s.clientDeps {
+			d, err := idx.lookup(s, "depends_on_clients", strings.TrimSpace(dep))
+			if err != nil {
+				return err
+			}
+			target := services[d]
+			switch {
+			case d == i:
+				return fmt.Errorf("服务 %s 的 (registry.depends_on_clients) 不能依赖自身", s.FullName)
+			case slices.Contains(deps[i], d):
+				return fmt.Errorf("服务 %s 的 (registry.depends_on_clients) 重复声明了 %s", s.FullName, target.FullName)
+			case target.outputDir != s.outputDir:
+				return fmt.Errorf("服务 %s 的 (registry.depends_on_clients) 依赖的服务 %s 输出到不同的包", s.FullName, target.FullName)
+			}
+			deps[i] = append(deps[i], d)
+			s.DependsOnClients = append(s.DependsOnClients, ClientDependency{
+				FullName:       target.FullName,
+				GoName:         target.GoName,
+				RegisteredName: target.RegisteredName,
+				Namespace:      target.Namespace,
+			})
+		}
+	}
+	if cycle := findCycle(services, deps, make([]bool, len(services))); cycle != "" {
+		return fmt.Errorf("(registry.depends_on_clients) 存在循环: %s", cycle)
+	}
+	return nil
+}
+
+// resolveAllowedCallers 将各服务 (registry.allowed_callers) 中的服务名解析为注册名称，写入 AllowedCallers
+//
+// 与参与本次生成的服务的全限定名或服务名匹配时取其注册名称，否则视为其他服务的注册名称原样保留。
+func resolveAllowedCallers(services []*ServiceInfo) error {
+	idx := newServiceIndex(services)
+	for _, s := range services {
+		s.AllowedCallers = nil
+		for i, caller := range s.callers {
+			caller = strings.TrimSpace(caller)
+			if caller == "" {
+				return fmt.Errorf("服务 %s 的 (registry.allowed_callers) 第 %d 项为空", s.FullName, i+1)
+			}
+			if _, ok := idx.byFullName[caller]; ok || len(idx.byName[caller]) > 0 {
+				d, err := idx.lookup(s, "allowed_callers", caller)
+				if err != nil {
+					return err
+				}
+				caller = services[d].RegisteredName
+			}
+			if slices.Contains(s.AllowedCallers, caller) {
+				return fmt.Errorf("服务 %s 的 (registry.allowed_callers) 重复声明了 %s", s.FullName, caller)
+			}
+			s.AllowedCallers = append(s.AllowedCallers, caller)
+		}
+	}
+	return nil
+}
+
+// findCycle 在未能排序的服务中找出一条依赖环，格式如 A -> B -> A
+func findCycle(services []*ServiceInfo, deps [][]int, done []bool) string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(services))
+	var stack []int
+
+	var visit func(i int) []int
+	visit = func(i int) []int {
+		state[i] = visiting
+		stack = append(stack, i)
+		for _, d := range deps[i] {
+			if done[d] {
+				continue
+			}
+			switch state[d] {
+			case visiting:
+				for j, k := range stack {
+					if k == d {
+						return append(append([]int{}, stack[j:]...), d)
+					}
+				}
+			case unvisited:
+				if cycle := visit(d); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[i] = visited
+		return nil
+	}
+
+	for i := range services {
+		if done[i] || state[i] != unvisited {
+			continue
+		}
+		if cycle := visit(i); cycle != nil {
+			names := make([]string, len(cycle))
+			for j, k := range cycle {
+				names[j] = services[k].FullName
+			}
+			return strings.Join(names, " -> ")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOrderServices(t *testing.T) {
+	svc := func(fullName string, priority int32, dependsOn ...string) *ServiceInfo {
+		return &ServiceInfo{FullName: fullName, Priority: priority, DependsOn: dependsOn}
+	}
+	tests := []struct {
+		name     string
+		services []*ServiceInfo
+		want     []string
+		errMsg   string
+	}{
+		{name: "无依赖时保持声明顺序", services: []*ServiceInfo{svc("a.A", 0), svc("a.B", 0), svc("a.C", 0)},
+			want: []string{"a.A", "a.B", "a.C"}},
+		{name: "按 priority 升序", services: []*ServiceInfo{svc("a.A", 10), svc("a.B", -1), svc("a.C", 0)},
+			want: []string{"a.B", "a.C", "a.A"}},
+		{name: "依赖优先于 priority", services: []*ServiceInfo{svc("a.A", -5, "B"), svc("a.B", 10), svc("a.C", 0)},
+			want: []string{"a.C", "a.B", "a.A"}},
+		{name: "全限定名依赖", services: []*ServiceInfo{svc("a.A", 0, "b.A"), svc("b.A", 0)},
+			want: []string{"b.A", "a.A"}},
+		{name: "依赖链", services: []*ServiceInfo{svc("a.A", 0, "a.B"), svc("a.B", 0, "C"), svc("a.C", 0)},
+			want: []string{"a.C", "a.B", "a.A"}},
+		{name: "依赖不存在", services: []*ServiceInfo{svc("a.A", 0, "Missing")},
+			errMsg: "服务 a.A 的 (registry.depends_on) 依赖的服务 Missing 不存在或未参与本次生成"},
+		{name: "服务名存在歧义", services: []*ServiceInfo{svc("a.A", 0, "B"), svc("a.B", 0), svc("b.B", 0)},
+			errMsg: "依赖的服务名 B 存在歧义"},
+		{name: "循环依赖", services: []*ServiceInfo{svc("a.A", 0, "B"), svc("a.B", 0, "A")},
+			errMsg: "服务依赖存在循环: a.A -> a.B -> a.A"},
+		{name: "依赖自身", services: []*ServiceInfo{svc("a.A", 0), svc("a.B", 0, "B")},
+			errMsg: "服务依赖存在循环: a.B -> a.B"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered, err := orderServices(tt.services)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("orderServices 的错误为 %v，期望包含 %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("orderServices 失败: %v", err)
+			}
+			var got []string
+			for _, s := range ordered {
+				got = append(got, s.FullName)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("orderServices = %v，期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindCycle(t *testing.T) {
+	services := []*ServiceInfo{{FullName: "a.A"}, {FullName: "a.B"}, {FullName: "a.C"}, {FullName: "a.D"}}
+	tests := []struct {
+		name string
+		deps [][]int
+		done []bool
+		want string
+	}{
+		{name: "无环", deps: [][]int{{1}, {2}, nil, {0}}, want: ""},
+		{name: "两个服务成环", deps: [][]int{{1}, {0}, nil, nil}, want: "a.A -> a.B -> a.A"},
+		{name: "环不含起点", deps: [][]int{{1}, {2}, {3}, {1}}, want: "a.B -> a.C -> a.D -> a.B"},
+		{name: "自环", deps: [][]int{nil, nil, {2}, nil}, want: "a.C -> a.C"},
+		{name: "忽略已排序的服务", deps: [][]int{{1}, {0}, {3}, {2}}, done: []bool{true, false, false, false},
+			want: "a.C -> a.D -> a.C"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done := tt.done
+			if done == nil {
+				done = make([]bool, len(services))
+			}
+			if got := findCycle(services, tt.deps, done); got != tt.want {
+				t.Errorf("findCycle = %q，期望 %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultDNSDomain Kubernetes 默认的集群域名
+const defaultDNSDomain = "cluster.local"
+
+// dnsLabel 模板函数 dnsLabel 的实现，将名称转换为 RFC 1123 DNS 标签，用作 Kubernetes 服务名与命名空间:
+// 转为小写，字母数字以外的字符替换为 -，去掉首尾的 -，超过 63 个字符时截断，如 pages.prepare_order -> pages-prepare-order
+func dnsLabel(name string) (string, error) {
+	label := []byte(strings.ToLower(name))
+	for i, c := range label {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			label[i] = '-'
+		}
+	}
+	s := strings.Trim(string(label), "-")
+	if len(s) > 63 {
+		s = strings.TrimRight(s[:63], "-")
+	}
+	if s == "" {
+		return "", fmt.Errorf("dnsLabel: %q 不包含可用于 DNS 标签的字符", name)
+	}
+	return s, nil
+}
+
+// parseDNSDomain 解析 dns_domain 参数，每一段都须为合法的 DNS 标签
+func parseDNSDomain(v string) (string, error) {
+	for _, part := range strings.Split(v, ".") {
+		if label, err := dnsLabel(part); err != nil || label != part {
+			return "", fmt.Errorf("dns_domain 不是合法的域名: %s", v)
+		}
+	}
+	return v, nil
+}
+
+// protoDuration 模板函数 protoDuration 的实现，将时长转为 protobuf Duration 的 JSON 格式，
+// 即 Istio 等配置接受的秒数，如 1m30s -> 90s、500ms -> 0.5s
+func protoDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
@@ -0,0 +1,31 @@
+// Package generator 实现 protoc-gen-service-registry 的核心逻辑：
+// 插件参数解析、服务元数据提取以及模板渲染。
+//
+// 除了作为 protoc 插件运行外，其他工具也可以直接嵌入本包，
+// 复用相同的服务信息提取与渲染流程，而无需调用 protoc:
+//
+//	config, err := generator.ParsePluginOptions("template=local_service_center")
+//	if err != nil {
+//		return err
+//	}
+//	g, err := generator.New(config)
+//	if err != nil {
+//		return err
+//	}
+//	return g.Generate(plugin) // plugin 为 *protogen.Plugin
+//
+// 如果只需要渲染单个服务，可以使用 NewServiceInfo 构建模板数据，
+// 再调用 Generator.Render 得到格式化后的代码。
+//
+// 调用 Generate 前可以通过 AddValidator 注册生成前校验，落实团队约定:
+//
+//	g.AddValidator(generator.ValidatorFunc(func(services []*generator.ServiceInfo) error {
+//		var errs []error
+//		for _, s := range services {
+//			if len(s.Owners) == 0 {
+//				errs = append(errs, fmt.Errorf("服务 %s 未声明 (registry.owner)", s.FullName))
+//			}
+//		}
+//		return errors.Join(errs...)
+//	}))
+package generator
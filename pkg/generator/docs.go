@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"fmt"
+	"text/template"
+)
+
+// docTemplate 服务文档模板及其输出文件名
+type docTemplate struct {
+	file string
+	tmpl *template.Template
+}
+
+// docTemplates docs_dir 非空时为每个服务渲染的内置模板及其输出文件名
+var docTemplates = []struct{ file, name string }{
+	{"README.md", "service_readme.md"},
+	{"OWNERS", "service_owners"},
+}
+
+// loadDocs 加载服务文档模板
+func (g *Generator) loadDocs() error {
+	if g.config.DocsDir == "" {
+		return nil
+	}
+	for _, d := range docTemplates {
+		content, err := LoadBuiltinTemplate(d.name)
+		if err != nil {
+			return fmt.Errorf("加载文档模板失败: %v", err)
+		}
+		tmpl, err := newTemplate(d.name).Parse(content)
+		if err != nil {
+			return fmt.Errorf("解析模板 %s 失败: %v", d.name, err)
+		}
+		g.docs = append(g.docs, &docTemplate{file: d.file, tmpl: tmpl})
+		g.recordTemplate(d.name, content)
+	}
+	return nil
+}
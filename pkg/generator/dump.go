@@ -0,0 +1,54 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// dumpDataTemplate dump_data 输出在生成报告中记录的模板名
+const dumpDataTemplate = "dump_data"
+
+// DumpDataVersion dump_data 输出格式的版本号
+//
+// 新增字段保持兼容，不改变版本号；删除、重命名字段或改变字段类型时递增，
+// 外部工具可根据 Version 字段或 schema 的 $id 判断能否读取。
+const DumpDataVersion = 1
+
+// DumpDataSchemaID dump_data 输出对应的 JSON Schema 标识，随 DumpDataVersion 变化
+var DumpDataSchemaID = fmt.Sprintf("https://github.com/lhdbsbz/protoc-gen-service-registry/schema/dump_data.v%d.json", DumpDataVersion)
+
+// DumpData dump_data 参数输出的模板数据，字段名与模板中引用的名称一致
+type DumpData struct {
+	Version   int            // 输出格式版本号，即 DumpDataVersion
+	Parameter string         // 插件参数
+	Data      *AggregateInfo // 汇总模板的数据，其中 Services 为各服务模板的数据
+}
+
+// DumpDataSchema 返回 dump_data 输出的 JSON Schema，直接从 Go 结构体反射得到
+func DumpDataSchema() map[string]any {
+	schema := JSONSchema(&DumpData{})
+	schema["$id"] = DumpDataSchemaID
+	schema["description"] = "protoc-gen-service-registry dump_data 输出的模板数据"
+	properties := schema["properties"].(map[string]any)
+	properties["Version"].(map[string]any)["const"] = DumpDataVersion
+	schema["required"] = []string{"Version", "Parameter", "Data"}
+	return schema
+}
+
+// writeDumpData 将模板数据以 JSON 输出到 dump_data 参数指定的文件
+func (g *Generator) writeDumpData(gen *protogen.Plugin, aggregate *AggregateInfo) error {
+	if g.config.DumpDataFile == "" {
+		return nil
+	}
+	content, err := json.MarshalIndent(DumpData{
+		Version:   DumpDataVersion,
+		Parameter: g.parameter(gen),
+		Data:      aggregate,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("导出模板数据失败: %v", err)
+	}
+	return g.writeFile(gen, g.config.DumpDataFile, append(content, '\n'), nil, dumpDataTemplate)
+}
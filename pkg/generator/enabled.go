@@ -0,0 +1,71 @@
+package generator
+
+import (
+	"fmt"
+	"go/build/constraint"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// generateEnabledGuard 为声明了 (registry.enabled_when) 的服务生成启用条件判断函数
+//
+// env 条件生成一个读取环境变量的函数；build 条件生成一对互斥构建约束的文件，
+// 分别返回 true 和 false，保证任意构建下注册代码都能编译。
+//
+// 服务模板输出非 Go 文件（output_ext 不为 .go）时不生成。
+func (g *Generator) generateEnabledGuard(gen *protogen.Plugin, data *ServiceInfo) error {
+	if data.EnabledWhen == "" || filepath.Ext(g.OutputPath(data)) != ".go" {
+		return nil
+	}
+
+	kind, expr, _ := strings.Cut(data.EnabledWhen, ":")
+	expr = strings.TrimSpace(expr)
+	base := filepath.Join(g.outputDir(data), toCamelCase(data.GoName))
+
+	switch kind {
+	case "env":
+		name, value, hasValue := strings.Cut(expr, "=")
+		if name == "" {
+			return fmt.Errorf("服务 %s 的 enabled_when 缺少环境变量名: %s", data.FullName, data.EnabledWhen)
+		}
+		check := fmt.Sprintf("os.Getenv(%q) != \"\"", name)
+		if hasValue {
+			check = fmt.Sprintf("os.Getenv(%q) == %q", name, value)
+		}
+		src := fmt.Sprintf("package %s\n\nimport \"os\"\n\n// %s 环境变量满足 %s 时启用%s服务\nfunc %s() bool {\n\treturn %s\n}\n",
+			data.PackageName, data.EnabledFunc, expr, data.ServiceName, data.EnabledFunc, check)
+		return g.writeGoFile(gen, base+"_enabled.go", src, data)
+
+	case "build":
+		if _, err := constraint.Parse("//go:build " + expr); err != nil {
+			return fmt.Errorf("服务 %s 的 enabled_when 不是合法的构建约束: %s", data.FullName, expr)
+		}
+		for _, variant := range []struct {
+			suffix, constraint, result string
+		}{
+			{"_enabled.go", expr, "true"},
+			{"_disabled.go", "!(" + expr + ")", "false"},
+		} {
+			src := fmt.Sprintf("//go:build %s\n\npackage %s\n\n// %s 满足构建约束 %s 时启用%s服务\nfunc %s() bool {\n\treturn %s\n}\n",
+				variant.constraint, data.PackageName, data.EnabledFunc, expr, data.ServiceName, data.EnabledFunc, variant.result)
+			if err := g.writeGoFile(gen, base+variant.suffix, src, data); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("服务 %s 的 enabled_when 格式错误，应为 env:NAME[=value] 或 build:expr: %s", data.FullName, data.EnabledWhen)
+	}
+}
+
+// writeGoFile 格式化并输出启用条件的 Go 源文件
+func (g *Generator) writeGoFile(gen *protogen.Plugin, path, src string, data *ServiceInfo) error {
+	formatted, err := g.format(path, []byte(src))
+	if err != nil {
+		return err
+	}
+	return g.writeFile(gen, path, formatted, data, "enabled_when")
+}
@@ -0,0 +1,48 @@
+package generator
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// fieldMaskType 字段掩码消息的全限定名
+const fieldMaskType = "google.protobuf.FieldMask"
+
+// FieldMaskInfo 遵循 AIP-134 的 Update 方法：请求含要更新的资源与指定更新字段的 google.protobuf.FieldMask
+type FieldMaskInfo struct {
+	MaskField     string       // 请求中字段掩码的字段名，如 update_mask
+	MaskGetter    string       // 取字段掩码的 Go 方法名，如 GetUpdateMask
+	ResourceField string       // 请求中要更新的资源的字段名，为请求中第一个非字段掩码的消息字段，如 order
+	Resource      *MessageInfo // 资源消息，字段掩码中的路径须为其字段
+}
+
+// newFieldMask 检测名称以 Update 开头、请求含 google.protobuf.FieldMask 与资源消息字段的一元方法，其他方法为 nil
+func newFieldMask(file *protogen.File, method *protogen.Method) *FieldMaskInfo {
+	if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() || !strings.HasPrefix(string(method.Desc.Name()), "Update") {
+		return nil
+	}
+	var mask, resource *protogen.Field
+	for _, f := range method.Input.Fields {
+		if f.Message == nil || f.Desc.IsList() || f.Desc.IsMap() || f.Desc.ContainingOneof() != nil {
+			continue
+		}
+		switch {
+		case f.Message.Desc.FullName() == fieldMaskType:
+			if mask == nil {
+				mask = f
+			}
+		case resource == nil:
+			resource = f
+		}
+	}
+	if mask == nil || resource == nil {
+		return nil
+	}
+	return &FieldMaskInfo{
+		MaskField:     string(mask.Desc.Name()),
+		MaskGetter:    "Get" + mask.GoName,
+		ResourceField: string(resource.Desc.Name()),
+		Resource:      newMessageInfo(file, resource.Message),
+	}
+}
@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// format 按输出文件扩展名格式化内容
+//
+// 配置了 formatter 的扩展名使用外部命令（包括 .go，此时替代 gofmt），
+// 未配置时 .go 文件使用 gofmt，其他文件原样输出。
+func (g *Generator) format(path string, raw []byte) ([]byte, error) {
+	ext := filepath.Ext(path)
+	if ext == ".go" {
+		resolved, err := resolveImports(raw)
+		if err != nil {
+			return nil, err
+		}
+		raw = resolved
+	}
+	if command, ok := g.config.Formatters[ext]; ok {
+		return runFormatter(command, path, raw)
+	}
+	if ext == ".go" {
+		return formatSource(raw)
+	}
+	return raw, nil
+}
+
+// runFormatter 通过 shell 执行格式化命令，内容从 stdin 输入，从 stdout 读取结果
+//
+// 命令可通过环境变量 SERVICE_REGISTRY_FILE 获取输出文件路径，例如用于 prettier --stdin-filepath。
+func runFormatter(command, path string, raw []byte) ([]byte, error) {
+	cmd := exec.Command("sh", "-c", command)
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(raw)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), "SERVICE_REGISTRY_FILE="+path)
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("格式化 %s 失败，命令 %q: %v: %s", path, command, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// parseFormatter 解析 formatter 参数，格式为 .ext:command
+func parseFormatter(value string) (ext, command string, err error) {
+	ext, command, ok := strings.Cut(value, ":")
+	ext, command = strings.TrimSpace(ext), strings.TrimSpace(command)
+	if !ok || !strings.HasPrefix(ext, ".") || command == "" {
+		return "", "", fmt.Errorf("formatter 格式错误，应为 .ext:command: %s", value)
+	}
+	return ext, command, nil
+}
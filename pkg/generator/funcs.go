@@ -0,0 +1,113 @@
+package generator
+
+import (
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// templateFunc 模板函数定义，同时用于渲染与 schema 输出
+type templateFunc struct {
+	name  string // 模板中的函数名
+	usage string // 函数说明
+	fn    any    // 函数实现
+}
+
+// templateFuncs 所有模板（服务模板、汇总模板）可用的函数
+var templateFuncs = []templateFunc{
+	{name: "lowerCamel", usage: "大驼峰转小驼峰，如 PrepareOrder -> prepareOrder", fn: toCamelCase},
+	{name: "snakeToCamel", usage: "下划线命名转小驼峰，如 register_all -> registerAll", fn: snakeToCamel},
+	{name: "kebab", usage: "大驼峰转 kebab-case，如 HTTPGateway -> http-gateway", fn: toKebabCase},
+	{name: "snake", usage: "大驼峰转下划线命名，如 HTTPGateway -> http_gateway", fn: func(s string) string {
+		return strings.ReplaceAll(toKebabCase(s), "-", "_")
+	}},
+	{name: "goIdentifier", usage: "任意名称转为合法的 Go 标识符，如 order-v2 -> orderV2、2fa -> _2fa、type -> type_；其余参数为已占用的名称，冲突时追加 2、3……", fn: goIdentifier},
+	{name: "goExported", usage: "任意名称转为导出的 Go 标识符，如 order-v2 -> OrderV2、2fa -> X2fa；其余参数为已占用的名称", fn: goExported},
+	{name: "goUnexported", usage: "任意名称转为不导出的 Go 标识符，如 HTTPGateway -> httpGateway、Type -> type_；其余参数为已占用的名称", fn: goUnexported},
+	{name: "import", usage: "声明 Go 代码可能用到的包，只有实际引用了的包才会加入 import 块，第二个参数可指定包名，如 import \"google.golang.org/grpc/codes\"", fn: importFunc},
+	{name: "dnsLabel", usage: "任意名称转为 DNS 标签（小写字母、数字与 -，不超过 63 个字符），如 pages.prepare_order -> pages-prepare-order", fn: dnsLabel},
+	{name: "protoDuration", usage: "时长转为 protobuf Duration 的 JSON 格式（秒数），如 1m30s -> 90s、500ms -> 0.5s", fn: protoDuration},
+	{name: "lower", usage: "转为小写", fn: strings.ToLower},
+	{name: "upper", usage: "转为大写", fn: strings.ToUpper},
+	{name: "trimSuffix", usage: "去掉后缀，参数顺序为 (后缀, 字符串)，便于管道使用", fn: func(suffix, s string) string {
+		return strings.TrimSuffix(s, suffix)
+	}},
+	{name: "replace", usage: "替换所有出现的子串，参数顺序为 (旧, 新, 字符串)，便于管道使用", fn: func(old, new, s string) string {
+		return strings.ReplaceAll(s, old, new)
+	}},
+	{name: "graphqlType", usage: "字段的 GraphQL 类型，第二个参数为 true 时消息使用 Input 类型", fn: graphqlType},
+	{name: "graphqlScalar", usage: "well-known 消息对应的 GraphQL 标量，其他消息返回空字符串", fn: graphqlScalar},
+	{name: "graphqlOperation", usage: "方法对应的 GraphQL 操作类型，Get/List 等前缀为 Query，其余为 Mutation", fn: graphqlOperation},
+	{name: "jsonSchema", usage: "字段值的 JSON Schema（JSON 编码），消息与枚举引用 前缀+TypeName，参数顺序为 (前缀, 字段)", fn: jsonSchema},
+	{name: "jsonSchemaRef", usage: "消息的 JSON Schema（JSON 编码），well-known types 内联，参数为 (前缀, FullName, TypeName)", fn: jsonSchemaRef},
+	{name: "tsType", usage: "字段的 TypeScript 类型，与 protojson 编码一致", fn: tsType},
+	{name: "tsMessageType", usage: "消息的 TypeScript 类型，参数为 (FullName, TypeName)，well-known types 为对应的 JSON 类型", fn: tsMessageType},
+	{name: "wellKnown", usage: "消息是否为 google.protobuf 中以 JSON 原生类型表示的 well-known type，参数为 FullName", fn: isJSONWellKnown},
+	{name: "cel", usage: "对模板数据求值 CEL 表达式，如 cel \"Methods.exists(m, m.ServerStreaming)\" .，支持的语法见 cel.go", fn: celEval},
+	{name: "datafile", usage: "读取 data_dir 下的 YAML 或 JSON 文件，返回 map[string]any 等，如 index (datafile \"ports.yaml\") .RegisteredName；不能读取 data_dir 之外的文件", fn: readDataFile},
+	{name: "join", usage: "以分隔符连接字符串列表，参数顺序为 (分隔符, 列表)", fn: func(sep string, items []string) string {
+		return strings.Join(items, sep)
+	}},
+}
+
+// builtinFuncs text/template 内置函数，仅用于 schema 输出
+var builtinFuncs = []FuncDoc{
+	{Name: "and", Usage: "逻辑与，返回第一个为空的参数或最后一个参数"},
+	{Name: "or", Usage: "逻辑或，返回第一个非空的参数或最后一个参数"},
+	{Name: "not", Usage: "逻辑非"},
+	{Name: "len", Usage: "返回长度"},
+	{Name: "index", Usage: "按下标或键取值"},
+	{Name: "slice", Usage: "切片"},
+	{Name: "print", Usage: "同 fmt.Sprint"},
+	{Name: "printf", Usage: "同 fmt.Sprintf"},
+	{Name: "println", Usage: "同 fmt.Sprintln"},
+	{Name: "eq", Usage: "等于"},
+	{Name: "ne", Usage: "不等于"},
+	{Name: "lt", Usage: "小于"},
+	{Name: "le", Usage: "小于等于"},
+	{Name: "gt", Usage: "大于"},
+	{Name: "ge", Usage: "大于等于"},
+	{Name: "call", Usage: "调用函数类型的值"},
+	{Name: "html", Usage: "HTML 转义"},
+	{Name: "js", Usage: "JavaScript 转义"},
+	{Name: "urlquery", Usage: "URL 查询参数转义"},
+}
+
+// FuncDoc 模板函数说明
+type FuncDoc struct {
+	Name      string // 函数名
+	Signature string // Go 函数签名，内置函数为空
+	Usage     string // 函数说明
+	Builtin   bool   // 是否为 text/template 内置函数
+}
+
+// funcMap 返回注册到模板中的函数
+func funcMap() template.FuncMap {
+	funcs := template.FuncMap{}
+	for _, f := range templateFuncs {
+		funcs[f.name] = f.fn
+	}
+	return funcs
+}
+
+// newTemplate 创建注册了模板函数的模板
+func newTemplate(name string) *template.Template {
+	return template.New(name).Funcs(funcMap())
+}
+
+// TemplateFuncs 返回模板中可用的函数，插件提供的函数在前，text/template 内置函数在后
+func TemplateFuncs() []FuncDoc {
+	docs := make([]FuncDoc, 0, len(templateFuncs)+len(builtinFuncs))
+	for _, f := range templateFuncs {
+		docs = append(docs, FuncDoc{
+			Name:      f.name,
+			Signature: strings.TrimPrefix(reflect.TypeOf(f.fn).String(), "func"),
+			Usage:     f.usage,
+		})
+	}
+	for _, f := range builtinFuncs {
+		f.Builtin = true
+		docs = append(docs, f)
+	}
+	return docs
+}
@@ -0,0 +1,98 @@
+package generator
+
+import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"text/template"
+)
+
+// FuncsPluginSymbol Go 插件中导出模板函数的符号名
+//
+// 插件以 go build -buildmode=plugin 构建，导出以下任一形式的 TemplateFuncs:
+//
+//	var TemplateFuncs = map[string]any{"serviceID": lookupServiceID}
+//	func TemplateFuncs() map[string]any { ... }
+//
+// 插件必须与本插件使用相同的 Go 版本构建，共同依赖的包版本也必须一致。
+// Go 插件依赖 cgo，只有以 CGO_ENABLED=1 构建的 linux、darwin、freebsd 版本支持 funcs_plugin，
+// CGO_ENABLED=0 的静态构建（如 Dockerfile 构建的 buf 远程插件镜像）会拒绝该参数，见 funcsPluginSupported。
+const FuncsPluginSymbol = "TemplateFuncs"
+
+// RegisterTemplateFunc 注册自定义模板函数，对之后创建的生成器生效
+//
+// 库模式下可直接调用；funcs_plugin 加载的函数也通过它注册。
+// fn 须为函数，返回一个值，或一个值与 error；name 不能与已有函数重名。
+func RegisterTemplateFunc(name, usage string, fn any) error {
+	if !token.IsIdentifier(name) {
+		return fmt.Errorf("模板函数名 %q 不是合法的标识符", name)
+	}
+	if slices.ContainsFunc(templateFuncs, func(f templateFunc) bool { return f.name == name }) ||
+		slices.ContainsFunc(builtinFuncs, func(f FuncDoc) bool { return f.Name == name }) {
+		return fmt.Errorf("模板函数 %s 已存在", name)
+	}
+	t := reflect.TypeOf(fn)
+	if t == nil || t.Kind() != reflect.Func {
+		return fmt.Errorf("模板函数 %s 不是函数", name)
+	}
+	if t.NumOut() == 0 || t.NumOut() > 2 || t.NumOut() == 2 && t.Out(1) != reflect.TypeFor[error]() {
+		return fmt.Errorf("模板函数 %s 必须返回一个值，或一个值与 error", name)
+	}
+	templateFuncs = append(templateFuncs, templateFunc{name: name, usage: usage, fn: fn})
+	return nil
+}
+
+// loadedFuncsPlugins 已加载的插件路径，同一进程内多次创建生成器时不重复注册
+var loadedFuncsPlugins = map[string]bool{}
+
+// loadFuncsPlugins 加载 funcs_plugin 指定的插件并注册其中的模板函数
+func loadFuncsPlugins(paths []string) error {
+	for _, path := range paths {
+		if loadedFuncsPlugins[path] {
+			continue
+		}
+		funcs, err := openFuncsPlugin(path)
+		if err != nil {
+			return fmt.Errorf("加载模板函数插件 %s 失败: %v", path, err)
+		}
+		// 按名称排序注册，保证 schema 输出稳定
+		names := make([]string, 0, len(funcs))
+		for name := range funcs {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			usage := fmt.Sprintf("由 %s 提供", filepath.Base(path))
+			if err := RegisterTemplateFunc(name, usage, funcs[name]); err != nil {
+				return fmt.Errorf("加载模板函数插件 %s 失败: %v", path, err)
+			}
+		}
+		// 加载成功后才记录，失败的插件在下次创建生成器时重新报错
+		loadedFuncsPlugins[path] = true
+	}
+	return nil
+}
+
+// openFuncsPlugin 打开 Go 插件并读取导出的模板函数
+func openFuncsPlugin(path string) (map[string]any, error) {
+	if filepath.Ext(path) == ".wasm" {
+		return nil, fmt.Errorf("暂不支持 WASM 插件，请使用 go build -buildmode=plugin 构建的 .so 文件")
+	}
+	sym, err := lookupPluginSymbol(path, FuncsPluginSymbol)
+	if err != nil {
+		return nil, err
+	}
+	switch v := sym.(type) {
+	case *map[string]any:
+		return *v, nil
+	case *template.FuncMap:
+		return *v, nil
+	case func() map[string]any:
+		return v(), nil
+	case func() template.FuncMap:
+		return v(), nil
+	}
+	return nil, fmt.Errorf("%s 的类型 %T 不受支持，应为 map[string]any 或 func() map[string]any", FuncsPluginSymbol, sym)
+}
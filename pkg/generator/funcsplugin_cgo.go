@@ -0,0 +1,17 @@
+//go:build cgo && (linux || darwin || freebsd)
+
+package generator
+
+import "plugin"
+
+// funcsPluginSupported 当前构建是否支持 Go 插件，即 funcs_plugin 参数
+const funcsPluginSupported = true
+
+// lookupPluginSymbol 打开 Go 插件并查找导出的符号
+func lookupPluginSymbol(path, name string) (any, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.Lookup(name)
+}
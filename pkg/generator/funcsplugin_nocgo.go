@@ -0,0 +1,13 @@
+//go:build !cgo || !(linux || darwin || freebsd)
+
+package generator
+
+import "errors"
+
+// funcsPluginSupported 当前构建是否支持 Go 插件，即 funcs_plugin 参数
+const funcsPluginSupported = false
+
+// lookupPluginSymbol 未启用 cgo 或系统不支持 Go 插件时总是返回错误
+func lookupPluginSymbol(path, name string) (any, error) {
+	return nil, errors.New("当前构建不支持 Go 插件")
+}
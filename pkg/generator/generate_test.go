@@ -0,0 +1,498 @@
+package generator
+
+import (
+	"context"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/cmd/protoc-gen-go/internal_gengo"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// testRequest 用 protocompile 编译内存中的 proto 源文件，构造与 protoc 传给插件相同的 CodeGeneratorRequest
+//
+// 源文件可以导入本插件已链接的 proto，如 registry/annotations.proto 与 google/api/annotations.proto。
+func testRequest(t testing.TB, sources map[string]string, param string) *pluginpb.CodeGeneratorRequest {
+	t.Helper()
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(protocompile.CompositeResolver{
+			&protocompile.SourceResolver{Accessor: protocompile.SourceAccessorFromMap(sources)},
+			protocompile.ResolverFunc(func(path string) (protocompile.SearchResult, error) {
+				fd, err := protoregistry.GlobalFiles.FindFileByPath(path)
+				if err != nil {
+					return protocompile.SearchResult{}, err
+				}
+				return protocompile.SearchResult{Desc: fd}, nil
+			}),
+		}),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+	files := slices.Sorted(maps.Keys(sources))
+	compiled, err := compiler.Compile(context.Background(), files...)
+	if err != nil {
+		t.Fatalf("编译 proto 失败: %v", err)
+	}
+
+	var protoFiles []*descriptorpb.FileDescriptorProto
+	seen := map[string]bool{}
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		protoFiles = append(protoFiles, protodesc.ToFileDescriptorProto(fd))
+	}
+	for _, fd := range compiled {
+		add(fd)
+	}
+
+	// 经过一次序列化，使自定义选项按已链接的扩展重新解析，未链接的扩展保留为未知字段
+	raw, err := proto.Marshal(&pluginpb.CodeGeneratorRequest{
+		FileToGenerate: files,
+		Parameter:      proto.String(param),
+		ProtoFile:      protoFiles,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &pluginpb.CodeGeneratorRequest{}
+	if err := proto.Unmarshal(raw, req); err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+// testPlugin 编译 proto 源文件并创建 protogen 插件
+func testPlugin(t testing.TB, sources map[string]string, param string) *protogen.Plugin {
+	t.Helper()
+	gen, err := protogen.Options{}.New(testRequest(t, sources, param))
+	if err != nil {
+		t.Fatalf("创建插件失败: %v", err)
+	}
+	return gen
+}
+
+// testMethods 返回 proto 源文件 path 中第一个服务的方法，按名称索引
+func testMethods(t testing.TB, gen *protogen.Plugin, path string) map[string]*protogen.Method {
+	t.Helper()
+	file, ok := gen.FilesByPath[path]
+	if !ok || len(file.Services) == 0 {
+		t.Fatalf("%s 中没有服务", path)
+	}
+	methods := map[string]*protogen.Method{}
+	for _, m := range file.Services[0].Methods {
+		methods[m.GoName] = m
+	}
+	return methods
+}
+
+// e2eProto 端到端测试的 proto，覆盖 HTTP 转码、审计、租户、流式方法与依赖排序
+const e2eProto = `syntax = "proto3";
+
+package shop.v1;
+
+import "google/api/annotations.proto";
+import "registry/annotations.proto";
+
+option go_package = "example.com/e2e/shoppb";
+
+// CatalogService 商品目录
+service CatalogService {
+  rpc GetItem(GetItemRequest) returns (Item) {
+    option (google.api.http) = { get: "/v1/{name=items/*}" };
+  }
+  rpc ListItems(ListItemsRequest) returns (ListItemsResponse) {
+    option (google.api.http) = { get: "/v1/items/" };
+  }
+  rpc WatchItems(ListItemsRequest) returns (stream Item);
+}
+
+// OrderService 订单
+service OrderService {
+  option (registry.depends_on) = "CatalogService";
+
+  rpc CreateOrder(CreateOrderRequest) returns (Order) {
+    option (google.api.http) = { post: "/v1/orders" body: "*" };
+    option (registry.audit) = true;
+    option (registry.audit_resource) = "item";
+    option (registry.tenant_field) = "tenant_id";
+  }
+}
+
+message GetItemRequest { string name = 1; }
+message Item { string name = 1; int64 price = 2; }
+message ListItemsRequest { int32 page_size = 1; string page_token = 2; }
+message ListItemsResponse { repeated Item items = 1; string next_page_token = 2; }
+message CreateOrderRequest { string tenant_id = 1; string item = 2; }
+message Order { string id = 1; }
+`
+
+// e2eUserProto 与 e2eOrderProto 的 Go 包名都是 v1，汇总文件须为其中一个使用别名
+const e2eUserProto = `syntax = "proto3";
+
+package user.v1;
+
+import "google/api/annotations.proto";
+
+option go_package = "example.com/e2e/user/v1";
+
+service UserService {
+  rpc GetUser(GetUserRequest) returns (User) {
+    option (google.api.http) = { get: "/v1/users/{id}" };
+  }
+}
+
+message GetUserRequest { string id = 1; }
+message User { string id = 1; string name = 2; }
+`
+
+const e2eOrderProto = `syntax = "proto3";
+
+package order.v1;
+
+import "google/api/annotations.proto";
+import "user/v1/user.proto";
+
+option go_package = "example.com/e2e/order/v1";
+
+service OrderService {
+  rpc GetBuyer(GetBuyerRequest) returns (user.v1.User) {
+    option (google.api.http) = { get: "/v1/orders/{order_id}/buyer" };
+  }
+  rpc ListBuyers(user.v1.GetUserRequest) returns (ListBuyersResponse) {
+    option (google.api.http) = { get: "/v1/buyers" };
+  }
+}
+
+message GetBuyerRequest { string order_id = 1; }
+message ListBuyersResponse { repeated user.v1.User buyers = 1; }
+`
+
+// e2eCommonProto 被其他包引用的消息，go_package 以 ;commonv1 指定了与导入路径最后一段不同的包名
+const e2eCommonProto = `syntax = "proto3";
+
+package common.v1;
+
+import "registry/annotations.proto";
+
+option go_package = "example.com/e2e/commonpb;commonv1";
+
+message GetRequest { string tenant_id = 1; string id = 2; }
+message Product {
+  string id = 1;
+  string name = 2;
+  string cost = 3 [(registry.sensitive) = true];
+}
+`
+
+// e2eAuditProto 请求与响应都在 common.v1 包中的审计方法
+const e2eAuditProto = `syntax = "proto3";
+
+package ledger.v1;
+
+import "common/v1/common.proto";
+import "registry/annotations.proto";
+
+option go_package = "example.com/e2e/ledgerpb";
+
+service LedgerService {
+  rpc GetEntry(common.v1.GetRequest) returns (common.v1.Product) {
+    option (registry.audit) = true;
+    option (registry.audit_resource) = "id";
+  }
+}
+`
+
+// e2eTenantProto 从 common.v1 包中的请求取租户的方法
+const e2eTenantProto = `syntax = "proto3";
+
+package quota.v1;
+
+import "common/v1/common.proto";
+import "registry/annotations.proto";
+
+option go_package = "example.com/e2e/quotapb";
+
+service QuotaService {
+  rpc GetQuota(common.v1.GetRequest) returns (common.v1.Product) {
+    option (registry.tenant_field) = "tenant_id";
+  }
+}
+`
+
+// e2eCatalogProto 方法的请求、响应、分页结果与字段掩码资源大多位于 common.v1 与 google.protobuf 包
+const e2eCatalogProto = `syntax = "proto3";
+
+package catalog.v1;
+
+import "common/v1/common.proto";
+import "google/api/annotations.proto";
+import "google/protobuf/empty.proto";
+import "google/protobuf/field_mask.proto";
+import "registry/annotations.proto";
+
+option go_package = "example.com/e2e/catalogpb";
+
+service CatalogService {
+  option (registry.grpc_web_origins) = "https://shop.example.com";
+
+  rpc GetProduct(common.v1.GetRequest) returns (common.v1.Product) {
+    option (google.api.http) = { get: "/v1/products/{id}" };
+    option (registry.rate_limit) = { qps: 100 burst: 20 };
+    option (registry.grpc_web) = true;
+  }
+  rpc ListProducts(ListProductsRequest) returns (ListProductsResponse) {
+    option (google.api.http) = { get: "/v1/products" };
+  }
+  rpc UpdateProduct(UpdateProductRequest) returns (common.v1.Product) {
+    option (google.api.http) = { patch: "/v1/products/{product.id}" body: "product" };
+  }
+  rpc Ping(google.protobuf.Empty) returns (google.protobuf.Empty);
+  rpc WatchProducts(common.v1.GetRequest) returns (stream common.v1.Product);
+}
+
+message ListProductsRequest { int32 page_size = 1; string page_token = 2; }
+message ListProductsResponse { repeated common.v1.Product products = 1; string next_page_token = 2; }
+message UpdateProductRequest { common.v1.Product product = 1; google.protobuf.FieldMask update_mask = 2; }
+`
+
+// e2eTwirpProto Twirp 服务，Twirp 不支持流式方法
+const e2eTwirpProto = `syntax = "proto3";
+
+package pricing.v1;
+
+import "common/v1/common.proto";
+import "registry/annotations.proto";
+
+option go_package = "example.com/e2e/pricingpb";
+
+service PricingService {
+  option (registry.twirp) = true;
+
+  rpc GetPrice(common.v1.GetRequest) returns (common.v1.Product);
+}
+`
+
+// TestGenerateBuilds 端到端测试：编译 proto，用 protoc-gen-go、protoc-gen-go-grpc（部分用例还有 protoc-gen-twirp）与本插件生成代码，
+// 并在临时模块中执行 go build
+func TestGenerateBuilds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("-short 时跳过需要 go build 的端到端测试")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("未找到 go 命令")
+	}
+	grpcPlugin, err := exec.LookPath("protoc-gen-go-grpc")
+	if err != nil {
+		t.Skip("未找到 protoc-gen-go-grpc，跳过端到端测试")
+	}
+
+	catalog := map[string]string{"common/v1/common.proto": e2eCommonProto, "catalog/v1/catalog.proto": e2eCatalogProto}
+	backend := "template=registry_backend,aggregate_template=registry_lifecycle+"
+	tests := []struct {
+		name    string
+		sources map[string]string
+		param   string
+		want    string   // 生成的文件中须包含的内容
+		plugins []string // 还需运行的其他插件，未安装时跳过
+	}{
+		{
+			name:    "registry_backend",
+			sources: map[string]string{"shop/v1/shop.proto": e2eProto},
+			param:   "template=registry_backend,aggregate_template=registry_lifecycle+consul_registry+http_routes+audit_events+tenant_context+conn_manager",
+			want:    `mux.HandleFunc("GET /v1/items/{$}"`,
+		},
+		{
+			name:    "audit_events",
+			sources: map[string]string{"common/v1/common.proto": e2eCommonProto, "ledger/v1/ledger.proto": e2eAuditProto},
+			param:   "template=registry_backend,aggregate_template=registry_lifecycle+consul_registry+audit_events",
+			want:    `commonv1 "example.com/e2e/commonpb"`,
+		},
+		{
+			name:    "tenant_context",
+			sources: map[string]string{"common/v1/common.proto": e2eCommonProto, "quota/v1/quota.proto": e2eTenantProto},
+			param:   "template=registry_backend,aggregate_template=registry_lifecycle+consul_registry+tenant_context",
+			want:    `func QuotaGetQuotaTenant(ctx context.Context, req *commonv1.GetRequest) string`,
+		},
+		{
+			name:    "同名 Go 包",
+			sources: map[string]string{"user/v1/user.proto": e2eUserProto, "order/v1/order.proto": e2eOrderProto},
+			param:   "template=registry_backend,aggregate_template=registry_lifecycle+consul_registry+http_routes+conn_manager",
+			want:    `orderv1 "example.com/e2e/order/v1"`,
+		},
+		{
+			name:    "同名 Go 包的请求与响应",
+			sources: map[string]string{"user/v1/user.proto": e2eUserProto, "order/v1/order.proto": e2eOrderProto},
+			param:   backend + "consul_registry+method_routes+logging+graphql_resolvers",
+		},
+		{name: "etcd_registry", sources: catalog, param: backend + "etcd_registry"},
+		{name: "zookeeper_registry", sources: catalog, param: backend + "zookeeper_registry"},
+		{name: "nacos_registry", sources: catalog, param: backend + "nacos_registry"},
+		{name: "multi_registry", sources: catalog, param: backend + "consul_registry+etcd_registry+multi_registry"},
+		{
+			name:    "twirp",
+			sources: map[string]string{"common/v1/common.proto": e2eCommonProto, "pricing/v1/pricing.proto": e2eTwirpProto},
+			param:   backend + "consul_registry+twirp",
+			plugins: []string{"protoc-gen-twirp"},
+		},
+		{name: "grpc_web", sources: catalog, param: backend + "consul_registry+grpc_web"},
+		{name: "rate_limit", sources: catalog, param: backend + "consul_registry+rate_limit"},
+		{name: "graphql_resolvers", sources: catalog, param: backend + "consul_registry+graphql_resolvers"},
+		{name: "logging", sources: catalog, param: backend + "consul_registry+logging", want: `"common.v1.Product": {"cost": true}`},
+		{name: "method_routes", sources: catalog, param: backend + "consul_registry+method_routes"},
+		{name: "conn_manager", sources: catalog, param: backend + "consul_registry+conn_manager", want: `*commonv1.Product`},
+		{name: "http_routes", sources: catalog, param: backend + "consul_registry+http_routes", want: `new(commonv1.GetRequest)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugins := []string{grpcPlugin}
+			for _, name := range tt.plugins {
+				path, err := exec.LookPath(name)
+				if err != nil {
+					t.Skipf("未找到 %s", name)
+				}
+				plugins = append(plugins, path)
+			}
+			t.Parallel()
+			testBuild(t, goTool, plugins, tt.sources, tt.param, tt.want)
+		})
+	}
+}
+
+// testBuild 为 sources 生成 pb 代码、运行 plugins 中的插件（如 protoc-gen-go-grpc）与本插件，
+// 在临时模块中执行 go build 与 go vet
+func testBuild(t *testing.T, goTool string, plugins []string, sources map[string]string, param, want string) {
+	out := t.TempDir()
+	write := func(files []*pluginpb.CodeGeneratorResponse_File) {
+		t.Helper()
+		for _, f := range files {
+			path := filepath.Join(out, filepath.FromSlash(f.GetName()))
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(f.GetContent()), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// protoc-gen-go
+	gen := testPlugin(t, sources, "module=example.com/e2e")
+	for _, f := range gen.Files {
+		if f.Generate {
+			internal_gengo.GenerateFile(gen, f)
+		}
+	}
+	resp := gen.Response()
+	if resp.Error != nil {
+		t.Fatalf("protoc-gen-go 失败: %s", resp.GetError())
+	}
+	write(resp.File)
+
+	// protoc-gen-go-grpc 等外部插件，与 protoc 一样逐个文件调用，protoc-gen-twirp 要求同一次调用中的文件属于同一个 Go 包
+	req := testRequest(t, sources, "module=example.com/e2e")
+	for _, file := range slices.Sorted(maps.Keys(sources)) {
+		req.FileToGenerate = []string{file}
+		raw, err := proto.Marshal(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, plugin := range plugins {
+			name := filepath.Base(plugin)
+			var stderr strings.Builder
+			cmd := exec.Command(plugin)
+			cmd.Stdin = strings.NewReader(string(raw))
+			cmd.Stderr = &stderr
+			output, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("%s 失败: %v\n%s", name, err, stderr.String())
+			}
+			resp = &pluginpb.CodeGeneratorResponse{}
+			if err := proto.Unmarshal(output, resp); err != nil || resp.Error != nil {
+				t.Fatalf("%s 失败: %v %s", name, err, resp.GetError())
+			}
+			write(resp.File)
+		}
+	}
+
+	// 本插件
+	config, err := ParsePluginOptions(param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen = testPlugin(t, sources, param)
+	if err := g.Generate(gen); err != nil {
+		t.Fatalf("生成失败: %v", err)
+	}
+	resp = g.Response(gen)
+	if resp.Error != nil {
+		t.Fatalf("生成失败: %s", resp.GetError())
+	}
+	write(resp.File)
+	if want != "" && !slices.ContainsFunc(resp.File, func(f *pluginpb.CodeGeneratorResponse_File) bool {
+		return strings.Contains(f.GetContent(), want)
+	}) {
+		t.Errorf("生成的文件中缺少 %s", want)
+	}
+
+	// 临时模块沿用本模块的依赖版本，生成的 pb 代码引用的 registry 包指向本仓库，
+	// 其余缺少的依赖（如 grpc）由 go mod tidy 补充
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	goMod, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	goSum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	module := "github.com/lhdbsbz/protoc-gen-service-registry"
+	mod := strings.Replace(string(goMod), "module "+module, "module example.com/e2e", 1) +
+		"\nrequire " + module + " v0.0.0\n\nreplace " + module + " => " + root + "\n"
+	if err := os.WriteFile(filepath.Join(out, "go.mod"), []byte(mod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(out, "go.sum"), goSum, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// 优先从本地模块缓存解析缺少的依赖，依赖都已缓存时无需访问网络
+	env := os.Environ()
+	if output, err := exec.Command(goTool, "env", "GOMODCACHE", "GOPROXY").Output(); err == nil {
+		if modCache, proxy, ok := strings.Cut(strings.TrimSpace(string(output)), "\n"); ok {
+			env = append(env, "GOPROXY=file://"+filepath.ToSlash(filepath.Join(modCache, "cache", "download"))+","+proxy)
+		}
+	}
+	for _, args := range [][]string{{"mod", "tidy"}, {"build", "./..."}, {"vet", "./..."}} {
+		cmd := exec.Command(goTool, args...)
+		cmd.Dir = out
+		cmd.Env = env
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("go %s 失败: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+}
@@ -0,0 +1,548 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"github.com/lhdbsbz/protoc-gen-service-registry/registry"
+)
+
+// Generator 持有解析好的模板，负责把服务信息渲染为代码
+type Generator struct {
+	config       *PluginConfig
+	tmpl         *template.Template
+	overrides    map[string]*template.Template // 通过 (registry.template) 指定的内置模板
+	extras       []*template.Template          // service_template 指定的额外服务级模板
+	docs         []*docTemplate                // docs_dir 非空时生成的服务文档
+	aggregates   []*aggregateTemplate          // 汇总模板
+	packageIndex *template.Template            // package_index 为 true 时按 proto 包汇总的模板
+	source       string                        // 服务模板来源，内置模板名、模板文件路径或 template_inline
+	log          *slog.Logger                  // 结构化日志，输出到 stderr
+	hooks        []PostHook                    // 库模式下的生成后钩子
+	validators   []Validator                   // 库模式下的生成前校验
+	failed       GenerateErrors                // fail_fast=false 时收集的错误，由 Response 写入响应
+	warnings     []string                      // 已记录的警告，warnings=error 时导致生成失败
+	passes       []*Generator                  // 配置文件中 passes 声明的渲染阶段
+	parent       *Generator                    // 作为渲染阶段时所属的生成器，警告记录到其中
+	templates    []Digest                      // 实际使用的模板内容摘要，写入 attestation
+
+	insertions []*pluginpb.CodeGeneratorResponse_File // 待注入插入点的代码片段
+	outputs    []PlannedFile                          // 已生成（dry_run 时为计划生成）的文件
+}
+
+// New 根据插件配置加载并解析模板
+func New(config *PluginConfig) (*Generator, error) {
+	// 自定义模板函数须在解析模板前注册
+	if err := loadFuncsPlugins(config.FuncsPlugins); err != nil {
+		return nil, err
+	}
+	setDataDir(config.DataDir)
+	if !config.AllowCommands {
+		if len(config.Formatters) > 0 {
+			return nil, fmt.Errorf("formatter 通过 shell 执行外部命令，只能在 generate、--check、--list_outputs 子命令中使用；protoc 或 buf 调用插件时请在生成后自行格式化")
+		}
+		if len(config.PostHooks) > 0 {
+			return nil, fmt.Errorf("post_hook 通过 shell 执行外部命令，只能在 generate 子命令中使用；protoc 或 buf 调用插件时由其写出文件，请在生成后自行执行")
+		}
+	}
+
+	// 加载模板
+	tmplContent, err := LoadTemplate(config)
+	if err != nil {
+		return nil, fmt.Errorf("加载模板失败: %v", err)
+	}
+
+	// 解析模板，template=none 时不使用服务模板
+	var tmpl *template.Template
+	if templateSource(config) != NoTemplate {
+		tmpl, err = newTemplate("service_registry").Parse(tmplContent)
+		if err != nil {
+			return nil, fmt.Errorf("解析模板失败: %v", err)
+		}
+	}
+
+	g := &Generator{
+		config:    config,
+		tmpl:      tmpl,
+		overrides: map[string]*template.Template{},
+		source:    templateSource(config),
+		log:       slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: config.LogLevel})),
+	}
+	if tmpl != nil {
+		g.recordTemplate(g.source, tmplContent)
+	}
+
+	for _, name := range config.ServiceTemplates {
+		content, err := LoadBuiltinTemplate(name)
+		if err != nil {
+			return nil, fmt.Errorf("加载服务级模板失败: %v", err)
+		}
+		extra, err := newTemplate(name).Parse(content)
+		if err != nil {
+			return nil, fmt.Errorf("解析模板 %s 失败: %v", name, err)
+		}
+		g.extras = append(g.extras, extra)
+		g.recordTemplate(name, content)
+	}
+
+	if err := g.loadDocs(); err != nil {
+		return nil, err
+	}
+	if err := g.loadAggregates(); err != nil {
+		return nil, err
+	}
+	if config.PackageIndex {
+		content, err := LoadBuiltinTemplate(packageIndexTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("加载包索引模板失败: %v", err)
+		}
+		if g.packageIndex, err = newTemplate(packageIndexTemplate).Parse(content); err != nil {
+			return nil, fmt.Errorf("解析包索引模板失败: %v", err)
+		}
+		g.recordTemplate(packageIndexTemplate, content)
+	}
+
+	if g.tmpl != nil {
+		g.warnUnusedVariables(g.tmpl, g.source)
+		if g.source != config.Template {
+			g.warnDeprecatedFields(g.tmpl, g.source)
+		}
+	}
+	for _, extra := range g.extras {
+		g.warnUnusedVariables(extra, extra.Name())
+	}
+	for _, a := range g.aggregates {
+		g.warnUnusedVariables(a.tmpl, a.tmpl.Name())
+	}
+	if err := g.loadPasses(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Config 返回生成器使用的插件配置
+func (g *Generator) Config() *PluginConfig {
+	return g.config
+}
+
+// Generate 为插件请求中所有需要生成的服务输出注册文件，配置了汇总模板时再输出汇总文件
+func (g *Generator) Generate(gen *protogen.Plugin) error {
+	type serviceEntry struct {
+		file    *protogen.File
+		service *protogen.Service
+	}
+
+	if err := checkNaming(gen.Files, g.config.NamingRules); err != nil {
+		return err
+	}
+
+	var entries []serviceEntry
+	var services []*ServiceInfo
+	for _, f := range gen.Files {
+		if !f.Generate {
+			continue
+		}
+
+		// 查找服务定义
+		for _, service := range f.Services {
+			if serviceOption[bool](service, registry.E_Skip) {
+				g.log.Info("跳过服务", "service", service.Desc.FullName(), "reason", "(registry.skip) = true")
+				continue
+			}
+			g.log.Debug("处理服务", "service", service.Desc.FullName(), "file", f.Desc.Path())
+			entries = append(entries, serviceEntry{file: f, service: service})
+			services = append(services, NewServiceInfo(f, service, g.config))
+		}
+	}
+	if keep := g.resolveGRPCStubs(services); len(keep) < len(services) {
+		kept := make([]serviceEntry, len(keep))
+		keptServices := make([]*ServiceInfo, len(keep))
+		for i, j := range keep {
+			kept[i], keptServices[i] = entries[j], services[j]
+		}
+		entries, services = kept, keptServices
+	}
+
+	// fail_fast=false 时收集各服务的错误，其余服务照常生成
+	var errs GenerateErrors
+	failed := make([]bool, len(services))
+	fail := func(i int, err error) error {
+		if g.config.FailFast {
+			return err
+		}
+		errs = append(errs, err)
+		if i >= 0 {
+			failed[i] = true
+		}
+		return nil
+	}
+
+	// 同一服务存在多个版本时，调整标识符避免冲突
+	resolveVersions(services)
+	resolvePackageNames(gen, services)
+	if err := resolveClientDependencies(services); err != nil {
+		if err := fail(-1, err); err != nil {
+			return err
+		}
+	}
+	if err := resolveAllowedCallers(services); err != nil {
+		if err := fail(-1, err); err != nil {
+			return err
+		}
+	}
+	// http_routes 汇总模板将所有服务的路由挂载到同一个 ServeMux，路由在服务之间也不能重复或冲突
+	var mounted []*ServiceInfo
+	for i, s := range services {
+		g.warnService(s)
+		g.dropUnsupportedHTTP(s)
+		if err := checkHTTPRoutes(s, mounted); err != nil {
+			if err := fail(i, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := checkTwirp(s); err != nil {
+			if err := fail(i, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := g.checkMiddleware(s); err != nil {
+			if err := fail(i, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := g.checkAudit(s); err != nil {
+			if err := fail(i, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := g.checkTenantContext(s); err != nil {
+			if err := fail(i, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := g.checkFeatureFlags(s); err != nil {
+			if err := fail(i, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := checkMethods(s); err != nil {
+			if err := fail(i, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := resolveSPIFFEID(s); err != nil {
+			if err := fail(i, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := checkSchemaLabels(s); err != nil {
+			if err := fail(i, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if g.usesAggregateTemplate(httpRoutesTemplate) {
+			mounted = append(mounted, s)
+		}
+		g.log.Debug("计算服务名称", "service", s.FullName, "go_name", s.GoName,
+			"registered_name", s.RegisteredName, "group", s.Group, "namespace", s.Namespace, "version", s.Version)
+	}
+	if err := g.runValidators(services); err != nil {
+		return err
+	}
+
+	for i, entry := range entries {
+		if failed[i] {
+			continue
+		}
+		// 生成服务注册文件
+		if err := g.generateServiceRegistry(gen, entry.file, entry.service, services[i]); err != nil {
+			if err := fail(i, withService(services[i], err)); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 生成失败的服务不计入汇总文件
+	generated := make([]*ServiceInfo, 0, len(services))
+	byFile := map[*protogen.File][]*ServiceInfo{}
+	for i, s := range services {
+		if !failed[i] {
+			generated = append(generated, s)
+			byFile[entries[i].file] = append(byFile[entries[i].file], s)
+		}
+	}
+
+	var files []*FileInfo
+	for _, f := range gen.Files {
+		if f.Generate {
+			files = append(files, NewFileInfo(f, byFile[f], g.config))
+		}
+	}
+	if g.tmpl != nil && g.config.Scope == scopeFile {
+		for _, f := range files {
+			if err := g.generateFile(gen, f); err != nil {
+				if err := fail(-1, err); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// 无论是否生成汇总文件，都校验服务依赖关系
+	aggregate, err := NewAggregateInfo(generated, g.config)
+	if err != nil {
+		if err := fail(-1, err); err != nil {
+			return err
+		}
+	} else {
+		aggregate.Files = files
+		aggregate.Generated = g.generatedFiles()
+		if g.tmpl != nil && g.config.Scope == scopeAll {
+			if err := g.generateAll(gen, aggregate); err != nil {
+				if err := fail(-1, err); err != nil {
+					return err
+				}
+			}
+		}
+		for _, a := range g.aggregates {
+			if err := g.generateAggregate(gen, a, aggregate); err != nil {
+				if err := fail(-1, err); err != nil {
+					return err
+				}
+			}
+		}
+		if err := g.generatePackageIndexes(gen, aggregate); err != nil {
+			if err := fail(-1, err); err != nil {
+				return err
+			}
+		}
+		if err := g.writeDumpData(gen, aggregate); err != nil {
+			return err
+		}
+	}
+	if err := g.runPasses(gen); err != nil {
+		var passErrs GenerateErrors
+		if g.config.FailFast || !errors.As(err, &passErrs) {
+			return err
+		}
+		errs = append(errs, passErrs...)
+	}
+	if g.parent == nil {
+		if err := g.compactOutputs(gen); err != nil {
+			return err
+		}
+	}
+	if err := g.generateGoMod(gen); err != nil {
+		return err
+	}
+	if err := g.checkWarnings(); err != nil {
+		return err
+	}
+	if err := g.writeManifest(gen); err != nil {
+		return err
+	}
+	if err := g.writeReport(gen); err != nil {
+		return err
+	}
+	if err := g.writeAttestation(gen); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		g.failed = errs
+		return errs
+	}
+	return nil
+}
+
+// withService 为未注明服务的错误（如模板执行错误）补充服务全限定名
+func withService(s *ServiceInfo, err error) error {
+	if strings.Contains(err.Error(), s.FullName) {
+		return err
+	}
+	return fmt.Errorf("服务 %s: %v", s.FullName, err)
+}
+
+// GenerateErrors fail_fast=false 时收集的全部错误，对应的服务不会生成文件
+type GenerateErrors []error
+
+func (e GenerateErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("生成失败，共 %d 个错误:\n  %s", len(e), strings.Join(msgs, "\n  "))
+}
+
+// Unwrap 返回收集到的各个错误，供 errors.Is 与 errors.As 使用
+func (e GenerateErrors) Unwrap() []error {
+	return e
+}
+
+// Render 执行模板并按输出文件扩展名格式化生成的代码
+func (g *Generator) Render(data *ServiceInfo) ([]byte, error) {
+	if g.tmpl == nil {
+		return nil, fmt.Errorf("未配置服务模板（template=%s）", NoTemplate)
+	}
+	raw, err := execute(g.tmpl, data)
+	if err != nil {
+		return nil, err
+	}
+	if raw, err = unqualifySelfImport(g.OutputPath(data), raw, data); err != nil {
+		return nil, err
+	}
+	return g.format(g.OutputPath(data), raw)
+}
+
+// templateFor 返回服务使用的模板，(registry.template) 可为单个服务指定内置模板
+func (g *Generator) templateFor(service *protogen.Service) (*template.Template, error) {
+	name := serviceOption[string](service, registry.E_Template)
+	if name == "" {
+		return g.tmpl, nil
+	}
+	if tmpl, ok := g.overrides[name]; ok {
+		return tmpl, nil
+	}
+
+	tmplContent, err := LoadBuiltinTemplate(name)
+	if err != nil {
+		return nil, fmt.Errorf("服务 %s 加载模板失败: %v", service.Desc.FullName(), err)
+	}
+	tmpl, err := newTemplate(name).Parse(tmplContent)
+	if err != nil {
+		return nil, fmt.Errorf("解析模板 %s 失败: %v", name, err)
+	}
+	g.overrides[name] = tmpl
+	g.recordTemplate(name, tmplContent)
+	g.warnUnusedVariables(tmpl, name)
+	return tmpl, nil
+}
+
+// execute 执行模板，返回未格式化的内容
+func execute(tmpl *template.Template, data any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("执行模板失败: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// formatSource 格式化生成的代码
+func formatSource(raw []byte) ([]byte, error) {
+	formatted, err := format.Source(raw)
+	if err != nil {
+		return nil, fmt.Errorf("格式化代码失败: %v", err)
+	}
+	return formatted, nil
+}
+
+// OutputPath 返回服务对应的输出文件路径（文件名为 GoName 的小驼峰格式，扩展名由 output_ext 决定）
+func (g *Generator) OutputPath(data *ServiceInfo) string {
+	ext := g.config.OutputExt
+	if ext == "" {
+		ext = ".go"
+	}
+	fileName := toCamelCase(data.GoName) + ext
+	return filepath.Join(g.outputDir(data), fileName)
+}
+
+func (g *Generator) generateServiceRegistry(gen *protogen.Plugin, file *protogen.File, service *protogen.Service, data *ServiceInfo) error {
+	tmpl, err := g.templateFor(service)
+	if err != nil {
+		return err
+	}
+
+	// template=none 且服务未通过 (registry.template) 指定模板时，只输出服务级模板与文档；
+	// scope 不为 service 时主模板另行渲染
+	if tmpl != nil && (tmpl != g.tmpl || g.config.Scope == scopeService) {
+		g.log.Debug("选择模板", "service", data.FullName, "template", g.sourceOf(tmpl))
+
+		if err := g.renderInsertions(tmpl, g.sourceOf(tmpl), file, data); err != nil {
+			return err
+		}
+
+		if err := g.generateEnabledGuard(gen, data); err != nil {
+			return err
+		}
+
+		if err := g.generateRegistrars(gen, data); err != nil {
+			return err
+		}
+
+		if err := g.renderService(gen, tmpl, g.OutputPath(data), data); err != nil {
+			return err
+		}
+	}
+	for _, extra := range g.extras {
+		if err := g.renderService(gen, extra, g.extraOutputPath(extra.Name(), data), data); err != nil {
+			return err
+		}
+	}
+	for _, doc := range g.docs {
+		path := filepath.Join(g.config.DocsDir, data.RegisteredName, doc.file)
+		if err := g.renderService(gen, doc.tmpl, path, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderService 渲染服务模板并输出到 path
+func (g *Generator) renderService(gen *protogen.Plugin, tmpl *template.Template, path string, data *ServiceInfo) error {
+	raw, err := execute(tmpl, data)
+	if err != nil {
+		return err
+	}
+	// 模板渲染为空时（如主模板仅包含插入点代码块），不生成独立文件
+	if len(bytes.TrimSpace(raw)) == 0 {
+		g.log.Info("模板渲染结果为空，不生成服务文件", "service", data.FullName, "template", g.sourceOf(tmpl))
+		return nil
+	}
+
+	if raw, err = unqualifySelfImport(path, raw, data); err != nil {
+		return err
+	}
+	formatted, err := g.format(path, raw)
+	if err != nil {
+		return err
+	}
+
+	// 创建输出文件
+	return g.writeFile(gen, path, formatted, data, g.sourceOf(tmpl))
+}
+
+// extraOutputPath 返回额外服务级模板的输出路径：模板名带扩展名时追加在服务文件名之后，
+// 如 deploy.yaml -> user.deploy.yaml，否则输出 Go 文件，如 twirp -> user_twirp.go
+func (g *Generator) extraOutputPath(name string, data *ServiceInfo) string {
+	fileName := toCamelCase(data.GoName) + "." + name
+	if filepath.Ext(name) == "" {
+		fileName = toCamelCase(data.GoName) + "_" + name + ".go"
+	}
+	return filepath.Join(g.outputDir(data), fileName)
+}
+
+// sourceOf 返回模板的来源描述，(registry.template) 指定的模板以内置模板名命名
+func (g *Generator) sourceOf(tmpl *template.Template) string {
+	if tmpl == g.tmpl {
+		return g.source
+	}
+	return tmpl.Name()
+}
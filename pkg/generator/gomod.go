@@ -0,0 +1,151 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// goModGoVersion 生成的 go.mod 中的 go 指令版本，与内置模板使用的 grpc.NewClient 所需的最低版本一致
+const goModGoVersion = "1.22"
+
+// goModTemplate go_mod 输出在生成报告中记录的模板名
+const goModTemplate = "go_mod"
+
+// GoModRequire go.mod 中的一条依赖，来自插件参数 go_mod_require
+type GoModRequire struct {
+	Path    string // 模块路径
+	Version string // 版本号，如 v1.64.0
+}
+
+// String 返回与插件参数相同的 path@version 形式
+func (r GoModRequire) String() string {
+	return r.Path + "@" + r.Version
+}
+
+// parseGoModRequire 解析 go_mod_require 参数，格式为 path@version
+func parseGoModRequire(value string) (GoModRequire, error) {
+	path, version, ok := strings.Cut(value, "@")
+	if !ok || path == "" || !strings.HasPrefix(version, "v") {
+		return GoModRequire{}, fmt.Errorf("go_mod_require 格式错误，应为 模块路径@版本号，如 google.golang.org/grpc@v1.64.0: %s", value)
+	}
+	return GoModRequire{Path: path, Version: version}, nil
+}
+
+// generateGoMod go_mod 非空时在输出目录生成 go.mod 与 doc.go，使生成的代码成为独立模块
+func (g *Generator) generateGoMod(gen *protogen.Plugin) error {
+	if g.config.GoMod == "" {
+		return nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// 由 protoc-gen-service-registry 生成；generate 子命令重新生成时保留其他依赖与 replace 等指令\n\n")
+	fmt.Fprintf(&b, "module %s\n\ngo %s\n", g.config.GoMod, goModGoVersion)
+	if len(g.config.GoModRequires) > 0 {
+		requires := slices.Clone(g.config.GoModRequires)
+		slices.SortFunc(requires, func(a, b GoModRequire) int { return strings.Compare(a.Path, b.Path) })
+		b.WriteString("\nrequire (\n")
+		for _, r := range requires {
+			fmt.Fprintf(&b, "\t%s %s\n", r.Path, r.Version)
+		}
+		b.WriteString(")\n")
+	}
+	if err := g.writeFile(gen, filepath.Join(g.config.OutputDir, "go.mod"), []byte(b.String()), nil, goModTemplate); err != nil {
+		return err
+	}
+
+	doc := fmt.Sprintf("// Package %s 由 protoc-gen-service-registry 生成的服务注册代码，作为独立模块 %s 供多个服务引用。\n//\n// 请勿手动修改，重新生成会覆盖本目录下的文件。\npackage %s\n",
+		g.config.PackageName, g.config.GoMod, g.config.PackageName)
+	return g.writeFile(gen, filepath.Join(g.config.OutputDir, "doc.go"), []byte(doc), nil, goModTemplate)
+}
+
+// MergeGoMod 用生成的 go.mod 更新已有的 go.mod：module、go 指令与生成的依赖以 generated 为准，
+// 已有文件中的其他依赖（如 go mod tidy 补充的）以及 replace、exclude、toolchain 等指令保留
+//
+// generate 子命令写出 go.mod 时使用；protoc 调用时由 protoc 直接覆盖文件。
+func MergeGoMod(existing, generated []byte) []byte {
+	gen := parseGoMod(string(generated))
+	old := parseGoMod(string(existing))
+
+	requires := map[string]string{}
+	for path, version := range old.requires {
+		requires[path] = version
+	}
+	for path, version := range gen.requires {
+		requires[path] = version
+	}
+
+	var b strings.Builder
+	b.WriteString(gen.header)
+	if len(requires) > 0 {
+		paths := make([]string, 0, len(requires))
+		for path := range requires {
+			paths = append(paths, path)
+		}
+		slices.Sort(paths)
+		b.WriteString("\nrequire (\n")
+		for _, path := range paths {
+			fmt.Fprintf(&b, "\t%s %s\n", path, requires[path])
+		}
+		b.WriteString(")\n")
+	}
+	for _, directive := range old.others {
+		b.WriteString("\n" + directive)
+	}
+	return []byte(b.String())
+}
+
+// goModFile 按指令拆分的 go.mod
+type goModFile struct {
+	header   string            // module 与 go 指令及其之前的注释
+	requires map[string]string // 依赖的模块路径与版本，含 // indirect 注释
+	others   []string          // 其他指令，按原文保留
+}
+
+// parseGoMod 按行解析 go.mod，只区分 module/go、require 与其他指令，不做完整的语法检查
+func parseGoMod(content string) goModFile {
+	f := goModFile{requires: map[string]string{}}
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		verb, rest, _ := strings.Cut(line, " ")
+		rest = strings.TrimSpace(rest)
+		block := rest == "("
+
+		// 收集单行指令或整个块
+		var body []string
+		raw := lines[i] + "\n"
+		if block {
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != ")"; i++ {
+				body = append(body, strings.TrimSpace(lines[i]))
+				raw += lines[i] + "\n"
+			}
+			raw += ")\n"
+		} else {
+			body = []string{rest}
+		}
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "//"):
+			if len(f.requires) == 0 && len(f.others) == 0 {
+				f.header += lines[i] + "\n"
+			}
+		case verb == "module" || verb == "go":
+			f.header += raw
+		case verb == "require":
+			for _, r := range body {
+				path, version, ok := strings.Cut(r, " ")
+				if ok && !strings.HasPrefix(path, "//") {
+					f.requires[path] = strings.TrimSpace(version)
+				}
+			}
+		default:
+			f.others = append(f.others, raw)
+		}
+	}
+	f.header = strings.TrimRight(f.header, "\n") + "\n"
+	return f
+}
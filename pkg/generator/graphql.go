@@ -0,0 +1,75 @@
+package generator
+
+import "strings"
+
+// graphqlQueryPrefixes 方法名以这些前缀开头时映射为 GraphQL Query，其余映射为 Mutation
+var graphqlQueryPrefixes = []string{"Get", "List", "Search", "Find", "Query", "Lookup", "Count", "Check", "BatchGet"}
+
+// graphqlWellKnown well-known types 对应的 GraphQL 标量，与 protojson 的编码方式一致
+var graphqlWellKnown = map[string]string{
+	"google.protobuf.Timestamp":   "String",
+	"google.protobuf.Duration":    "String",
+	"google.protobuf.FieldMask":   "String",
+	"google.protobuf.StringValue": "String",
+	"google.protobuf.BytesValue":  "String",
+	"google.protobuf.Int32Value":  "Int",
+	"google.protobuf.UInt32Value": "Float",
+	"google.protobuf.Int64Value":  "String",
+	"google.protobuf.UInt64Value": "String",
+	"google.protobuf.FloatValue":  "Float",
+	"google.protobuf.DoubleValue": "Float",
+	"google.protobuf.BoolValue":   "Boolean",
+	"google.protobuf.Struct":      "JSON",
+	"google.protobuf.Value":       "JSON",
+	"google.protobuf.ListValue":   "JSON",
+	"google.protobuf.Any":         "JSON",
+	"google.protobuf.Empty":       "JSON",
+}
+
+// graphqlScalar 返回 well-known 消息对应的 GraphQL 标量，其他消息返回空字符串
+func graphqlScalar(fullName string) string {
+	return graphqlWellKnown[fullName]
+}
+
+// graphqlOperation 方法对应的 GraphQL 操作类型，Query 或 Mutation
+func graphqlOperation(m *MethodInfo) string {
+	for _, prefix := range graphqlQueryPrefixes {
+		if strings.HasPrefix(m.Name, prefix) {
+			return "Query"
+		}
+	}
+	return "Mutation"
+}
+
+// graphqlType 返回字段的 GraphQL 类型，input 为 true 时消息类型使用对应的 Input 类型
+func graphqlType(f *FieldInfo, input bool) string {
+	var t string
+	switch f.Kind {
+	case "message":
+		t = graphqlScalar(f.TypeFull)
+		if t == "" {
+			t = f.TypeName
+			if input {
+				t += "Input"
+			}
+		}
+	case "enum":
+		t = f.TypeName
+	case "bool":
+		t = "Boolean"
+	case "string", "bytes":
+		t = "String"
+	case "float", "double":
+		t = "Float"
+	case "int32", "sint32", "sfixed32":
+		t = "Int"
+	case "uint32", "fixed32":
+		t = "Float" // 超出 GraphQL Int 的 32 位有符号范围
+	default:
+		t = "String" // 64 位整数与 protojson 一致编码为字符串
+	}
+	if f.Repeated {
+		return "[" + t + "!]"
+	}
+	return t
+}
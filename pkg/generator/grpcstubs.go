@@ -0,0 +1,146 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 是否存在 protoc-gen-go-grpc 生成的代码，来自插件参数 grpc_stubs
+const (
+	grpcStubsAssume = "assume" // 假定所有服务都生成了 gRPC 代码
+	grpcStubsDetect = "detect" // 在当前目录所在的 Go 模块中查找已生成的 gRPC 代码
+	grpcStubsNone   = "none"   // 没有生成 gRPC 代码
+)
+
+// 缺少 gRPC 代码的服务的处理方式，来自插件参数 grpc_stubs_missing
+const (
+	grpcStubsMissingSkip   = "skip"   // 跳过服务并警告
+	grpcStubsMissingRender = "render" // 照常渲染，由模板按 HasGRPCStubs 分支
+)
+
+// grpcStubDetector 在当前目录所在的 Go 模块中查找 protoc-gen-go-grpc 生成的注册函数
+//
+// 插件之间相互独立，本次 protoc 调用中其他插件的输出在插件结束后才写出，
+// 因此只能检测到此前已生成（如已提交或由前一个生成步骤输出）的代码。
+type grpcStubDetector struct {
+	module string              // 模块路径，未找到 go.mod 时为空
+	root   string              // 模块根目录
+	funcs  map[string][]string // 已扫描的目录中声明的函数名，键为目录
+}
+
+// newGRPCStubDetector 从当前目录向上查找 go.mod
+func newGRPCStubDetector() *grpcStubDetector {
+	d := &grpcStubDetector{funcs: map[string][]string{}}
+	dir, err := os.Getwd()
+	if err != nil {
+		return d
+	}
+	for {
+		if module := goModModule(filepath.Join(dir, "go.mod")); module != "" {
+			d.module, d.root = module, dir
+			return d
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return d
+		}
+		dir = parent
+	}
+}
+
+// goModModule 返回 go.mod 中的模块路径，文件不存在或没有 module 指令时为空
+func goModModule(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if module, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.Trim(strings.TrimSpace(module), `"`)
+		}
+	}
+	return ""
+}
+
+// hasStubs 返回导入路径为 importPath 的包中是否声明了 registerFunc；
+// 包不在当前模块中、无法定位时 known 为 false
+func (d *grpcStubDetector) hasStubs(importPath, registerFunc string) (found, known bool) {
+	if d.module == "" {
+		return false, false
+	}
+	rel, ok := strings.CutPrefix(importPath, d.module)
+	if !ok || (rel != "" && !strings.HasPrefix(rel, "/")) {
+		return false, false
+	}
+	dir := filepath.Join(d.root, filepath.FromSlash(strings.TrimPrefix(rel, "/")))
+	funcs, ok := d.funcs[dir]
+	if !ok {
+		funcs = packageFuncs(dir)
+		d.funcs[dir] = funcs
+	}
+	for _, name := range funcs {
+		if name == registerFunc {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// packageFuncs 返回目录中非测试 Go 文件声明的顶层函数名，目录不存在时为空
+func packageFuncs(dir string) []string {
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	var names []string
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+				names = append(names, fn.Name.Name)
+			}
+		}
+	}
+	return names
+}
+
+// resolveGRPCStubs 按 grpc_stubs 设置各服务的 HasGRPCStubs，返回应生成的服务的下标；
+// grpc_stubs_missing=skip 时缺少 gRPC 代码的服务被跳过并警告
+func (g *Generator) resolveGRPCStubs(services []*ServiceInfo) []int {
+	var detector *grpcStubDetector
+	if g.config.GRPCStubs == grpcStubsDetect {
+		detector = newGRPCStubDetector()
+	}
+	var keep []int
+	for i, s := range services {
+		switch g.config.GRPCStubs {
+		case grpcStubsNone:
+			s.HasGRPCStubs = false
+		case grpcStubsDetect:
+			found, known := detector.hasStubs(s.ProtoImportPath, s.RegisterFunc)
+			if !known {
+				g.log.Debug("无法定位服务的 Go 包，假定已生成 gRPC 代码", "service", s.FullName, "import_path", s.ProtoImportPath)
+			}
+			s.HasGRPCStubs = found || !known
+		default:
+			s.HasGRPCStubs = true
+		}
+		if !s.HasGRPCStubs && g.config.GRPCStubsMissing != grpcStubsMissingRender {
+			g.warn(s.pos, "服务没有生成 gRPC 代码，已跳过；如需照常渲染请指定 grpc_stubs_missing=render",
+				"service", s.FullName, "register_func", s.ProtoPackageName+"."+s.RegisterFunc)
+			continue
+		}
+		keep = append(keep, i)
+	}
+	return keep
+}
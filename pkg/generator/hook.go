@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// PostHook 库模式下的生成后钩子，在 Response 返回前执行
+//
+// 钩子可以检查或改写响应中的文件内容，例如运行 goimports、检查许可证头；
+// 返回错误时响应会携带该错误，protoc 不会写出任何文件。
+type PostHook interface {
+	PostGenerate(resp *pluginpb.CodeGeneratorResponse) error
+}
+
+// PostHookFunc 将普通函数适配为 PostHook
+type PostHookFunc func(resp *pluginpb.CodeGeneratorResponse) error
+
+// PostGenerate 调用 f(resp)
+func (f PostHookFunc) PostGenerate(resp *pluginpb.CodeGeneratorResponse) error {
+	return f(resp)
+}
+
+// AddPostHook 注册生成后钩子，按注册顺序执行
+func (g *Generator) AddPostHook(hook PostHook) {
+	g.hooks = append(g.hooks, hook)
+}
+
+// runPostHooks 依次执行生成后钩子，遇到错误立即停止
+func (g *Generator) runPostHooks(resp *pluginpb.CodeGeneratorResponse) error {
+	for i, hook := range g.hooks {
+		if err := hook.PostGenerate(resp); err != nil {
+			return fmt.Errorf("第 %d 个生成后钩子执行失败: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Validator 库模式下的生成前校验，在渲染任何文件前检查全部服务
+//
+// 可用于落实团队约定，例如每个服务必须声明负责人、每个方法必须声明 HTTP 路由。
+// 返回的错误可以用 errors.Join 合并多条违规，所有校验器的违规会汇总后一并报告。
+type Validator interface {
+	Validate(services []*ServiceInfo) error
+}
+
+// ValidatorFunc 将普通函数适配为 Validator
+type ValidatorFunc func(services []*ServiceInfo) error
+
+// Validate 调用 f(services)
+func (f ValidatorFunc) Validate(services []*ServiceInfo) error {
+	return f(services)
+}
+
+// AddValidator 注册生成前校验，按注册顺序执行
+func (g *Generator) AddValidator(v Validator) {
+	g.validators = append(g.validators, v)
+}
+
+// runValidators 执行全部生成前校验，汇总所有违规后返回
+func (g *Generator) runValidators(services []*ServiceInfo) error {
+	var violations []string
+	for _, v := range g.validators {
+		err := v.Validate(services)
+		if err == nil {
+			continue
+		}
+		// errors.Join 合并的错误逐条展开
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range joined.Unwrap() {
+				violations = append(violations, e.Error())
+			}
+			continue
+		}
+		violations = append(violations, err.Error())
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("生成前校验未通过，共 %d 处违规:\n  %s", len(violations), strings.Join(violations, "\n  "))
+}
@@ -0,0 +1,268 @@
+package generator
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// HTTPBinding 由 google.api.http 注解得到的一条 HTTP 路由
+type HTTPBinding struct {
+	Method       string       // HTTP 方法，如 GET、POST
+	Path         string       // 注解中的原始路径模板，如 /v1/{name=shelves/*}
+	Pattern      string       // net/http.ServeMux 路由模式，如 GET /v1/shelves/{p1}
+	Body         string       // 请求体映射到的字段，* 表示整个请求消息，为空表示没有请求体
+	ResponseBody string       // 响应体取自的字段，为空表示整个响应消息
+	PathParams   []*PathParam // 路径参数
+
+	// API 网关（apisix.yaml、kong.yaml 汇总模板）匹配路径使用的规则
+	Prefix string // 第一个变量之前的字面量前缀，如 /v1/shelves/，路径不含变量时为空
+	Regexp string // 匹配整个路径的正则表达式，如 ^/v1/shelves/[^/]+$
+
+	err error // 不支持的路径模板，生成时跳过并输出警告
+}
+
+// PathParam 路径参数与请求消息字段的对应关系
+type PathParam struct {
+	Field string // 请求消息中的字段路径，如 name 或 book.id
+	Value string // 从 *http.Request r 中还原字段值的 Go 表达式
+}
+
+// newHTTPBindings 解析方法上的 google.api.http 注解，包括 additional_bindings
+func newHTTPBindings(method *protogen.Method) []*HTTPBinding {
+	opts, ok := method.Desc.Options().(*descriptorpb.MethodOptions)
+	if !ok || opts == nil || !proto.HasExtension(opts, annotations.E_Http) {
+		return nil
+	}
+	rule := proto.GetExtension(opts, annotations.E_Http).(*annotations.HttpRule)
+
+	var bindings []*HTTPBinding
+	for _, r := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+		b := newHTTPBinding(r)
+		// 同一路由重复注册会使 ServeMux panic，重复的规则按不支持处理
+		if b.err == nil {
+			if i := slices.IndexFunc(bindings, func(o *HTTPBinding) bool { return o.err == nil && o.Pattern == b.Pattern }); i >= 0 {
+				b.err = fmt.Errorf("路由 %s 与第 %d 条 HTTP 规则重复", b.Pattern, i+1)
+			}
+		}
+		bindings = append(bindings, b)
+	}
+	return bindings
+}
+
+// httpRoutesTemplate HTTP 转码汇总模板，所有服务的路由挂载到同一个 ServeMux
+const httpRoutesTemplate = "http_routes"
+
+// checkHTTPRoutes 检查服务的 HTTP 路由之间、以及与 mounted 中服务的路由之间没有重复或冲突，须在 dropUnsupportedHTTP 之后调用
+//
+// mounted 为启用 http_routes 汇总模板时已通过检查的其他服务，它们的路由与 s 挂载到同一个 ServeMux。
+// 相同的路由，或能同时匹配某个请求且没有更具体一方的路由（如 GET /v1/{p1}/items 与 GET /v1/shelves/{p1}），
+// 注册到同一个 ServeMux 时会 panic。
+func checkHTTPRoutes(s *ServiceInfo, mounted []*ServiceInfo) error {
+	type route struct {
+		service *ServiceInfo
+		method  *MethodInfo
+		pattern string
+	}
+	var routes []route
+	for _, o := range mounted {
+		for _, m := range o.Methods {
+			for _, b := range m.HTTP {
+				routes = append(routes, route{o, m, b.Pattern})
+			}
+		}
+	}
+	for _, m := range s.Methods {
+		for _, b := range m.HTTP {
+			for _, r := range routes {
+				other := "方法 " + r.method.Name
+				if r.service != s {
+					other = "服务 " + r.service.FullName + " 的" + other
+				}
+				if r.pattern == b.Pattern {
+					return fmt.Errorf("服务 %s 的方法 %s 与%s 声明了相同的 HTTP 路由 %s", s.FullName, m.Name, other, b.Pattern)
+				}
+				if routesConflict(r.pattern, b.Pattern) {
+					return fmt.Errorf("服务 %s 的方法 %s 的 HTTP 路由 %s 与%s 的路由 %s 冲突：两者能匹配同一请求且没有更具体的一方",
+						s.FullName, m.Name, b.Pattern, other, r.pattern)
+				}
+			}
+			routes = append(routes, route{s, m, b.Pattern})
+		}
+	}
+	return nil
+}
+
+// routesConflict 两个不同的 ServeMux 路由模式能否注册到同一个 ServeMux，冲突时 ServeMux 会 panic
+func routesConflict(a, b string) (conflict bool) {
+	defer func() {
+		if recover() != nil {
+			conflict = true
+		}
+	}()
+	mux := http.NewServeMux()
+	mux.HandleFunc(a, func(http.ResponseWriter, *http.Request) {})
+	mux.HandleFunc(b, func(http.ResponseWriter, *http.Request) {})
+	return false
+}
+
+// newHTTPBinding 转换单条 HttpRule
+func newHTTPBinding(rule *annotations.HttpRule) *HTTPBinding {
+	b := &HTTPBinding{Body: rule.GetBody(), ResponseBody: rule.GetResponseBody()}
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		b.Method, b.Path = "GET", p.Get
+	case *annotations.HttpRule_Put:
+		b.Method, b.Path = "PUT", p.Put
+	case *annotations.HttpRule_Post:
+		b.Method, b.Path = "POST", p.Post
+	case *annotations.HttpRule_Delete:
+		b.Method, b.Path = "DELETE", p.Delete
+	case *annotations.HttpRule_Patch:
+		b.Method, b.Path = "PATCH", p.Patch
+	case *annotations.HttpRule_Custom:
+		b.Method, b.Path = p.Custom.GetKind(), p.Custom.GetPath()
+	default:
+		b.err = fmt.Errorf("未指定 HTTP 方法")
+		return b
+	}
+
+	pattern, params, err := muxPattern(b.Path)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	b.Pattern = b.Method + " " + pattern
+	b.PathParams = params
+	b.Prefix, b.Regexp = gatewayPath(pattern)
+	return b
+}
+
+// gatewayPath 由 ServeMux 路由模式的路径得到 API 网关的前缀与正则表达式，{pN} 匹配一个路径段，{pN...} 匹配剩余路径
+func gatewayPath(pattern string) (prefix, re string) {
+	// {$} 只表示精确匹配结尾的 /，正则表达式本身以 $ 结尾
+	pattern = strings.TrimSuffix(pattern, "{$}")
+	var b strings.Builder
+	rest := pattern
+	for {
+		i := strings.Index(rest, "{")
+		if i < 0 {
+			b.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+		if prefix == "" {
+			prefix = pattern[:len(pattern)-len(rest)+i]
+		}
+		b.WriteString(regexp.QuoteMeta(rest[:i]))
+		end := strings.Index(rest, "}")
+		if strings.HasSuffix(rest[:end], "...") {
+			b.WriteString(".*")
+		} else {
+			b.WriteString("[^/]+")
+		}
+		rest = rest[end+1:]
+	}
+	return prefix, "^" + b.String() + "$"
+}
+
+// muxPattern 将 google.api.http 路径模板转换为 ServeMux 路由模式
+//
+// 变量中的 * 与 ** 分别转换为 {pN} 与 {pN...}，字段值由字面量片段与通配符拼接还原，
+// 例如 /v1/{name=shelves/*} -> /v1/shelves/{p1}，name = "shelves/" + r.PathValue("p1")。
+// ServeMux 的通配符必须占据整个路径段，因此不支持 :verb 后缀。
+// 以 / 结尾的路径模板转换为以 /{$} 结尾，ServeMux 中单独的结尾 / 会匹配其下的所有路径。
+func muxPattern(path string) (string, []*PathParam, error) {
+	if !strings.HasPrefix(path, "/") {
+		return "", nil, fmt.Errorf("路径模板必须以 / 开头: %s", path)
+	}
+	if i := strings.LastIndex(path, "/"); strings.Contains(path[i:], ":") && !strings.HasSuffix(path, "}") {
+		return "", nil, fmt.Errorf("暂不支持自定义动词后缀: %s", path)
+	}
+
+	var segments []string
+	var params []*PathParam
+	wildcard := 0
+	rest := path[1:]
+	for rest != "" {
+		if !strings.HasPrefix(rest, "{") {
+			segment, tail, _ := strings.Cut(rest, "/")
+			if strings.ContainsAny(segment, "{}*") {
+				return "", nil, fmt.Errorf("暂不支持变量之外的通配符: %s", path)
+			}
+			segments = append(segments, segment)
+			rest = tail
+			continue
+		}
+
+		end := strings.Index(rest, "}")
+		if end < 0 {
+			return "", nil, fmt.Errorf("路径模板中的变量缺少 }: %s", path)
+		}
+		field, varPattern, ok := strings.Cut(rest[1:end], "=")
+		if !ok {
+			varPattern = "*"
+		}
+		after := rest[end+1:]
+		rest = strings.TrimPrefix(after, "/")
+
+		// 连续的字面量片段合并为一个字符串常量
+		var parts []string
+		literal := ""
+		for i, seg := range strings.Split(varPattern, "/") {
+			if i > 0 {
+				literal += "/"
+			}
+			if seg != "*" && seg != "**" {
+				segments = append(segments, seg)
+				literal += seg
+				continue
+			}
+			wildcard++
+			name := fmt.Sprintf("p%d", wildcard)
+			if seg == "**" {
+				if after != "" {
+					return "", nil, fmt.Errorf("** 只能出现在路径末尾: %s", path)
+				}
+				name += "..."
+			}
+			segments = append(segments, "{"+name+"}")
+			if literal != "" {
+				parts = append(parts, strconv.Quote(literal))
+				literal = ""
+			}
+			parts = append(parts, fmt.Sprintf("r.PathValue(%q)", strings.TrimSuffix(name, "...")))
+		}
+		if literal != "" {
+			parts = append(parts, strconv.Quote(literal))
+		}
+		params = append(params, &PathParam{Field: field, Value: strings.Join(parts, " + ")})
+	}
+	pattern := "/" + strings.Join(segments, "/")
+	if strings.HasSuffix(path, "/") {
+		pattern = strings.TrimSuffix(pattern, "/") + "/{$}"
+	}
+	return pattern, params, nil
+}
+
+// dropUnsupportedHTTP 移除无法转换为 ServeMux 路由的 HTTP 规则，并输出警告
+func (g *Generator) dropUnsupportedHTTP(s *ServiceInfo) {
+	for _, m := range s.Methods {
+		supported := m.HTTP[:0]
+		for _, b := range m.HTTP {
+			if b.err != nil {
+				g.warn(m.pos, "跳过不支持的 HTTP 规则", "method", m.FullMethod, "path", b.Path, "reason", b.err)
+				continue
+			}
+			supported = append(supported, b)
+		}
+		m.HTTP = supported
+	}
+}
@@ -0,0 +1,210 @@
+package generator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMuxPattern(t *testing.T) {
+	tests := []struct {
+		path    string
+		pattern string
+		params  []*PathParam
+		errMsg  string
+	}{
+		{path: "/v1/ping", pattern: "/v1/ping"},
+		{path: "/v1/items/", pattern: "/v1/items/{$}"},
+		{path: "/", pattern: "/{$}"},
+		{path: "/v1/{name}", pattern: "/v1/{p1}", params: []*PathParam{{Field: "name", Value: `r.PathValue("p1")`}}},
+		{path: "/v1/{name}/", pattern: "/v1/{p1}/{$}", params: []*PathParam{{Field: "name", Value: `r.PathValue("p1")`}}},
+		{path: "/v1/{name=shelves/*}", pattern: "/v1/shelves/{p1}", params: []*PathParam{{Field: "name", Value: `"shelves/" + r.PathValue("p1")`}}},
+		{path: "/v1/{name=shelves/*/books/*}:get", errMsg: "暂不支持自定义动词后缀"},
+		{path: "/v1/{parent=shelves/*}/books/{book.id}", pattern: "/v1/shelves/{p1}/books/{p2}", params: []*PathParam{
+			{Field: "parent", Value: `"shelves/" + r.PathValue("p1")`},
+			{Field: "book.id", Value: `r.PathValue("p2")`},
+		}},
+		{path: "/v1/{path=files/**}", pattern: "/v1/files/{p1...}", params: []*PathParam{{Field: "path", Value: `"files/" + r.PathValue("p1")`}}},
+		{path: "/v1/users:lookup", errMsg: "暂不支持自定义动词后缀"},
+		{path: "v1/ping", errMsg: "必须以 / 开头"},
+		{path: "/v1/{name", errMsg: "缺少 }"},
+		{path: "/v1/*/items", errMsg: "暂不支持变量之外的通配符"},
+		{path: "/v1/{path=**}/items", errMsg: "** 只能出现在路径末尾"},
+		{path: "/v1/{path=**}/", errMsg: "** 只能出现在路径末尾"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			pattern, params, err := muxPattern(tt.path)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("muxPattern(%q) 的错误为 %v，期望包含 %q", tt.path, err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("muxPattern(%q) 失败: %v", tt.path, err)
+			}
+			if pattern != tt.pattern {
+				t.Errorf("muxPattern(%q) = %q，期望 %q", tt.path, pattern, tt.pattern)
+			}
+			if !reflect.DeepEqual(params, tt.params) {
+				t.Errorf("muxPattern(%q) 的路径参数为 %+v，期望 %+v", tt.path, params, tt.params)
+			}
+		})
+	}
+}
+
+func TestGatewayPath(t *testing.T) {
+	tests := []struct {
+		pattern, prefix, re string
+	}{
+		{"/v1/ping", "", `^/v1/ping$`},
+		{"/v1/items/{$}", "", `^/v1/items/$`},
+		{"/v1/shelves/{p1}", "/v1/shelves/", `^/v1/shelves/[^/]+$`},
+		{"/v1/{p1}/{$}", "/v1/", `^/v1/[^/]+/$`},
+		{"/v1/files/{p1...}", "/v1/files/", `^/v1/files/.*$`},
+	}
+	for _, tt := range tests {
+		prefix, re := gatewayPath(tt.pattern)
+		if prefix != tt.prefix || re != tt.re {
+			t.Errorf("gatewayPath(%q) = %q, %q，期望 %q, %q", tt.pattern, prefix, re, tt.prefix, tt.re)
+		}
+	}
+}
+
+const httpTestProto = `syntax = "proto3";
+
+package http.v1;
+
+import "google/api/annotations.proto";
+
+option go_package = "example.com/httppb";
+
+service BookService {
+  rpc GetBook(Request) returns (Response) {
+    option (google.api.http) = {
+      get: "/v1/{name=books/*}"
+      additional_bindings { get: "/v1/books/{name}:lookup" }
+      additional_bindings { post: "/v1/books/{name}/read" body: "*" response_body: "name" }
+    };
+  }
+  rpc ListBooks(Request) returns (Response) {
+    option (google.api.http) = { get: "/v1/books/" };
+  }
+  rpc Duplicate(Request) returns (Response) {
+    option (google.api.http) = {
+      get: "/v1/{name=shelves/*}"
+      additional_bindings { get: "/v1/shelves/{name}" }
+    };
+  }
+  rpc Plain(Request) returns (Response);
+}
+
+message Request { string name = 1; }
+message Response { string name = 1; }
+`
+
+func TestNewHTTPBindings(t *testing.T) {
+	methods := testMethods(t, testPlugin(t, map[string]string{"http/v1/http.proto": httpTestProto}, ""), "http/v1/http.proto")
+
+	type binding struct {
+		Method, Pattern, Body, ResponseBody, Err string
+	}
+	tests := []struct {
+		method string
+		want   []binding
+	}{
+		{"GetBook", []binding{
+			{Method: "GET", Pattern: "GET /v1/books/{p1}"},
+			{Method: "GET", Err: "暂不支持自定义动词后缀"},
+			{Method: "POST", Pattern: "POST /v1/books/{p1}/read", Body: "*", ResponseBody: "name"},
+		}},
+		{"ListBooks", []binding{{Method: "GET", Pattern: "GET /v1/books/{$}"}}},
+		// /v1/{name=shelves/*} 与 /v1/shelves/{name} 转换为相同的路由
+		{"Duplicate", []binding{
+			{Method: "GET", Pattern: "GET /v1/shelves/{p1}"},
+			{Method: "GET", Err: "与第 1 条 HTTP 规则重复"},
+		}},
+		{"Plain", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			bindings := newHTTPBindings(methods[tt.method])
+			var got []binding
+			for _, b := range bindings {
+				g := binding{Method: b.Method, Pattern: b.Pattern, Body: b.Body, ResponseBody: b.ResponseBody}
+				if b.err != nil {
+					g = binding{Method: b.Method, Err: b.err.Error()}
+				}
+				got = append(got, g)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("得到 %d 条绑定 %+v，期望 %d 条", len(got), got, len(tt.want))
+			}
+			for i := range got {
+				want := tt.want[i]
+				if want.Err != "" {
+					if !strings.Contains(got[i].Err, want.Err) {
+						t.Errorf("第 %d 条绑定的错误为 %q，期望包含 %q", i+1, got[i].Err, want.Err)
+					}
+					continue
+				}
+				if got[i] != want {
+					t.Errorf("第 %d 条绑定为 %+v，期望 %+v", i+1, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckHTTPRoutes(t *testing.T) {
+	route := func(pattern string) []*HTTPBinding { return []*HTTPBinding{{Pattern: pattern}} }
+	shelves := &ServiceInfo{FullName: "shelves.ShelfService", Methods: []*MethodInfo{
+		{Name: "GetShelf", HTTP: route("GET /v1/shelves/{p1}")},
+		{Name: "ListShelves", HTTP: route("GET /v1/shelves/{$}")},
+	}}
+	tests := []struct {
+		name    string
+		methods []*MethodInfo
+		mounted []*ServiceInfo
+		errMsg  string
+	}{
+		{name: "路由不同", methods: []*MethodInfo{
+			{Name: "Get", HTTP: route("GET /v1/books/{p1}")},
+			{Name: "List", HTTP: route("GET /v1/books/{$}")},
+			{Name: "Create", HTTP: route("POST /v1/books/{$}")},
+			{Name: "Read", HTTP: route("GET /v1/books/featured")},
+		}, mounted: []*ServiceInfo{shelves}},
+		{name: "不同方法的路由重复", methods: []*MethodInfo{
+			{Name: "Get", HTTP: route("GET /v1/books/{p1}")},
+			{Name: "Lookup", HTTP: route("GET /v1/books/{p1}")},
+		}, errMsg: "方法 Lookup 与方法 Get 声明了相同的 HTTP 路由 GET /v1/books/{p1}"},
+		{name: "同一方法的路由冲突", methods: []*MethodInfo{
+			{Name: "Get", HTTP: []*HTTPBinding{{Pattern: "GET /v1/{p1}/books"}, {Pattern: "GET /v1/shelves/{p1}"}}},
+		}, errMsg: "方法 Get 的 HTTP 路由 GET /v1/shelves/{p1} 与方法 Get 的路由 GET /v1/{p1}/books 冲突"},
+		{name: "与其他服务的路由重复", methods: []*MethodInfo{
+			{Name: "Get", HTTP: route("GET /v1/shelves/{p1}")},
+		}, mounted: []*ServiceInfo{shelves}, errMsg: "方法 Get 与服务 shelves.ShelfService 的方法 GetShelf 声明了相同的 HTTP 路由"},
+		{name: "与其他服务的路由冲突", methods: []*MethodInfo{
+			{Name: "List", HTTP: route("GET /v1/{p1}/books")},
+		}, mounted: []*ServiceInfo{shelves}, errMsg: "与服务 shelves.ShelfService 的方法 GetShelf 的路由 GET /v1/shelves/{p1} 冲突"},
+		{name: "未挂载到同一 ServeMux", methods: []*MethodInfo{
+			{Name: "Get", HTTP: route("GET /v1/shelves/{p1}")},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkHTTPRoutes(&ServiceInfo{FullName: "books.BookService", Methods: tt.methods}, tt.mounted)
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("checkHTTPRoutes 失败: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("checkHTTPRoutes 的错误为 %v，期望包含 %q", err, tt.errMsg)
+			}
+		})
+	}
+}
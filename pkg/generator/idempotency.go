@@ -0,0 +1,54 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/lhdbsbz/protoc-gen-service-registry/registry"
+)
+
+// defaultIdempotencyTTL 幂等键默认的有效期
+const defaultIdempotencyTTL = 24 * time.Hour
+
+// IdempotencyInfo 一元方法的幂等键
+type IdempotencyInfo struct {
+	KeyField string        // 存放幂等键的请求字段路径，如 meta.idempotency_key
+	KeyValue string        // 从请求 req 中取幂等键的 Go 表达式，如 req.GetMeta().GetIdempotencyKey()
+	TTL      time.Duration // 幂等键的有效期
+
+	err error // 配置不合法，生成时报错
+}
+
+// newIdempotency 转换幂等键配置，按请求消息 input 解析 key_field
+func newIdempotency(idem *registry.Idempotency, input *protogen.Message, streaming bool) *IdempotencyInfo {
+	if idem == nil {
+		return nil
+	}
+	info := &IdempotencyInfo{KeyField: strings.TrimSpace(idem.GetKeyField()), TTL: defaultIdempotencyTTL}
+	if v := strings.TrimSpace(idem.GetTtl()); v != "" {
+		ttl, err := parseTimeout(v)
+		if err != nil {
+			info.err = fmt.Errorf("ttl 不是合法的正时长: %q", idem.GetTtl())
+		}
+		info.TTL = ttl
+	}
+	if streaming {
+		info.err = fmt.Errorf("流式方法不支持幂等键")
+		return info
+	}
+	field, value, err := requestField(input, info.KeyField)
+	switch {
+	case info.KeyField == "":
+		info.err = fmt.Errorf("未指定 key_field")
+	case err != nil:
+		info.err = fmt.Errorf("key_field 中的%v", err)
+	case field.Desc.Kind() != protoreflect.StringKind || field.Desc.IsList():
+		info.err = fmt.Errorf("key_field 中的字段 %s 须为非 repeated 的 string 字段", info.KeyField)
+	}
+	info.KeyValue = value
+	return info
+}
@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// goPredeclared Go 预声明的标识符，用作生成代码中的名称会遮蔽内置类型与函数
+var goPredeclared = map[string]bool{
+	"any": true, "bool": true, "byte": true, "comparable": true, "complex64": true, "complex128": true,
+	"error": true, "float32": true, "float64": true, "int": true, "int8": true, "int16": true, "int32": true,
+	"int64": true, "rune": true, "string": true, "uint": true, "uint8": true, "uint16": true, "uint32": true,
+	"uint64": true, "uintptr": true, "true": true, "false": true, "iota": true, "nil": true,
+	"append": true, "cap": true, "clear": true, "close": true, "complex": true, "copy": true, "delete": true,
+	"imag": true, "len": true, "make": true, "max": true, "min": true, "new": true, "panic": true,
+	"print": true, "println": true, "real": true, "recover": true,
+}
+
+// goIdentifier 将任意名称转换为合法的 Go 标识符，保留首字母大小写
+//
+// 非字母数字字符作为单词分隔，其后的字母大写，如 order-v2 -> orderV2、pay.gateway -> payGateway；
+// 以数字开头时加 _ 前缀；与关键字或预声明标识符相同时加 _ 后缀；
+// 与 taken 中的名称冲突时依次追加 2、3……，同样的输入总是得到同样的结果。
+func goIdentifier(s string, taken ...string) string {
+	var b strings.Builder
+	upperNext := false
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			upperNext = b.Len() > 0
+			continue
+		}
+		if upperNext {
+			r = unicode.ToUpper(r)
+			upperNext = false
+		}
+		b.WriteRune(r)
+	}
+	name := b.String()
+	switch {
+	case name == "":
+		name = "_"
+	case unicode.IsDigit([]rune(name)[0]):
+		name = "_" + name
+	case token.IsKeyword(name) || goPredeclared[name]:
+		name += "_"
+	}
+	return avoidCollision(name, taken)
+}
+
+// goExported 转换为导出的 Go 标识符，如 order-v2 -> OrderV2；
+// 首字符不是字母时加 X 前缀（与 protoc-gen-go 的处理方式一致），如 2fa -> X2fa
+func goExported(s string, taken ...string) string {
+	name := goIdentifier(s)
+	r := []rune(strings.TrimSuffix(name, "_"))
+	if len(r) == 0 || !unicode.IsLetter(r[0]) {
+		name = "X" + strings.TrimPrefix(name, "_")
+	} else {
+		r[0] = unicode.ToUpper(r[0])
+		name = string(r)
+	}
+	return avoidCollision(name, taken)
+}
+
+// goUnexported 转换为不导出的 Go 标识符，开头的缩写整体小写，如 HTTPGateway -> httpGateway、
+// User -> user；与关键字或预声明标识符相同时加 _ 后缀，如 Type -> type_
+func goUnexported(s string, taken ...string) string {
+	r := []rune(goIdentifier(s))
+	// 开头连续的大写字母视为缩写，其后紧跟小写字母时最后一个大写字母属于下一个单词
+	n := 0
+	for n < len(r) && unicode.IsUpper(r[n]) {
+		n++
+	}
+	if n > 1 && n < len(r) && unicode.IsLower(r[n]) {
+		n--
+	}
+	for i := 0; i < n; i++ {
+		r[i] = unicode.ToLower(r[i])
+	}
+	name := string(r)
+	if token.IsKeyword(name) || goPredeclared[name] {
+		name += "_"
+	}
+	return avoidCollision(name, taken)
+}
+
+// avoidCollision name 与 taken 中的名称冲突时依次追加 2、3……
+func avoidCollision(name string, taken []string) string {
+	if len(taken) == 0 {
+		return name
+	}
+	used := make(map[string]bool, len(taken))
+	for _, t := range taken {
+		used[t] = true
+	}
+	candidate := name
+	for i := 2; used[candidate]; i++ {
+		candidate = name + strconv.Itoa(i)
+	}
+	return candidate
+}
@@ -0,0 +1,126 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// importMarker import 函数在渲染结果中留下的标记，格式化前由 resolveImports 移除；
+// 使用块注释，可以出现在 Go 代码中任何允许空白的位置
+var importMarker = regexp.MustCompile(`/\*service-registry:import ("[^"]*")( [A-Za-z_][A-Za-z0-9_]*)?\*/`)
+
+// majorVersion 导入路径末尾的主版本号，如 go-redis/v9 中的 v9
+var majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// importFunc 模板函数 import：声明生成的 Go 代码可能用到的包，只有实际引用了的包才会写入 import 块
+//
+// 例如 {{import "google.golang.org/grpc/codes"}}，包名与导入路径最后一段不同时以第二个参数指定，
+// 如 {{import "github.com/redis/go-redis/v9" "redis"}}。
+func importFunc(importPath string, name ...string) (string, error) {
+	if importPath == "" || strings.ContainsAny(importPath, "\"\n") {
+		return "", fmt.Errorf("import 的导入路径不合法: %q", importPath)
+	}
+	if len(name) > 1 || len(name) == 1 && !token.IsIdentifier(name[0]) {
+		return "", fmt.Errorf("import 的包名不合法: %v", name)
+	}
+	marker := "/*service-registry:import " + strconv.Quote(importPath)
+	if len(name) == 1 {
+		marker += " " + name[0]
+	}
+	return marker + "*/", nil
+}
+
+// importName 由导入路径推断包名：去掉 /v2 等主版本后缀与 gopkg.in 的 .v3 后缀，非法字符替换为下划线
+func importName(importPath string) string {
+	base := path.Base(importPath)
+	if dir := path.Dir(importPath); dir != "." && majorVersion.MatchString(base) {
+		base = path.Base(dir)
+	}
+	if i := strings.Index(base, ".v"); i > 0 && strings.HasPrefix(importPath, "gopkg.in/") {
+		base = base[:i]
+	}
+	return goPackageName(base)
+}
+
+// resolveImports 移除 import 函数留下的标记，并把代码中实际引用了的包加入 import 块
+func resolveImports(raw []byte) ([]byte, error) {
+	matches := importMarker.FindAllSubmatch(raw, -1)
+	if len(matches) == 0 {
+		return raw, nil
+	}
+	type declared struct{ path, name string }
+	var imports []declared
+	for _, m := range matches {
+		importPath, _ := strconv.Unquote(string(m[1]))
+		name := strings.TrimSpace(string(m[2]))
+		if name == "" {
+			name = importName(importPath)
+		}
+		imports = append(imports, declared{importPath, name})
+	}
+	src := importMarker.ReplaceAll(raw, nil)
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		return nil, fmt.Errorf("解析生成的代码失败: %v", err)
+	}
+
+	// 未解析到本地声明的 pkg.X 中的 pkg 视为对包的引用
+	used := map[string]bool{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok && id.Obj == nil {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+	existing := map[string]bool{}
+	for _, spec := range f.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		existing[importPath] = true
+	}
+
+	var lines []string
+	for _, imp := range imports {
+		if existing[imp.path] || !used[imp.name] {
+			continue
+		}
+		existing[imp.path] = true
+		line := strconv.Quote(imp.path)
+		if imp.name != path.Base(imp.path) {
+			line = imp.name + " " + line
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return src, nil
+	}
+
+	// 加入第一个带括号的 import 块，没有时在 package 声明之后新建
+	var out bytes.Buffer
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT || !gen.Lparen.IsValid() {
+			continue
+		}
+		at := fset.Position(gen.Rparen).Offset
+		out.Write(src[:at])
+		out.WriteString("\t" + strings.Join(lines, "\n\t") + "\n")
+		out.Write(src[at:])
+		return out.Bytes(), nil
+	}
+	at := fset.Position(f.Name.End()).Offset
+	out.Write(src[:at])
+	out.WriteString("\n\nimport (\n\t" + strings.Join(lines, "\n\t") + "\n)\n")
+	out.Write(src[at:])
+	return out.Bytes(), nil
+}
@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// packageIndexTemplate package_index 参数启用的按 proto 包汇总的内置模板
+const packageIndexTemplate = "package_index"
+
+// PackageIndexInfo 按 proto 包汇总的渲染数据
+type PackageIndexInfo struct {
+	PackageName  string         // 生成的包名
+	ProtoPackage string         // proto 包名，如 pages.prepare_order
+	Name         string         // 生成代码中使用的标识符前缀，如 PagesPrepareOrder
+	Services     []*ServiceInfo // 该包中的服务，按注册顺序排列
+	Imports      []ImportInfo   // 去重后的 proto 包导入
+}
+
+// packageIndexes 将服务按 proto 包分组，只保留包含多个服务的包，按包内首个服务的注册顺序排列
+func packageIndexes(aggregate *AggregateInfo) []*PackageIndexInfo {
+	var indexes []*PackageIndexInfo
+	byPackage := map[string]*PackageIndexInfo{}
+	for _, s := range aggregate.Services {
+		pkg := s.FullName[:max(strings.LastIndex(s.FullName, "."), 0)]
+		index, ok := byPackage[pkg]
+		if !ok {
+			name := snakeToCamel(strings.ReplaceAll(pkg, ".", "_"))
+			if name != "" {
+				name = strings.ToUpper(name[:1]) + name[1:]
+			}
+			index = &PackageIndexInfo{PackageName: aggregate.PackageName, ProtoPackage: pkg, Name: name}
+			byPackage[pkg] = index
+			indexes = append(indexes, index)
+		}
+		index.Services = append(index.Services, s)
+	}
+
+	shared := indexes[:0]
+	for _, index := range indexes {
+		if len(index.Services) > 1 {
+			index.Imports, _ = collectImports(index.Services)
+			shared = append(shared, index)
+		}
+	}
+	return shared
+}
+
+// generatePackageIndexes 为包含多个服务的 proto 包生成 <包名>_index.go，如 order_v1_index.go
+func (g *Generator) generatePackageIndexes(gen *protogen.Plugin, aggregate *AggregateInfo) error {
+	if g.packageIndex == nil {
+		return nil
+	}
+	for _, index := range packageIndexes(aggregate) {
+		if index.ProtoPackage == "" {
+			g.warn(index.Services[0].pos, "服务未声明 proto 包，跳过包索引")
+			continue
+		}
+		raw, err := execute(g.packageIndex, index)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(g.config.OutputDir, strings.ReplaceAll(index.ProtoPackage, ".", "_")+"_index.go")
+		formatted, err := g.format(path, raw)
+		if err != nil {
+			return fmt.Errorf("生成 %s 包索引失败: %v", index.ProtoPackage, err)
+		}
+		if err := g.writeFile(gen, path, formatted, nil, packageIndexTemplate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
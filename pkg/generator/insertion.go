@@ -0,0 +1,106 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// insertionPrefix 模板中以该前缀命名的 define 块会被注入到对应插入点，
+// 例如 {{define "insert:package_scope"}}func init() {...}{{end}}
+//
+// 注意：目标文件必须由支持插入点的生成器输出，
+// 即包含 "// @@protoc_insertion_point(package_scope)" 形式的标记，
+// 且该生成器需在同一次 protoc 调用中先于本插件执行。
+// protoc-gen-go 与 protoc-gen-go-grpc 的输出都不含插入点标记，因此目标文件没有默认值，
+// 使用插入点的模板必须通过 insertion_suffix 显式指定目标文件后缀。
+const insertionPrefix = "insert:"
+
+// renderInsertions 渲染模板中的插入点代码块
+func (g *Generator) renderInsertions(tmpl *template.Template, source string, file *protogen.File, data *ServiceInfo) error {
+	target := g.insertionTarget(file)
+	for _, t := range tmpl.Templates() {
+		point, ok := strings.CutPrefix(t.Name(), insertionPrefix)
+		if !ok {
+			continue
+		}
+		if g.config.InsertionSuffix == "" {
+			return fmt.Errorf("模板定义了插入点 %s，必须通过 insertion_suffix 指定含该插入点的目标文件后缀", point)
+		}
+
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, data); err != nil {
+			return fmt.Errorf("执行插入点模板 %s 失败: %v", point, err)
+		}
+		if strings.TrimSpace(buf.String()) == "" {
+			continue
+		}
+
+		if err := g.checkNoInit(target, buf.Bytes(), true); err != nil {
+			return fmt.Errorf("插入点 %s: %v", point, err)
+		}
+		f := newPlannedFile(target, buf.Bytes(), data, g.config.stablePath(source)+" "+t.Name())
+		f.InsertionPoint = point
+		g.outputs = append(g.outputs, f)
+		g.log.Debug("生成插入点代码", "target", target, "point", point, "service", data.FullName)
+		if g.config.DryRun {
+			continue
+		}
+		g.insertions = append(g.insertions, &pluginpb.CodeGeneratorResponse_File{
+			Name:           proto.String(target),
+			InsertionPoint: proto.String(point),
+			Content:        proto.String(buf.String()),
+		})
+	}
+	return nil
+}
+
+// insertionTarget 计算插入点目标文件名，与 protogen 对 module 参数的处理保持一致
+func (g *Generator) insertionTarget(file *protogen.File) string {
+	name := file.GeneratedFilenamePrefix + g.config.InsertionSuffix
+	if g.config.Module != "" {
+		name = strings.TrimPrefix(name, g.config.Module+"/")
+	}
+	return name
+}
+
+// Insertions 返回已渲染的插入点文件
+//
+// protogen 不支持插入点，调用方需要把它们追加到 CodeGeneratorResponse 中，
+// 或直接使用 Response。
+func (g *Generator) Insertions() []*pluginpb.CodeGeneratorResponse_File {
+	return g.insertions
+}
+
+// Response 生成插件响应，追加插入点文件并执行生成后钩子
+func (g *Generator) Response(gen *protogen.Plugin) *pluginpb.CodeGeneratorResponse {
+	resp := gen.Response()
+	if resp.Error != nil {
+		return resp
+	}
+	resp.File = append(resp.File, g.insertions...)
+	// fail_fast=false 时响应同时携带已生成的文件与汇总的错误，不再执行生成后钩子；
+	// protoc 与 buf 遇到 error 字段时不会写出文件，generate 子命令仍会写出
+	if g.failed != nil {
+		resp.Error = proto.String(g.failed.Error())
+		return resp
+	}
+	if err := g.runPostHooks(resp); err != nil {
+		return &pluginpb.CodeGeneratorResponse{
+			Error:             proto.String(err.Error()),
+			SupportedFeatures: resp.SupportedFeatures,
+		}
+	}
+	if err := g.checkResponseSize(proto.Size(resp)); err != nil {
+		return &pluginpb.CodeGeneratorResponse{
+			Error:             proto.String(err.Error()),
+			SupportedFeatures: resp.SupportedFeatures,
+		}
+	}
+	return resp
+}
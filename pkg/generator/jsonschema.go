@@ -0,0 +1,92 @@
+package generator
+
+import "encoding/json"
+
+// jsonWellKnown well-known types 在 protojson 编码下对应的 JSON Schema
+var jsonWellKnown = map[string]map[string]any{
+	"google.protobuf.Timestamp":   {"type": "string", "format": "date-time"},
+	"google.protobuf.Duration":    {"type": "string", "pattern": `^-?[0-9]+(\.[0-9]+)?s$`},
+	"google.protobuf.FieldMask":   {"type": "string"},
+	"google.protobuf.StringValue": {"type": "string"},
+	"google.protobuf.BytesValue":  {"type": "string", "contentEncoding": "base64"},
+	"google.protobuf.Int32Value":  {"type": "integer", "format": "int32"},
+	"google.protobuf.UInt32Value": {"type": "integer", "format": "uint32"},
+	"google.protobuf.Int64Value":  {"type": "string", "format": "int64"},
+	"google.protobuf.UInt64Value": {"type": "string", "format": "uint64"},
+	"google.protobuf.FloatValue":  {"type": "number", "format": "float"},
+	"google.protobuf.DoubleValue": {"type": "number", "format": "double"},
+	"google.protobuf.BoolValue":   {"type": "boolean"},
+	"google.protobuf.Struct":      {"type": "object"},
+	"google.protobuf.Value":       {},
+	"google.protobuf.ListValue":   {"type": "array"},
+	"google.protobuf.Any":         {"type": "object", "required": []string{"@type"}},
+	"google.protobuf.Empty":       {"type": "object"},
+}
+
+// isJSONWellKnown 判断消息是否以内联 JSON Schema 表示，而不是引用
+func isJSONWellKnown(fullName string) bool {
+	_, ok := jsonWellKnown[fullName]
+	return ok
+}
+
+// jsonSchemaRef 返回消息的 JSON Schema（JSON 编码）：well-known types 内联，其他消息引用 prefix+typeName
+func jsonSchemaRef(prefix, fullName, typeName string) string {
+	return encodeJSONSchema(messageSchema(prefix, fullName, typeName))
+}
+
+// jsonSchema 返回字段值的 JSON Schema（JSON 编码），与 protojson 的编码方式一致，
+// 消息与枚举引用 prefix+TypeName，如 #/components/schemas/
+func jsonSchema(prefix string, f *FieldInfo) string {
+	return encodeJSONSchema(fieldSchema(prefix, f))
+}
+
+func messageSchema(prefix, fullName, typeName string) map[string]any {
+	if s, ok := jsonWellKnown[fullName]; ok {
+		return s
+	}
+	return map[string]any{"$ref": prefix + typeName}
+}
+
+func fieldSchema(prefix string, f *FieldInfo) map[string]any {
+	if f.Map && f.MapValue != nil {
+		return map[string]any{"type": "object", "additionalProperties": valueSchema(prefix, f.MapValue)}
+	}
+	s := valueSchema(prefix, f)
+	if f.Repeated {
+		return map[string]any{"type": "array", "items": s}
+	}
+	return s
+}
+
+// valueSchema 返回单个值（不考虑 repeated）的 JSON Schema
+func valueSchema(prefix string, f *FieldInfo) map[string]any {
+	switch f.Kind {
+	case "message":
+		return messageSchema(prefix, f.TypeFull, f.TypeName)
+	case "enum":
+		return map[string]any{"$ref": prefix + f.TypeName}
+	case "bool":
+		return map[string]any{"type": "boolean"}
+	case "string":
+		return map[string]any{"type": "string"}
+	case "bytes":
+		return map[string]any{"type": "string", "contentEncoding": "base64"}
+	case "int32", "sint32", "sfixed32", "uint32", "fixed32":
+		return map[string]any{"type": "integer"}
+	case "int64", "sint64", "sfixed64", "uint64", "fixed64":
+		// protojson 将 64 位整数编码为字符串
+		return map[string]any{"type": "string", "format": "int64"}
+	case "float", "double":
+		return map[string]any{"type": "number"}
+	}
+	return map[string]any{}
+}
+
+// encodeJSONSchema 编码为紧凑的 JSON，键按字母排序，可直接嵌入 YAML
+func encodeJSONSchema(s map[string]any) string {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
@@ -0,0 +1,40 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lhdbsbz/protoc-gen-service-registry/registry"
+)
+
+// LimitsInfo 方法的消息大小与截止时间限制
+type LimitsInfo struct {
+	MaxRequestBytes  int           // 请求消息编码后的最大字节数，0 表示不限制
+	MaxResponseBytes int           // 响应消息编码后的最大字节数，0 表示不限制
+	DefaultDeadline  time.Duration // 请求未携带截止时间时使用的截止时间，0 表示不设置
+
+	err error // 配置不合法，生成时报错
+}
+
+// newLimits 转换 (registry.limits)，未声明任何限制时为 nil
+func newLimits(limits *registry.Limits) *LimitsInfo {
+	if limits == nil {
+		return nil
+	}
+	info := &LimitsInfo{
+		MaxRequestBytes:  int(limits.GetMaxRequestBytes()),
+		MaxResponseBytes: int(limits.GetMaxResponseBytes()),
+	}
+	if v := strings.TrimSpace(limits.GetDefaultDeadline()); v != "" {
+		deadline, err := parseTimeout(v)
+		if err != nil {
+			info.err = fmt.Errorf("default_deadline 不是合法的正时长: %q", limits.GetDefaultDeadline())
+		}
+		info.DefaultDeadline = deadline
+	}
+	if *info == (LimitsInfo{}) {
+		return nil
+	}
+	return info
+}
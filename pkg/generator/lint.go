@@ -0,0 +1,137 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// namingKinds enforce_naming 支持的规则类型及其检查对象
+var namingKinds = map[string]string{
+	"service": "服务名",
+	"method":  "方法名",
+	"package": "proto 包名",
+	"file":    "proto 文件路径",
+}
+
+// NamingRule 命名规则，来自插件参数 enforce_naming
+type NamingRule struct {
+	Kind    string         // 检查对象: service、method、package 或 file
+	Pattern *regexp.Regexp // 名称必须匹配的正则表达式
+}
+
+// String 返回与插件参数相同的 kind:pattern 形式
+func (r NamingRule) String() string {
+	return r.Kind + ":" + r.Pattern.String()
+}
+
+// parseNamingRule 解析 enforce_naming 参数，格式为 kind:regexp
+func parseNamingRule(value string) (NamingRule, error) {
+	kind, pattern, ok := strings.Cut(value, ":")
+	kind = strings.TrimSpace(kind)
+	if _, known := namingKinds[kind]; !ok || !known || pattern == "" {
+		return NamingRule{}, fmt.Errorf("enforce_naming 格式错误，应为 service|method|package|file:正则表达式: %s", value)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return NamingRule{}, fmt.Errorf("enforce_naming 的正则表达式无效 %q: %v", pattern, err)
+	}
+	return NamingRule{Kind: kind, Pattern: re}, nil
+}
+
+// checkNaming 按 enforce_naming 规则检查所有需要生成的 proto 文件，汇总全部违规后返回
+//
+// 每条违规带有 proto 文件中的行列号，格式与编译器错误一致，便于编辑器跳转。
+func checkNaming(files []*protogen.File, rules []NamingRule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	var violations []string
+	check := func(kind, name string, desc protoreflect.Descriptor) {
+		for _, rule := range rules {
+			if rule.Kind != kind || rule.Pattern.MatchString(name) {
+				continue
+			}
+			violations = append(violations, fmt.Sprintf("%s: %s %s 不符合命名规则 %s",
+				sourcePosition(desc), namingKinds[kind], name, rule))
+		}
+	}
+	for _, f := range files {
+		if !f.Generate {
+			continue
+		}
+		check("file", f.Desc.Path(), f.Desc)
+		check("package", string(f.Desc.Package()), f.Desc)
+		for _, service := range f.Services {
+			check("service", string(service.Desc.Name()), service.Desc)
+			for _, method := range service.Methods {
+				check("method", string(method.Desc.Name()), method.Desc)
+			}
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("命名检查未通过，共 %d 处违规:\n  %s", len(violations), strings.Join(violations, "\n  "))
+}
+
+// sourcePosition 返回定义在 proto 文件中的位置，如 user/user.proto:12:9；
+// 文件本身取 package 声明的位置，缺少源码信息时只返回文件路径
+func sourcePosition(desc protoreflect.Descriptor) string {
+	file := desc.ParentFile()
+	var loc protoreflect.SourceLocation
+	if fd, ok := desc.(protoreflect.FileDescriptor); ok {
+		file = fd
+		loc = file.SourceLocations().ByPath(protoreflect.SourcePath{2}) // FileDescriptorProto.package
+	} else {
+		loc = file.SourceLocations().ByDescriptor(desc)
+	}
+	if loc.Path == nil {
+		return file.Path()
+	}
+	return fmt.Sprintf("%s:%d:%d", file.Path(), loc.StartLine+1, loc.StartColumn+1)
+}
+
+// initFunc 无法解析的代码中 init 函数的声明
+var initFunc = regexp.MustCompile(`(?m)^func\s+init\s*\(\s*\)`)
+
+// forbidsInit 是否禁止生成的代码中出现 init 函数：forbid_init=true 或 registration=lazy
+func (c *PluginConfig) forbidsInit() bool {
+	return c.ForbidInit || c.Registration == registrationLazy
+}
+
+// checkNoInit 禁止 init 函数时检查生成的 Go 代码，fragment 为 true 时 src 为插入点代码块，不含 package 声明
+func (g *Generator) checkNoInit(path string, src []byte, fragment bool) error {
+	if !g.config.forbidsInit() || filepath.Ext(path) != ".go" {
+		return nil
+	}
+	if fragment {
+		src = append([]byte("package p\n"), src...)
+	}
+	found := false
+	if f, err := parser.ParseFile(token.NewFileSet(), path, src, parser.SkipObjectResolution); err == nil {
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "init" {
+				found = true
+			}
+		}
+	} else {
+		// 插入点代码块不一定能单独解析，按行匹配
+		found = initFunc.Match(src)
+	}
+	if !found {
+		return nil
+	}
+	option := "forbid_init=true"
+	if !g.config.ForbidInit {
+		option = "registration=lazy"
+	}
+	return fmt.Errorf("%s 时生成的代码不能包含 init 函数: %s", option, path)
+}
@@ -0,0 +1,50 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// envName 合法的环境变量名
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// LocalityInfo 服务实例的地域信息，来自插件参数 region 与 zone，注册时写入元数据的 region 与 zone，
+// 供按地域优先的负载均衡使用
+//
+// 参数值为 env:NAME 时在运行时读取环境变量 NAME，环境变量为空时不写入对应的元数据。
+type LocalityInfo struct {
+	Region    string // 固定的区域，如 cn-hangzhou
+	RegionEnv string // 运行时读取区域的环境变量名，如 REGION
+	Zone      string // 固定的可用区，如 cn-hangzhou-h
+	ZoneEnv   string // 运行时读取可用区的环境变量名，如 ZONE
+}
+
+// parseLocality 解析 region 与 zone 参数，返回固定值或环境变量名
+func parseLocality(key, v string) (value, env string, err error) {
+	name, ok := strings.CutPrefix(v, "env:")
+	if !ok {
+		return v, "", nil
+	}
+	if !envName.MatchString(name) {
+		return "", "", fmt.Errorf("%s 的环境变量名不合法: %s", key, v)
+	}
+	return "", name, nil
+}
+
+// newLocality 由插件参数生成地域信息，region 与 zone 都未指定时返回 nil
+func newLocality(config *PluginConfig) *LocalityInfo {
+	l := config.Locality
+	if l == (LocalityInfo{}) {
+		return nil
+	}
+	return &l
+}
+
+// localityString 返回 region 与 zone 参数的展示值
+func localityString(value, env string) string {
+	if env != "" {
+		return "env:" + env
+	}
+	return value
+}
@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// lroOperation 长时间运行操作（AIP-151）的响应消息
+const lroOperation = "google.longrunning.Operation"
+
+// lroOperations 查询长时间运行操作的服务，其方法本身不是长时间运行操作
+const lroOperations = "google.longrunning.Operations"
+
+// operationInfoField google.longrunning.operation_info 方法选项的字段号
+const operationInfoField = 1049
+
+// LROInfo 返回 google.longrunning.Operation 的一元方法的操作信息，来自 google.longrunning.operation_info
+type LROInfo struct {
+	ResponseType string // 操作完成后 response 中的消息全限定名，如 pages.Order，未声明时为空
+	MetadataType string // 操作执行期间 metadata 中的消息全限定名，未声明时为空
+}
+
+// newLRO 检测返回 google.longrunning.Operation 的一元方法，其他方法与 google.longrunning.Operations 服务的方法为 nil
+//
+// 插件没有链接 google.longrunning 的扩展类型，operation_info 作为 MethodOptions 的未知字段保留，在此直接解析。
+func newLRO(method *protogen.Method) *LROInfo {
+	if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() ||
+		method.Output.Desc.FullName() != lroOperation || method.Parent.Desc.FullName() == lroOperations {
+		return nil
+	}
+	info := &LROInfo{}
+	opts, ok := method.Desc.Options().(*descriptorpb.MethodOptions)
+	if !ok || opts == nil {
+		return info
+	}
+	pkg := string(method.Desc.ParentFile().Package())
+	rangeStringFields(opts.ProtoReflect().GetUnknown(), func(num protowire.Number, v []byte) {
+		if num != operationInfoField {
+			return
+		}
+		rangeStringFields(v, func(num protowire.Number, v []byte) {
+			switch num {
+			case 1:
+				info.ResponseType = qualifyTypeName(string(v), pkg)
+			case 2:
+				info.MetadataType = qualifyTypeName(string(v), pkg)
+			}
+		})
+	})
+	return info
+}
+
+// rangeStringFields 访问编码后的消息 b 中长度前缀类型的字段，遇到无法解析的内容时停止
+func rangeStringFields(b []byte, visit func(num protowire.Number, v []byte)) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return
+		}
+		b = b[n:]
+		if typ == protowire.BytesType {
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return
+			}
+			visit(num, v)
+			b = b[n:]
+			continue
+		}
+		if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+			return
+		}
+		b = b[n:]
+	}
+}
+
+// qualifyTypeName 将 operation_info 中的类型名转为全限定名，不含 . 的名称视为与方法同包
+func qualifyTypeName(name, pkg string) string {
+	name = strings.TrimPrefix(strings.TrimSpace(name), ".")
+	if name == "" || strings.Contains(name, ".") || pkg == "" {
+		return name
+	}
+	return pkg + "." + name
+}
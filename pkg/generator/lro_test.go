@@ -0,0 +1,114 @@
+package generator
+
+import "testing"
+
+// longrunningTestProto google/longrunning/operations.proto 中测试用到的部分
+const longrunningTestProto = `syntax = "proto3";
+
+package google.longrunning;
+
+import "google/protobuf/any.proto";
+import "google/protobuf/descriptor.proto";
+
+option go_package = "cloud.google.com/go/longrunning/autogen/longrunningpb";
+
+extend google.protobuf.MethodOptions {
+  OperationInfo operation_info = 1049;
+}
+
+service Operations {
+  rpc GetOperation(GetOperationRequest) returns (Operation);
+}
+
+message Operation {
+  string name = 1;
+  google.protobuf.Any metadata = 2;
+  bool done = 3;
+  google.protobuf.Any response = 5;
+}
+
+message GetOperationRequest { string name = 1; }
+
+message OperationInfo {
+  string response_type = 1;
+  string metadata_type = 2;
+}
+`
+
+const lroTestProto = `syntax = "proto3";
+
+package shop.v1;
+
+import "google/longrunning/operations.proto";
+
+option go_package = "example.com/shoppb";
+
+service OrderService {
+  rpc ExportOrders(Request) returns (google.longrunning.Operation) {
+    option (google.longrunning.operation_info) = {
+      response_type: "ExportResponse"
+      metadata_type: "common.v1.ProgressMetadata"
+    };
+  }
+  rpc PurgeOrders(Request) returns (google.longrunning.Operation) {
+    option (google.longrunning.operation_info) = { response_type: ".shop.v1.ExportResponse" };
+  }
+  rpc RebuildIndex(Request) returns (google.longrunning.Operation);
+  rpc WatchExport(Request) returns (stream google.longrunning.Operation);
+  rpc GetOrder(Request) returns (ExportResponse);
+}
+
+message Request { string name = 1; }
+message ExportResponse { string uri = 1; }
+`
+
+func TestNewLRO(t *testing.T) {
+	gen := testPlugin(t, map[string]string{
+		"google/longrunning/operations.proto": longrunningTestProto,
+		"shop/v1/lro.proto":                   lroTestProto,
+	}, "")
+	methods := testMethods(t, gen, "shop/v1/lro.proto")
+
+	tests := []struct {
+		method string
+		want   *LROInfo
+	}{
+		{"ExportOrders", &LROInfo{ResponseType: "shop.v1.ExportResponse", MetadataType: "common.v1.ProgressMetadata"}},
+		{"PurgeOrders", &LROInfo{ResponseType: "shop.v1.ExportResponse"}},
+		{"RebuildIndex", &LROInfo{}},
+		{"WatchExport", nil},
+		{"GetOrder", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			got := newLRO(methods[tt.method])
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Errorf("newLRO(%s) = %+v，期望 %+v", tt.method, got, tt.want)
+			}
+		})
+	}
+
+	// google.longrunning.Operations 自身的方法不是长时间运行操作
+	operations := testMethods(t, gen, "google/longrunning/operations.proto")
+	if got := newLRO(operations["GetOperation"]); got != nil {
+		t.Errorf("newLRO(GetOperation) = %+v，期望 nil", got)
+	}
+}
+
+func TestQualifyTypeName(t *testing.T) {
+	tests := []struct {
+		name, pkg, want string
+	}{
+		{"ExportResponse", "shop.v1", "shop.v1.ExportResponse"},
+		{" ExportResponse ", "shop.v1", "shop.v1.ExportResponse"},
+		{"common.v1.Progress", "shop.v1", "common.v1.Progress"},
+		{".shop.v1.ExportResponse", "shop.v1", "shop.v1.ExportResponse"},
+		{"ExportResponse", "", "ExportResponse"},
+		{"", "shop.v1", ""},
+	}
+	for _, tt := range tests {
+		if got := qualifyTypeName(tt.name, tt.pkg); got != tt.want {
+			t.Errorf("qualifyTypeName(%q, %q) = %q，期望 %q", tt.name, tt.pkg, got, tt.want)
+		}
+	}
+}
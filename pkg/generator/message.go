@@ -0,0 +1,177 @@
+package generator
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/lhdbsbz/protoc-gen-service-registry/registry"
+)
+
+// MessageType 方法请求与响应中（直接或通过字段间接）用到的消息类型，用于生成 schema 类输出
+type MessageType struct {
+	Name     string       // 本次生成中唯一的类型名，通常为 Go 类型名，冲突时为以 _ 连接的全限定名
+	FullName string       // proto 消息全限定名
+	Fields   []*FieldInfo // 字段，按声明顺序排列
+	Input    bool         // 作为请求消息或其字段使用
+	Output   bool         // 作为响应消息或其字段使用
+	MapEntry bool         // map 字段自动生成的键值对消息
+}
+
+// SensitiveFields 返回声明了 (registry.sensitive) 的字段名
+func (t *MessageType) SensitiveFields() []string {
+	var names []string
+	for _, f := range t.Fields {
+		if f.Sensitive {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
+
+// EnumType 用到的枚举类型
+type EnumType struct {
+	Name     string   // 本次生成中唯一的类型名
+	FullName string   // proto 枚举全限定名
+	Values   []string // 枚举值名称
+}
+
+// FieldInfo 消息字段
+type FieldInfo struct {
+	Name     string // proto 字段名
+	JSONName string // JSON 字段名
+	GoName   string // Go 字段名
+	Kind     string // proto 类型，如 string、int64、message、enum
+	TypeName string // message 与 enum 字段引用的唯一类型名，其他类型为空
+	TypeFull string // message 与 enum 字段引用的全限定名，其他类型为空
+	Repeated bool   // repeated 字段（包括 map）
+	Map      bool   // map 字段，此时 TypeName 为键值对消息
+	Optional bool   // 显式声明了 optional
+
+	MapValue *FieldInfo // map 字段的值字段，非 map 字段为 nil
+
+	Sensitive bool // 敏感字段，来自 (registry.sensitive)
+}
+
+// collectTypes 收集服务方法用到的所有消息与枚举，按首次出现的顺序排列，并为每个类型分配唯一名称
+func collectTypes(services []*ServiceInfo) ([]*MessageType, []*EnumType) {
+	var messages []*MessageType
+	var enums []*EnumType
+	messageIndex := map[protoreflect.FullName]*MessageType{}
+	enumIndex := map[protoreflect.FullName]*EnumType{}
+	goNames := map[string]int{} // Go 类型名出现次数，用于检测冲突
+
+	var visit func(m *protogen.Message, input bool)
+	visit = func(m *protogen.Message, input bool) {
+		if t, ok := messageIndex[m.Desc.FullName()]; ok {
+			// 已访问过，只在首次作为请求或响应使用时继续向下标记
+			if (input && t.Input) || (!input && t.Output) {
+				return
+			}
+		} else {
+			t = &MessageType{Name: m.GoIdent.GoName, FullName: string(m.Desc.FullName()), MapEntry: m.Desc.IsMapEntry()}
+			messageIndex[m.Desc.FullName()] = t
+			messages = append(messages, t)
+			goNames[t.Name]++
+			for _, f := range m.Fields {
+				t.Fields = append(t.Fields, newFieldInfo(f))
+			}
+		}
+		t := messageIndex[m.Desc.FullName()]
+		if input {
+			t.Input = true
+		} else {
+			t.Output = true
+		}
+
+		for _, f := range m.Fields {
+			switch {
+			case f.Message != nil:
+				visit(f.Message, input)
+			case f.Enum != nil:
+				if _, ok := enumIndex[f.Enum.Desc.FullName()]; ok {
+					continue
+				}
+				e := &EnumType{Name: f.Enum.GoIdent.GoName, FullName: string(f.Enum.Desc.FullName())}
+				for _, v := range f.Enum.Values {
+					e.Values = append(e.Values, string(v.Desc.Name()))
+				}
+				enumIndex[f.Enum.Desc.FullName()] = e
+				enums = append(enums, e)
+				goNames[e.Name]++
+			}
+		}
+	}
+	for _, s := range services {
+		for _, m := range s.Methods {
+			visit(m.Input.message, true)
+			visit(m.Output.message, false)
+		}
+	}
+
+	// Go 类型名在不同包之间冲突时，改用全限定名
+	for _, t := range messages {
+		if goNames[t.Name] > 1 {
+			t.Name = uniqueTypeName(t.FullName)
+		}
+	}
+	for _, e := range enums {
+		if goNames[e.Name] > 1 {
+			e.Name = uniqueTypeName(e.FullName)
+		}
+	}
+
+	// 回填字段与方法引用的类型名
+	for _, t := range messages {
+		for _, f := range t.Fields {
+			for _, field := range []*FieldInfo{f, f.MapValue} {
+				if field == nil {
+					continue
+				}
+				if m, ok := messageIndex[protoreflect.FullName(field.TypeFull)]; ok {
+					field.TypeName = m.Name
+				} else if e, ok := enumIndex[protoreflect.FullName(field.TypeFull)]; ok {
+					field.TypeName = e.Name
+				}
+			}
+		}
+	}
+	for _, s := range services {
+		for _, m := range s.Methods {
+			m.Input.TypeName = messageIndex[protoreflect.FullName(m.Input.FullName)].Name
+			m.Output.TypeName = messageIndex[protoreflect.FullName(m.Output.FullName)].Name
+		}
+	}
+	return messages, enums
+}
+
+// newFieldInfo 提取字段信息
+func newFieldInfo(f *protogen.Field) *FieldInfo {
+	info := &FieldInfo{
+		Name:     string(f.Desc.Name()),
+		JSONName: f.Desc.JSONName(),
+		GoName:   f.GoName,
+		Kind:     f.Desc.Kind().String(),
+		Repeated: f.Desc.IsList() || f.Desc.IsMap(),
+		Map:      f.Desc.IsMap(),
+		Optional: f.Desc.HasOptionalKeyword(),
+
+		Sensitive: fieldOption[bool](f, registry.E_Sensitive),
+	}
+	if f.Desc.IsMap() {
+		info.MapValue = newFieldInfo(f.Message.Fields[1])
+	}
+	switch {
+	case f.Message != nil:
+		info.TypeFull = string(f.Message.Desc.FullName())
+	case f.Enum != nil:
+		info.TypeFull = string(f.Enum.Desc.FullName())
+	}
+	return info
+}
+
+// uniqueTypeName 由全限定名生成类型名，如 order.v1.Order -> order_v1_Order
+func uniqueTypeName(fullName string) string {
+	return strings.ReplaceAll(fullName, ".", "_")
+}
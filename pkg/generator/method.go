@@ -0,0 +1,373 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/lhdbsbz/protoc-gen-service-registry/registry"
+)
+
+// MethodInfo 服务方法信息，用于模板渲染
+type MethodInfo struct {
+	Name            string       // proto 方法名，如 CreateOrder
+	GoName          string       // Go 标识符，通常与 Name 相同
+	FullMethod      string       // gRPC 完整方法路径，如 /pages.OrderService/CreateOrder
+	Input           *MessageInfo // 请求消息
+	Output          *MessageInfo // 响应消息
+	ClientStreaming bool         // 客户端流式
+	ServerStreaming bool         // 服务端流式
+	Comment         string       // 方法的前置注释，已去掉注释符号
+	Deprecated      bool         // 方法声明了 deprecated = true
+
+	HTTP []*HTTPBinding // google.api.http 注解定义的 HTTP 路由，未声明时为空
+
+	GRPCWeb bool // 是否通过 gRPC-Web 暴露，由 (registry.grpc_web_origins) 与 (registry.grpc_web) 决定
+
+	Validated bool // 请求消息（含嵌套消息）声明了 protovalidate 或 PGV 约束，需要在处理前校验
+
+	ErrorCode   string // 非 gRPC 状态错误映射的状态码名称，来自 (registry.error_code) 或服务的 (registry.default_error_code)，如 UNAVAILABLE
+	ErrorCodeGo string // ErrorCode 对应的 codes 包常量名，如 Unavailable，ErrorCode 为空时为空
+
+	RateLimit *RateLimitInfo // 方法限流，来自 (registry.rate_limit)，未声明时为 nil
+
+	// CircuitBreaker 客户端熔断策略，来自 (registry.circuit_breaker) 或服务的 (registry.default_circuit_breaker)，
+	// 未声明或为流式方法时为 nil
+	CircuitBreaker *CircuitBreakerInfo
+
+	FeatureFlag string // 方法的功能开关名称，来自 (registry.method_feature_flag)，开关关闭时调用返回 Unimplemented
+
+	Timeout time.Duration // 客户端调用的超时时间，来自 (registry.timeout)，未声明时为 0
+	Retry   *RetryInfo    // 客户端重试策略，来自 (registry.retry)，未声明时为 nil
+
+	Cache       *CacheInfo       // 响应缓存，来自 (registry.cache)，未声明时为 nil
+	Idempotency *IdempotencyInfo // 幂等键，来自 (registry.idempotency)，未声明时为 nil
+	Audit       *AuditInfo       // 审计配置，来自 (registry.audit) 与 (registry.audit_resource)，未声明时为 nil
+	Limits      *LimitsInfo      // 消息大小与截止时间限制，来自 (registry.limits)，未声明任何限制时为 nil
+	RoutingKey  *RoutingKeyInfo  // 分片路由键，来自 (registry.routing_key)，未声明时为 nil
+	TenantField *TenantFieldInfo // 租户来源，来自 (registry.tenant_field)，未声明时为 nil
+
+	Pagination *PaginationInfo // 遵循 AIP-158 分页约定的一元方法的分页字段，不遵循时为 nil
+	IsLRO      bool            // 一元方法返回 google.longrunning.Operation（AIP-151 长时间运行操作）
+	LRO        *LROInfo        // 长时间运行操作的响应与元数据类型，IsLRO 为 false 时为 nil
+	FieldMask  *FieldMaskInfo  // 遵循 AIP-134 的 Update 方法的字段掩码与资源，不遵循时为 nil
+
+	pos        string // 方法在 proto 文件中的位置，用于警告
+	timeoutErr error  // (registry.timeout) 不合法，生成时报错
+}
+
+// RateLimitInfo 方法限流配置
+type RateLimitInfo struct {
+	QPS   float64 // 每秒允许的请求数
+	Burst int     // 允许的突发请求数，未声明时为 QPS 向上取整
+}
+
+// MessageInfo 方法请求或响应消息的 Go 类型信息
+type MessageInfo struct {
+	GoName      string // Go 类型名，如 CreateOrderRequest
+	FullName    string // proto 消息全限定名，如 pages.CreateOrderRequest
+	PackageName string // 引用类型时使用的包名，与服务同包时为 ProtoPackageName
+	ImportPath  string // Go 导入路径
+	TypeName    string // 在汇总数据 Messages 中的唯一类型名，仅汇总模板中可用
+
+	// Options 消息上声明的自定义选项，键为扩展的全限定名，如 {{index .Output.Options "cache.ttl"}}；
+	// 枚举值为名称，消息值为以字段名为键的 map，未声明任何选项时为 nil
+	Options map[string]any
+
+	message *protogen.Message
+}
+
+// CircuitBreakerInfo 客户端熔断策略
+type CircuitBreakerInfo struct {
+	FailureThreshold int           // 连续失败达到该次数时熔断
+	OpenTimeout      time.Duration // 熔断持续时间，之后进入半开状态
+	HalfOpenRequests int           // 半开状态下放行的探测请求数
+
+	err error // 配置不合法，生成时报错
+}
+
+// RetryInfo 客户端重试策略
+type RetryInfo struct {
+	Attempts      int           // 最多重试次数
+	PerTryTimeout time.Duration // 每次尝试的超时时间，为 0 时由网格决定
+	RetryOn       []string      // 触发重试的条件，为空时由网格决定
+
+	err error // 配置不合法，生成时报错
+}
+
+// newMethods 提取服务的所有方法，按 proto 中的声明顺序排列
+func newMethods(file *protogen.File, service *protogen.Service) []*MethodInfo {
+	// 启用了 gRPC-Web 的服务中，声明了 (registry.grpc_web) 的方法为暴露白名单，都未声明时暴露全部方法
+	grpcWeb := len(serviceOption[[]string](service, registry.E_GrpcWebOrigins)) > 0
+	exposeAll := true
+	for _, m := range service.Methods {
+		if methodOption[bool](m, registry.E_GrpcWeb) {
+			exposeAll = false
+		}
+	}
+
+	defaultErrorCode := serviceOption[string](service, registry.E_DefaultErrorCode)
+	defaultCircuitBreaker := serviceOption[*registry.CircuitBreaker](service, registry.E_DefaultCircuitBreaker)
+
+	methods := make([]*MethodInfo, 0, len(service.Methods))
+	for _, m := range service.Methods {
+		errorCode := methodOption[string](m, registry.E_ErrorCode)
+		if errorCode == "" {
+			errorCode = defaultErrorCode
+		}
+		errorCode = strings.ToUpper(strings.TrimSpace(errorCode))
+
+		circuitBreaker := methodOption[*registry.CircuitBreaker](m, registry.E_CircuitBreaker)
+		if circuitBreaker == nil {
+			circuitBreaker = defaultCircuitBreaker
+		}
+		if m.Desc.IsStreamingClient() || m.Desc.IsStreamingServer() {
+			circuitBreaker = nil
+		}
+
+		timeout, timeoutErr := parseTimeout(methodOption[string](m, registry.E_Timeout))
+		lro := newLRO(m)
+		methods = append(methods, &MethodInfo{
+			Name:            string(m.Desc.Name()),
+			GoName:          m.GoName,
+			FullMethod:      fmt.Sprintf("/%s/%s", service.Desc.FullName(), m.Desc.Name()),
+			Input:           newMessageInfo(file, m.Input),
+			Output:          newMessageInfo(file, m.Output),
+			ClientStreaming: m.Desc.IsStreamingClient(),
+			ServerStreaming: m.Desc.IsStreamingServer(),
+			Comment:         commentText(m.Comments.Leading),
+			Deprecated:      methodDeprecated(m),
+			HTTP:            newHTTPBindings(m),
+			GRPCWeb:         grpcWeb && (exposeAll || methodOption[bool](m, registry.E_GrpcWeb)),
+			Validated:       hasConstraints(m.Input),
+			ErrorCode:       errorCode,
+			ErrorCodeGo:     grpcCodes[errorCode],
+			RateLimit:       newRateLimit(m),
+			CircuitBreaker:  newCircuitBreaker(circuitBreaker),
+			FeatureFlag:     strings.TrimSpace(methodOption[string](m, registry.E_MethodFeatureFlag)),
+			Timeout:         timeout,
+			Retry:           newRetry(methodOption[*registry.Retry](m, registry.E_Retry)),
+			Cache:           newCache(methodOption[*registry.Cache](m, registry.E_Cache), m.Input, m.Desc.IsStreamingClient() || m.Desc.IsStreamingServer()),
+			Idempotency:     newIdempotency(methodOption[*registry.Idempotency](m, registry.E_Idempotency), m.Input, m.Desc.IsStreamingClient() || m.Desc.IsStreamingServer()),
+			Audit:           newAudit(methodOption[bool](m, registry.E_Audit), methodOption[[]string](m, registry.E_AuditResource), m.Input, m.Desc.IsStreamingClient() || m.Desc.IsStreamingServer()),
+			Limits:          newLimits(methodOption[*registry.Limits](m, registry.E_Limits)),
+			RoutingKey:      newRoutingKey(methodOption[string](m, registry.E_RoutingKey), m.Input),
+			TenantField:     newTenantField(methodOption[string](m, registry.E_TenantField), m.Input, m.Desc.IsStreamingClient() || m.Desc.IsStreamingServer()),
+			Pagination:      newPagination(file, m),
+			IsLRO:           lro != nil,
+			LRO:             lro,
+			FieldMask:       newFieldMask(file, m),
+			pos:             sourcePosition(m.Desc),
+			timeoutErr:      timeoutErr,
+		})
+	}
+	return methods
+}
+
+// methodDeprecated 方法是否声明了 deprecated = true
+func methodDeprecated(m *protogen.Method) bool {
+	opts, ok := m.Desc.Options().(*descriptorpb.MethodOptions)
+	return ok && opts.GetDeprecated()
+}
+
+// grpcCodes gRPC 状态码名称与 google.golang.org/grpc/codes 常量名的对应关系，不包括 OK
+var grpcCodes = map[string]string{
+	"CANCELLED":           "Canceled",
+	"UNKNOWN":             "Unknown",
+	"INVALID_ARGUMENT":    "InvalidArgument",
+	"DEADLINE_EXCEEDED":   "DeadlineExceeded",
+	"NOT_FOUND":           "NotFound",
+	"ALREADY_EXISTS":      "AlreadyExists",
+	"PERMISSION_DENIED":   "PermissionDenied",
+	"RESOURCE_EXHAUSTED":  "ResourceExhausted",
+	"FAILED_PRECONDITION": "FailedPrecondition",
+	"ABORTED":             "Aborted",
+	"OUT_OF_RANGE":        "OutOfRange",
+	"UNIMPLEMENTED":       "Unimplemented",
+	"INTERNAL":            "Internal",
+	"UNAVAILABLE":         "Unavailable",
+	"DATA_LOSS":           "DataLoss",
+	"UNAUTHENTICATED":     "Unauthenticated",
+}
+
+// newRateLimit 读取方法的限流配置
+func newRateLimit(method *protogen.Method) *RateLimitInfo {
+	limit := methodOption[*registry.RateLimit](method, registry.E_RateLimit)
+	if limit == nil {
+		return nil
+	}
+	info := &RateLimitInfo{QPS: limit.GetQps(), Burst: int(limit.GetBurst())}
+	if info.Burst == 0 {
+		info.Burst = int(math.Ceil(info.QPS))
+	}
+	return info
+}
+
+// newCircuitBreaker 转换熔断策略并填充默认值
+func newCircuitBreaker(cb *registry.CircuitBreaker) *CircuitBreakerInfo {
+	if cb == nil {
+		return nil
+	}
+	info := &CircuitBreakerInfo{
+		FailureThreshold: int(cb.GetFailureThreshold()),
+		OpenTimeout:      30 * time.Second,
+		HalfOpenRequests: int(cb.GetHalfOpenRequests()),
+	}
+	if info.HalfOpenRequests == 0 {
+		info.HalfOpenRequests = 1
+	}
+	if info.FailureThreshold == 0 {
+		info.err = fmt.Errorf("failure_threshold 必须大于 0")
+	}
+	if v := cb.GetOpenTimeout(); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil || timeout <= 0 {
+			info.err = fmt.Errorf("open_timeout 不是合法的正时长: %s", v)
+		}
+		info.OpenTimeout = timeout
+	}
+	return info
+}
+
+// parseTimeout 解析 (registry.timeout)，未声明时为 0
+func parseTimeout(v string) (time.Duration, error) {
+	if v == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(v)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("timeout 不是合法的正时长: %s", v)
+	}
+	return timeout, nil
+}
+
+// newRetry 转换重试策略，条件去除首尾空白
+func newRetry(retry *registry.Retry) *RetryInfo {
+	if retry == nil {
+		return nil
+	}
+	info := &RetryInfo{Attempts: int(retry.GetAttempts())}
+	if info.Attempts == 0 {
+		info.err = fmt.Errorf("attempts 必须大于 0")
+	}
+	if v := retry.GetPerTryTimeout(); v != "" {
+		timeout, err := parseTimeout(v)
+		if err != nil {
+			info.err = fmt.Errorf("per_try_timeout 不是合法的正时长: %s", v)
+		}
+		info.PerTryTimeout = timeout
+	}
+	for _, on := range retry.GetRetryOn() {
+		if on = strings.TrimSpace(on); on != "" {
+			info.RetryOn = append(info.RetryOn, on)
+		}
+	}
+	return info
+}
+
+// checkMethods 校验服务方法上的错误状态码映射、限流、熔断、超时、重试、响应缓存、幂等键、审计、限制、路由键与租户来源配置
+func checkMethods(s *ServiceInfo) error {
+	for _, m := range s.Methods {
+		if m.ErrorCode != "" && m.ErrorCodeGo == "" {
+			return fmt.Errorf("方法 %s 的错误状态码 %s 不合法，应为 OK 以外的 gRPC 状态码名称，如 UNAVAILABLE", m.FullMethod, m.ErrorCode)
+		}
+		if r := m.RateLimit; r != nil && (r.QPS <= 0 || r.Burst <= 0) {
+			return fmt.Errorf("方法 %s 的限流配置不合法，qps 与 burst 必须大于 0: qps=%v burst=%d", m.FullMethod, r.QPS, r.Burst)
+		}
+		if cb := m.CircuitBreaker; cb != nil && cb.err != nil {
+			return fmt.Errorf("方法 %s 的熔断配置不合法: %v", m.FullMethod, cb.err)
+		}
+		if m.timeoutErr != nil {
+			return fmt.Errorf("方法 %s 的超时配置不合法: %v", m.FullMethod, m.timeoutErr)
+		}
+		if r := m.Retry; r != nil && r.err != nil {
+			return fmt.Errorf("方法 %s 的重试配置不合法: %v", m.FullMethod, r.err)
+		}
+		if c := m.Cache; c != nil && c.err != nil {
+			return fmt.Errorf("方法 %s 的响应缓存配置不合法: %v", m.FullMethod, c.err)
+		}
+		if i := m.Idempotency; i != nil && i.err != nil {
+			return fmt.Errorf("方法 %s 的幂等键配置不合法: %v", m.FullMethod, i.err)
+		}
+		if a := m.Audit; a != nil && a.err != nil {
+			return fmt.Errorf("方法 %s 的审计配置不合法: %v", m.FullMethod, a.err)
+		}
+		if l := m.Limits; l != nil && l.err != nil {
+			return fmt.Errorf("方法 %s 的限制配置不合法: %v", m.FullMethod, l.err)
+		}
+		if r := m.RoutingKey; r != nil && r.err != nil {
+			return fmt.Errorf("方法 %s 的路由键配置不合法: %v", m.FullMethod, r.err)
+		}
+		if t := m.TenantField; t != nil && t.err != nil {
+			return fmt.Errorf("方法 %s 的租户来源配置不合法: %v", m.FullMethod, t.err)
+		}
+	}
+	return nil
+}
+
+// newMessageInfo 提取消息的 Go 类型信息
+//
+// 与服务不在同一个 Go 包的消息（如 emptypb.Empty）先以导入路径的最后一段作为包名，
+// 生成时由 resolvePackageNames 改为消息所在文件的 Go 包名，汇总模板通过 MessageImports 以该名称显式导入。
+func newMessageInfo(file *protogen.File, message *protogen.Message) *MessageInfo {
+	info := &MessageInfo{
+		GoName:      message.GoIdent.GoName,
+		FullName:    string(message.Desc.FullName()),
+		PackageName: string(file.GoPackageName),
+		ImportPath:  string(message.GoIdent.GoImportPath),
+		Options:     messageOptions(message),
+
+		message: message,
+	}
+	if message.GoIdent.GoImportPath != file.GoImportPath {
+		info.PackageName = goPackageName(info.ImportPath)
+	}
+	return info
+}
+
+// requestField 解析请求消息中以 . 分隔的字段路径，中间字段须为非 repeated 的消息，
+// 返回最后一个字段，以及从请求 req 中取字段值的 Go 表达式，如 req.GetFilter().GetTags()
+func requestField(message *protogen.Message, path string) (*protogen.Field, string, error) {
+	value := "req"
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		var field *protogen.Field
+		for _, f := range message.Fields {
+			if string(f.Desc.Name()) == name {
+				field = f
+				break
+			}
+		}
+		if field == nil {
+			return nil, "", fmt.Errorf("字段 %s 不存在于消息 %s", path, message.Desc.FullName())
+		}
+		value += ".Get" + field.GoName + "()"
+		if i == len(names)-1 {
+			return field, value, nil
+		}
+		if field.Message == nil || field.Desc.IsList() || field.Desc.IsMap() {
+			return nil, "", fmt.Errorf("字段 %s 的 %s 不是非 repeated 的消息字段", path, name)
+		}
+		message = field.Message
+	}
+	return nil, "", fmt.Errorf("字段路径为空")
+}
+
+// goPackageName 由导入路径推导包名，非法字符替换为下划线
+func goPackageName(importPath string) string {
+	name := []byte(path.Base(importPath))
+	for i, c := range name {
+		if !isLower(c) && !isUpper(c) && !isDigit(c) {
+			name[i] = '_'
+		}
+	}
+	if len(name) > 0 && isDigit(name[0]) {
+		name = append([]byte{'_'}, name...)
+	}
+	return string(name)
+}
@@ -0,0 +1,33 @@
+package generator
+
+import (
+	"slices"
+	"strings"
+)
+
+// multiRegistryTemplate 同时注册到多个服务中心的汇总模板
+const multiRegistryTemplate = "multi_registry"
+
+// registryBackendTemplates 可由 multi_registry 汇总模板组合的服务中心汇总模板
+var registryBackendTemplates = []string{"consul_registry", "nacos_registry", "etcd_registry", "zookeeper_registry"}
+
+// registryBackends 启用了 multi_registry 汇总模板时，返回同时启用的服务中心名称，按 templates 中的顺序排列，
+// 如 consul_registry+nacos_registry -> consul、nacos；未启用时为 nil
+func registryBackends(templates []string) []string {
+	if !slices.Contains(templates, multiRegistryTemplate) {
+		return nil
+	}
+	return enabledRegistryBackends(templates)
+}
+
+// enabledRegistryBackends 返回 templates 中启用的服务中心名称，按 templates 中的顺序排列
+func enabledRegistryBackends(templates []string) []string {
+	var backends []string
+	for _, name := range templates {
+		backend := strings.TrimSuffix(name, "_registry")
+		if slices.Contains(registryBackendTemplates, name) && !slices.Contains(backends, backend) {
+			backends = append(backends, backend)
+		}
+	}
+	return backends
+}
@@ -0,0 +1,60 @@
+package generator
+
+import "strings"
+
+// toCamelCase 将大驼峰转换为小驼峰格式
+// 例如: "PrepareOrder" -> "prepareOrder", "Order" -> "order", "User" -> "user"
+func toCamelCase(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+
+	// 如果第一个字符是大写字母，将其转为小写
+	first := s[0]
+	if first >= 'A' && first <= 'Z' {
+		// 将第一个字符转为小写，其余保持不变
+		return string(first+32) + s[1:]
+	}
+
+	// 如果第一个字符已经小写，直接返回
+	return s
+}
+
+// snakeToCamel 将下划线命名转换为小驼峰格式
+// 例如: "register_all" -> "registerAll", "consul_registry" -> "consulRegistry"
+func snakeToCamel(s string) string {
+	parts := strings.Split(s, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return toCamelCase(strings.Join(parts, ""))
+}
+
+// toKebabCase 将大驼峰转换为 kebab-case 格式，连续的大写字母视为一个缩写词
+// 例如: "PrepareOrder" -> "prepare-order", "HTTPGateway" -> "http-gateway", "OrderV2" -> "order-v2"
+func toKebabCase(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isUpper(c) {
+			if i > 0 && (isLower(s[i-1]) || isDigit(s[i-1]) ||
+				(isUpper(s[i-1]) && i+1 < len(s) && isLower(s[i+1]))) {
+				b.WriteByte('-')
+			}
+			b.WriteByte(c + 32)
+			continue
+		}
+		if c == '_' {
+			b.WriteByte('-')
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+func isUpper(c byte) bool { return c >= 'A' && c <= 'Z' }
+func isLower(c byte) bool { return c >= 'a' && c <= 'z' }
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
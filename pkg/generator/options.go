@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// serviceOption 读取服务上声明的 registry.* 选项，未声明时返回零值
+func serviceOption[T any](service *protogen.Service, xt protoreflect.ExtensionType) T {
+	opts, ok := service.Desc.Options().(*descriptorpb.ServiceOptions)
+	if !ok || opts == nil {
+		var zero T
+		return zero
+	}
+	return proto.GetExtension(opts, xt).(T)
+}
+
+// methodOption 读取方法上声明的 registry.* 选项，未声明时返回零值
+func methodOption[T any](method *protogen.Method, xt protoreflect.ExtensionType) T {
+	opts, ok := method.Desc.Options().(*descriptorpb.MethodOptions)
+	if !ok || opts == nil {
+		var zero T
+		return zero
+	}
+	return proto.GetExtension(opts, xt).(T)
+}
+
+// fieldOption 读取字段上声明的 registry.* 选项，未声明时返回零值
+func fieldOption[T any](field *protogen.Field, xt protoreflect.ExtensionType) T {
+	opts, ok := field.Desc.Options().(*descriptorpb.FieldOptions)
+	if !ok || opts == nil {
+		var zero T
+		return zero
+	}
+	return proto.GetExtension(opts, xt).(T)
+}
@@ -0,0 +1,237 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"path"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// PlannedFile 一个已生成（dry_run 时为计划生成）的文件
+type PlannedFile struct {
+	Path           string `json:"path"`                      // 输出路径
+	InsertionPoint string `json:"insertion_point,omitempty"` // 插入点名称，仅插入点代码非空
+	Proto          string `json:"proto,omitempty"`           // 来源 proto 文件，汇总文件为空
+	Service        string `json:"service,omitempty"`         // 来源服务的全限定名，汇总文件为空
+	Template       string `json:"template"`                  // 使用的模板
+	Size           int    `json:"size"`                      // 内容字节数
+	SHA256         string `json:"sha256"`                    // 内容的 SHA-256 十六进制摘要
+
+	goPackage string // Go 文件的包名，供 GeneratedFile 使用
+	content   []byte // compact=package 时留待合并的内容，输出后清空
+}
+
+// newPlannedFile 记录一个输出，data 为 nil 表示汇总文件
+func newPlannedFile(path string, content []byte, data *ServiceInfo, source string) PlannedFile {
+	f := PlannedFile{Path: path, Template: source, Size: len(content), SHA256: sha256Hex(content)}
+	if data != nil {
+		f.Proto = data.ProtoFile
+		f.Service = data.FullName
+	}
+	if filepath.Ext(path) == ".go" {
+		f.goPackage = sourcePackageName(content)
+	}
+	return f
+}
+
+// writeFile 记录并输出生成的文件，dry_run 时只记录不输出；compact=package 时 Go 文件由 compactOutputs 合并后输出
+func (g *Generator) writeFile(gen *protogen.Plugin, path string, content []byte, data *ServiceInfo, source string) error {
+	if err := g.checkNoInit(path, content, false); err != nil {
+		return err
+	}
+	path, content, err := g.gzipArtifact(path, content)
+	if err != nil {
+		return err
+	}
+	f := newPlannedFile(path, content, data, g.config.stablePath(source))
+	if g.deferred(path) {
+		f.content = content
+		g.outputs = append(g.outputs, f)
+		return nil
+	}
+	g.outputs = append(g.outputs, f)
+	g.log.Debug("生成文件", "path", path, "service", f.Service, "template", source, "dry_run", g.config.DryRun)
+	if g.config.DryRun {
+		return nil
+	}
+	if _, err := gen.NewGeneratedFile(path, "").Write(content); err != nil {
+		return fmt.Errorf("写入文件失败: %v", err)
+	}
+	return nil
+}
+
+// Outputs 返回本次生成的所有文件，按生成顺序排列
+func (g *Generator) Outputs() []PlannedFile {
+	return g.outputs
+}
+
+// WritePlan 以表格形式输出生成计划，供 dry_run 使用
+func (g *Generator) WritePlan(w io.Writer) {
+	fmt.Fprintf(w, "dry_run: 共 %d 个文件，未写出任何内容\n", len(g.outputs))
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "文件\t字节\t服务\t模板")
+	for _, f := range g.outputs {
+		service := f.Service
+		if service == "" {
+			service = "-"
+		}
+		path := f.Path
+		if f.InsertionPoint != "" {
+			path += "#" + f.InsertionPoint
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", path, f.Size, service, f.Template)
+	}
+	tw.Flush()
+}
+
+// Report 生成报告，列出本次生成的所有文件
+type Report struct {
+	Parameter string        `json:"parameter"` // 插件参数
+	Files     []PlannedFile `json:"files"`     // 生成的文件，按生成顺序排列
+}
+
+// writeReport 将生成报告以 JSON 输出到 report 参数指定的文件，报告本身不计入其中
+func (g *Generator) writeReport(gen *protogen.Plugin) error {
+	if g.config.ReportFile == "" || g.config.DryRun {
+		return nil
+	}
+	content, err := json.MarshalIndent(Report{Parameter: g.parameter(gen), Files: g.outputs}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("生成报告失败: %v", err)
+	}
+	if _, err := gen.NewGeneratedFile(g.config.ReportFile, "").Write(append(content, '\n')); err != nil {
+		return fmt.Errorf("写入文件失败: %v", err)
+	}
+	return nil
+}
+
+// Manifest 输入与输出文件清单，内容只取决于输入与参数，供 Bazel、Pants 等构建系统预先声明输出
+type Manifest struct {
+	Parameter string          `json:"parameter"` // 插件参数
+	Inputs    []ManifestInput `json:"inputs"`    // 需要生成的 proto 文件，按路径排序
+	Aggregate []string        `json:"aggregate"` // 依赖全部输入的汇总文件，按路径排序
+	Outputs   []string        `json:"outputs"`   // 所有输出文件，按路径排序
+}
+
+// ManifestInput 一个输入 proto 文件及由它生成的文件
+type ManifestInput struct {
+	Proto   string   `json:"proto"`   // proto 文件路径
+	Outputs []string `json:"outputs"` // 由该文件中的服务生成的文件，按路径排序
+}
+
+// OutputPaths 返回本次生成会写出的文件路径，按路径排序并去重
+//
+// 不包括插入点（目标文件由其他插件生成），包括 report、manifest 与 attestation 参数指定的文件。
+func (g *Generator) OutputPaths() []string {
+	var paths []string
+	for _, f := range g.outputs {
+		if f.InsertionPoint == "" {
+			paths = append(paths, f.Path)
+		}
+	}
+	for _, path := range []string{g.config.ReportFile, g.config.ManifestFile, g.config.AttestationFile} {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+	return slices.Compact(paths)
+}
+
+// writeManifest 将输入与输出清单以 JSON 输出到 manifest 参数指定的文件
+func (g *Generator) writeManifest(gen *protogen.Plugin) error {
+	if g.config.ManifestFile == "" || g.config.DryRun {
+		return nil
+	}
+	byProto := map[string][]string{}
+	var aggregate []string
+	for _, f := range g.outputs {
+		switch {
+		case f.InsertionPoint != "":
+		case f.Proto == "":
+			aggregate = append(aggregate, f.Path)
+		default:
+			byProto[f.Proto] = append(byProto[f.Proto], f.Path)
+		}
+	}
+	slices.Sort(aggregate)
+
+	manifest := Manifest{
+		Parameter: g.parameter(gen),
+		Inputs:    []ManifestInput{},
+		Aggregate: slices.Compact(aggregate),
+		Outputs:   g.OutputPaths(),
+	}
+	inputs := slices.Clone(gen.Request.GetFileToGenerate())
+	slices.Sort(inputs)
+	for _, proto := range inputs {
+		outputs := byProto[proto]
+		slices.Sort(outputs)
+		manifest.Inputs = append(manifest.Inputs, ManifestInput{Proto: proto, Outputs: append([]string{}, slices.Compact(outputs)...)})
+	}
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("生成清单失败: %v", err)
+	}
+	if _, err := gen.NewGeneratedFile(g.config.ManifestFile, "").Write(append(content, '\n')); err != nil {
+		return fmt.Errorf("写入文件失败: %v", err)
+	}
+	return nil
+}
+
+// GeneratedFile 本次生成中已输出的文件，供汇总模板与 scope=all 模板引用
+type GeneratedFile struct {
+	Path        string // 相对于输出根目录的路径
+	Dir         string // 所在目录
+	PackageName string // Go 文件的包名，其他文件为空
+	ImportPath  string // Go 文件所在包的导入路径：指定了 module 时为 module 与 Dir 拼接，否则为 Dir（与 paths=import 一致）
+	Proto       string // 来源 proto 文件，汇总文件为空
+	Service     string // 来源服务的全限定名，汇总文件为空
+	Template    string // 使用的模板
+}
+
+// generatedFiles 返回此前已输出的文件（不含插入点），作为渲染阶段时包括之前各阶段的输出
+func (g *Generator) generatedFiles() []GeneratedFile {
+	var planned []PlannedFile
+	if g.parent != nil {
+		planned = append(planned, g.parent.outputs...)
+	}
+	planned = append(planned, g.outputs...)
+
+	files := make([]GeneratedFile, 0, len(planned))
+	for _, f := range planned {
+		if f.InsertionPoint != "" {
+			continue
+		}
+		dir := path.Dir(filepath.ToSlash(f.Path))
+		file := GeneratedFile{
+			Path:        f.Path,
+			Dir:         dir,
+			PackageName: f.goPackage,
+			Proto:       f.Proto,
+			Service:     f.Service,
+			Template:    f.Template,
+		}
+		if f.goPackage != "" {
+			file.ImportPath = path.Join(g.config.Module, dir)
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// sourcePackageName 返回 Go 源文件的包名，无法解析时为空
+func sourcePackageName(content []byte) string {
+	f, err := parser.ParseFile(token.NewFileSet(), "", content, parser.PackageClauseOnly)
+	if err != nil {
+		return ""
+	}
+	return f.Name.Name
+}
@@ -0,0 +1,154 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// 服务文件的 Go 包划分方式，来自插件参数 package_mode
+const (
+	packageModeSingle   = "single"    // 所有服务输出到 output_dir，包名为 package_name
+	packageModePerProto = "per_proto" // 服务输出到其 proto 文件的 Go 包目录，包名与 protoc-gen-go 生成的代码相同
+)
+
+// applyPackageMode package_mode=per_proto 时将服务的包名与输出目录改为 proto 文件的 Go 包
+//
+// 输出目录与 protoc-gen-go 的输出位置一致，同样受 paths 与 module 参数影响；
+// module 写在配置文件中时 protogen 不会处理，这里补充去掉模块前缀。
+func applyPackageMode(s *ServiceInfo, file *protogen.File, config *PluginConfig) {
+	if config.PackageMode != packageModePerProto {
+		return
+	}
+	s.PackageName = string(file.GoPackageName)
+	dir := path.Dir(file.GeneratedFilenamePrefix)
+	if config.Module != "" {
+		if rel, ok := strings.CutPrefix(dir, config.Module+"/"); ok {
+			dir = rel
+		} else if dir == config.Module {
+			dir = "."
+		}
+	}
+	s.outputDir = dir
+}
+
+// outputDir 返回服务文件的输出目录
+func (g *Generator) outputDir(data *ServiceInfo) string {
+	if data.outputDir != "" {
+		return data.outputDir
+	}
+	return g.config.OutputDir
+}
+
+// unqualifySelfImport 与 proto 代码同包输出时，去掉模板对 proto 包的导入，并把 pkg.Name 改为 Name
+//
+// 内置模板都以 {{.ProtoPackageName}}.X 引用 proto 类型，据此无需为同包输出单独维护模板。
+// 非 Go 文件或不同包时原样返回。
+func unqualifySelfImport(path string, raw []byte, data *ServiceInfo) ([]byte, error) {
+	if data.outputDir == "" || filepath.Ext(path) != ".go" {
+		return raw, nil
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, raw, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("解析生成的代码失败: %v", err)
+	}
+
+	name := ""
+	for i, spec := range f.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		if importPath != data.ProtoImportPath {
+			continue
+		}
+		name = data.ProtoPackageName
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		f.Imports = append(f.Imports[:i], f.Imports[i+1:]...)
+		removeImportSpec(f, spec)
+		break
+	}
+	if name == "" || name == "_" || name == "." {
+		return raw, nil
+	}
+
+	unqualify(reflect.ValueOf(f.Decls), name)
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, f); err != nil {
+		return nil, fmt.Errorf("输出生成的代码失败: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// removeImportSpec 从 import 声明中删除 spec，声明为空时一并删除
+func removeImportSpec(f *ast.File, spec *ast.ImportSpec) {
+	for i, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		for j, s := range gen.Specs {
+			if s != spec {
+				continue
+			}
+			gen.Specs = append(gen.Specs[:j], gen.Specs[j+1:]...)
+			if len(gen.Specs) == 0 {
+				f.Decls = append(f.Decls[:i], f.Decls[i+1:]...)
+			}
+			return
+		}
+	}
+}
+
+// unqualify 将 node 中所有 name.X 形式的表达式原地替换为 X，name 被局部变量遮蔽时除外
+//
+// go/ast 没有通用的节点替换接口，这里通过反射遍历所有 ast.Expr 字段与切片元素。
+func unqualify(node reflect.Value, name string) {
+	switch node.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if node.IsNil() {
+			return
+		}
+		unqualify(node.Elem(), name)
+	case reflect.Slice:
+		for i := 0; i < node.Len(); i++ {
+			unqualifyExpr(node.Index(i), name)
+			unqualify(node.Index(i), name)
+		}
+	case reflect.Struct:
+		for i := 0; i < node.NumField(); i++ {
+			field := node.Field(i)
+			// 对象与作用域会引用回声明，跳过以免循环
+			if t := field.Type(); t == reflect.TypeFor[*ast.Object]() || t == reflect.TypeFor[*ast.Scope]() {
+				continue
+			}
+			unqualifyExpr(field, name)
+			unqualify(field, name)
+		}
+	}
+}
+
+// unqualifyExpr v 为 name.X 时替换为 X
+func unqualifyExpr(v reflect.Value, name string) {
+	if v.Type() != reflect.TypeFor[ast.Expr]() || !v.CanSet() {
+		return
+	}
+	sel, ok := v.Interface().(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	if id, ok := sel.X.(*ast.Ident); ok && id.Name == name && id.Obj == nil {
+		v.Set(reflect.ValueOf(ast.Expr(sel.Sel)))
+	}
+}
@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"path"
+	"strconv"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// resolvePackageNames 确定引用服务、消息与分页结果元素时使用的包名
+//
+// newMessageInfo 只拿得到服务所在文件，其他包中的消息按导入路径的最后一段推断包名，
+// go_package 以 ;name 指定了包名（如 example.com/user/v1;userv1）时推断结果与 protoc-gen-go 不一致。
+// 插件请求中包含所有依赖文件，这里按消息所在文件查出实际的包名。
+//
+// 不同导入路径的包可能同名（如 user/v1 与 order/v1 都是 v1），同一文件中同时导入会冲突。
+// 按服务、消息的顺序首次出现的包保留原名，之后同名的包改用由导入路径生成的别名（如 orderv1），
+// 每个导入路径在所有生成文件中使用同一个名称。
+func resolvePackageNames(gen *protogen.Plugin, services []*ServiceInfo) {
+	names := map[string]string{} // 导入路径 -> 包名
+	taken := map[string]bool{}
+	name := func(importPath, pkg string) string {
+		if n, ok := names[importPath]; ok {
+			return n
+		}
+		n := pkg
+		if taken[n] {
+			n = packageAlias(importPath, taken)
+		}
+		names[importPath] = n
+		taken[n] = true
+		return n
+	}
+
+	for _, s := range services {
+		s.ProtoPackageName = name(s.ProtoImportPath, s.goPackage)
+		if s.Proto != nil {
+			s.Proto.GoPackageName = s.ProtoPackageName
+		}
+	}
+
+	resolve := func(msg *MessageInfo) {
+		if msg == nil || msg.message == nil {
+			return
+		}
+		if f := gen.FilesByPath[msg.message.Desc.ParentFile().Path()]; f != nil {
+			msg.PackageName = string(f.GoPackageName)
+		}
+		msg.PackageName = name(msg.ImportPath, msg.PackageName)
+	}
+	for _, s := range services {
+		for _, m := range s.Methods {
+			resolve(m.Input)
+			resolve(m.Output)
+			if m.FieldMask != nil {
+				resolve(m.FieldMask.Resource)
+			}
+			if p := m.Pagination; p != nil && p.item != nil {
+				pkg := p.ItemPackageName
+				if f := gen.FilesByPath[p.item.ParentFile().Path()]; f != nil {
+					pkg = string(f.GoPackageName)
+				}
+				p.setItemPackageName(name(p.ItemImportPath, pkg))
+			}
+		}
+	}
+}
+
+// packageAlias 由导入路径的最后两段生成未被占用的包名，如 example.com/order/v1 -> orderv1，仍冲突时追加序号
+func packageAlias(importPath string, taken map[string]bool) string {
+	base := path.Base(importPath)
+	if dir := path.Dir(importPath); dir != "." && dir != "/" {
+		base = path.Base(dir) + base
+	}
+	alias := goPackageName(base)
+	for i := 2; taken[alias]; i++ {
+		alias = goPackageName(base) + strconv.Itoa(i)
+	}
+	return alias
+}
@@ -0,0 +1,90 @@
+package generator
+
+import "testing"
+
+// userTestProto go_package 以 ;userv1 指定包名，与导入路径最后一段 userpb 不同
+const userTestProto = `syntax = "proto3";
+
+package user.v1;
+
+option go_package = "example.com/e2e/userpb;userv1";
+
+message User { string id = 1; string name = 2; }
+message GetUserRequest { string id = 1; }
+`
+
+const accountTestProto = `syntax = "proto3";
+
+package account.v1;
+
+import "google/protobuf/field_mask.proto";
+import "user/v1/user.proto";
+
+option go_package = "example.com/e2e/accountpb";
+
+service AccountService {
+  rpc GetOwner(user.v1.GetUserRequest) returns (user.v1.User);
+  rpc UpdateOwner(UpdateOwnerRequest) returns (Account);
+  rpc ListOwners(ListOwnersRequest) returns (ListOwnersResponse);
+}
+
+message Account { string id = 1; }
+message UpdateOwnerRequest {
+  user.v1.User owner = 1;
+  google.protobuf.FieldMask update_mask = 2;
+}
+message ListOwnersRequest { int32 page_size = 1; string page_token = 2; }
+message ListOwnersResponse { repeated user.v1.User owners = 1; string next_page_token = 2; }
+`
+
+func TestResolvePackageNames(t *testing.T) {
+	gen := testPlugin(t, map[string]string{
+		"user/v1/user.proto":       userTestProto,
+		"account/v1/account.proto": accountTestProto,
+	}, "")
+	file := gen.FilesByPath["account/v1/account.proto"]
+	s := NewServiceInfo(file, file.Services[0], &PluginConfig{})
+	resolvePackageNames(gen, []*ServiceInfo{s})
+
+	owner, update := s.Methods[0], s.Methods[1]
+	for _, tt := range []struct {
+		name string
+		msg  *MessageInfo
+		want string
+	}{
+		{"GetOwner 请求", owner.Input, "userv1"},
+		{"GetOwner 响应", owner.Output, "userv1"},
+		{"UpdateOwner 响应", update.Output, "accountpb"},
+		{"UpdateOwner 资源", update.FieldMask.Resource, "userv1"},
+	} {
+		if tt.msg.PackageName != tt.want {
+			t.Errorf("%s 的包名为 %q，期望 %q", tt.name, tt.msg.PackageName, tt.want)
+		}
+	}
+	if p := s.Methods[2].Pagination; p.ItemPackageName != "userv1" || p.ItemType != "*userv1.User" {
+		t.Errorf("ListOwners 分页结果元素为 %s（包名 %q），期望 *userv1.User", p.ItemType, p.ItemPackageName)
+	}
+}
+
+func TestPackageAlias(t *testing.T) {
+	tests := []struct {
+		importPath string
+		taken      []string
+		want       string
+	}{
+		{"example.com/order/v1", []string{"v1"}, "orderv1"},
+		{"example.com/order-api/v1", []string{"v1"}, "order_apiv1"},
+		{"example.com/order/v1", []string{"v1", "orderv1"}, "orderv12"},
+		{"example.com/order/v1", []string{"v1", "orderv1", "orderv12"}, "orderv13"},
+		{"v1", []string{"v1"}, "v12"},
+	}
+	for _, tt := range tests {
+		taken := map[string]bool{}
+		for _, name := range tt.taken {
+			taken[name] = true
+		}
+		if got := packageAlias(tt.importPath, taken); got != tt.want {
+			t.Errorf("packageAlias(%q, %v) = %q，期望 %q", tt.importPath, tt.taken, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package generator
+
+import (
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// PaginationInfo 遵循 AIP-158 分页约定的方法：请求含 page_size 与 page_token，响应含 next_page_token 与一个 repeated 结果字段
+type PaginationInfo struct {
+	ItemsField      string // 响应中存放当前页结果的字段，为响应中第一个 repeated 字段，如 orders
+	ItemsGetter     string // 取当前页结果的 Go 方法名，如 GetOrders
+	ItemType        string // 结果元素的 Go 类型，如 *order.Order、string
+	ItemImportPath  string // 结果元素为消息或枚举时其所在包的导入路径，否则为空
+	ItemPackageName string // 结果元素为消息或枚举时引用类型使用的包名，否则为空
+
+	item      protoreflect.Descriptor // 结果元素为消息或枚举时的描述，由 resolvePackageNames 确定所在文件
+	itemIdent protogen.GoIdent
+}
+
+// setItemPackageName 设置结果元素的包名并重新拼接 ItemType
+func (p *PaginationInfo) setItemPackageName(name string) {
+	p.ItemPackageName = name
+	p.ItemType = name + "." + p.itemIdent.GoName
+	if _, ok := p.item.(protoreflect.MessageDescriptor); ok {
+		p.ItemType = "*" + p.ItemType
+	}
+}
+
+// goScalarTypes 标量字段对应的 Go 类型
+var goScalarTypes = map[protoreflect.Kind]string{
+	protoreflect.BoolKind:     "bool",
+	protoreflect.Int32Kind:    "int32",
+	protoreflect.Sint32Kind:   "int32",
+	protoreflect.Sfixed32Kind: "int32",
+	protoreflect.Uint32Kind:   "uint32",
+	protoreflect.Fixed32Kind:  "uint32",
+	protoreflect.Int64Kind:    "int64",
+	protoreflect.Sint64Kind:   "int64",
+	protoreflect.Sfixed64Kind: "int64",
+	protoreflect.Uint64Kind:   "uint64",
+	protoreflect.Fixed64Kind:  "uint64",
+	protoreflect.FloatKind:    "float32",
+	protoreflect.DoubleKind:   "float64",
+	protoreflect.StringKind:   "string",
+	protoreflect.BytesKind:    "[]byte",
+}
+
+// newPagination 检测方法是否遵循 AIP-158 分页约定，不遵循或为流式方法时为 nil
+func newPagination(file *protogen.File, method *protogen.Method) *PaginationInfo {
+	if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() {
+		return nil
+	}
+	if !singularField(method.Input, "page_size", protoreflect.Int32Kind) ||
+		!singularField(method.Input, "page_token", protoreflect.StringKind) ||
+		!singularField(method.Output, "next_page_token", protoreflect.StringKind) {
+		return nil
+	}
+	for _, f := range method.Output.Fields {
+		if !f.Desc.IsList() {
+			continue
+		}
+		info := &PaginationInfo{ItemsField: string(f.Desc.Name()), ItemsGetter: "Get" + f.GoName}
+		switch {
+		case f.Message != nil:
+			info.item, info.itemIdent = f.Message.Desc, f.Message.GoIdent
+		case f.Enum != nil:
+			info.item, info.itemIdent = f.Enum.Desc, f.Enum.GoIdent
+		default:
+			info.ItemType = goScalarTypes[f.Desc.Kind()]
+			return info
+		}
+		info.ItemImportPath = string(info.itemIdent.GoImportPath)
+		name := string(file.GoPackageName)
+		if info.itemIdent.GoImportPath != file.GoImportPath {
+			name = goPackageName(info.ItemImportPath)
+		}
+		info.setItemPackageName(name)
+		return info
+	}
+	return nil
+}
+
+// singularField 消息是否有名为 name、类型为 kind 且不属于 oneof 的非 repeated 字段
+func singularField(message *protogen.Message, name string, kind protoreflect.Kind) bool {
+	for _, f := range message.Fields {
+		if string(f.Desc.Name()) == name {
+			return f.Desc.Kind() == kind && !f.Desc.IsList() && f.Desc.ContainingOneof() == nil
+		}
+	}
+	return false
+}
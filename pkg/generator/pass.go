@@ -0,0 +1,129 @@
+package generator
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// passesKey 配置文件中声明额外渲染阶段的键
+const passesKey = "passes"
+
+// passValues 解析配置文件中的 passes 列表，每一项为一组插件参数
+func passValues(v any) ([]map[string]any, error) {
+	list, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("passes 应为列表，每一项为一组插件参数")
+	}
+	passes := make([]map[string]any, len(list))
+	for i, item := range list {
+		values, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("passes 第 %d 项应为键值对", i+1)
+		}
+		passes[i] = values
+	}
+	return passes, nil
+}
+
+// buildPasses 由配置文件中的 passes 生成各渲染阶段的配置
+//
+// 每个阶段继承输出目录、包名、格式化等通用参数，但不继承要渲染的内容：
+// 主模板默认为 none，服务级模板、汇总模板、文档、报告等须在阶段中重新声明。
+func (c *PluginConfig) buildPasses() error {
+	c.Passes = nil
+	for i, values := range c.passValues {
+		pass := *c
+		pass.Template = NoTemplate
+		pass.TemplateFile = ""
+		pass.TemplateInline = ""
+		pass.Scope = scopeService
+		pass.ServiceTemplates = nil
+		pass.AggregateTemplates = nil
+		pass.AggregateTemplateFile = ""
+		pass.AggregateFile = ""
+		pass.Ops = false
+		pass.DebugServices = false
+		pass.Provenance = false
+		pass.PackageIndex = false
+		pass.DocsDir = ""
+		pass.ReportFile = ""
+		pass.AttestationFile = ""
+		pass.ManifestFile = ""
+		pass.DumpDataFile = ""
+		pass.GoMod = ""
+		pass.GoModRequires = nil
+		pass.PostHooks = nil
+		pass.Passes = nil
+		pass.passValues = nil
+		// 可重复参数在阶段中追加，避免写入基础配置的底层数组
+		pass.FuncsPlugins = slices.Clip(c.FuncsPlugins)
+		pass.NamingRules = slices.Clip(c.NamingRules)
+		pass.Formatters = maps.Clone(c.Formatters)
+		pass.Values = maps.Clone(c.Values)
+		pass.TenantMetadata = nil
+		for tenant, metadata := range c.TenantMetadata {
+			if pass.TenantMetadata == nil {
+				pass.TenantMetadata = map[string]map[string]string{}
+			}
+			pass.TenantMetadata[tenant] = maps.Clone(metadata)
+		}
+
+		if _, ok := values[passesKey]; ok {
+			return fmt.Errorf("passes 第 %d 项: 不能嵌套声明 passes", i+1)
+		}
+		if err := pass.applyConfigValues(values); err != nil {
+			return fmt.Errorf("passes 第 %d 项: %v", i+1, err)
+		}
+		if err := pass.Validate(); err != nil {
+			return fmt.Errorf("passes 第 %d 项: %v", i+1, err)
+		}
+		c.Passes = append(c.Passes, &pass)
+	}
+	return nil
+}
+
+// loadPasses 为每个渲染阶段创建生成器，阶段中模板的警告计入本生成器
+func (g *Generator) loadPasses() error {
+	for i, config := range g.config.Passes {
+		config.AllowCommands = g.config.AllowCommands
+		pass, err := New(config)
+		if err != nil {
+			return fmt.Errorf("渲染阶段 %d: %v", i+1, err)
+		}
+		pass.parent = g
+		pass.log = g.log
+		g.warnings = append(g.warnings, pass.warnings...)
+		g.passes = append(g.passes, pass)
+	}
+	return nil
+}
+
+// runPasses 在基础渲染之后依次执行各渲染阶段，输出与插入点代码汇总到本生成器
+func (g *Generator) runPasses(gen *protogen.Plugin) error {
+	var errs GenerateErrors
+	for i, pass := range g.passes {
+		err := pass.Generate(gen)
+		g.outputs = append(g.outputs, pass.outputs...)
+		g.insertions = append(g.insertions, pass.insertions...)
+		for _, t := range pass.templates {
+			if !slices.Contains(g.templates, t) {
+				g.templates = append(g.templates, t)
+			}
+		}
+		if err == nil {
+			continue
+		}
+		err = fmt.Errorf("渲染阶段 %d: %v", i+1, err)
+		if g.config.FailFast {
+			return err
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
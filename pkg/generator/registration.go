@@ -0,0 +1,50 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// 服务的注册方式，来自插件参数 registration
+const (
+	registrationLazy  = "lazy"  // 只生成显式调用的 Register 函数，同 forbid_init=true 禁止生成的代码中出现 init 函数
+	registrationEager = "eager" // 每个服务文件在 init 中登记注册函数，由 StartServices 统一注册
+)
+
+// registrarsTemplate eager 模式下登记服务注册函数的内置模板，每个输出目录生成一次
+const registrarsTemplate = "registrars"
+
+// generateRegistrars registration=eager 时在服务文件所在目录生成 registrars.go，
+// 其中定义各服务文件 init 中登记注册函数所用的 registerOnInit 与统一注册的 StartServices
+func (g *Generator) generateRegistrars(gen *protogen.Plugin, data *ServiceInfo) error {
+	if g.config.Registration != registrationEager || filepath.Ext(g.OutputPath(data)) != ".go" {
+		return nil
+	}
+	path := filepath.Join(g.outputDir(data), "registrars.go")
+	for _, f := range g.outputs {
+		if f.Path == path {
+			return nil
+		}
+	}
+
+	content, err := LoadBuiltinTemplate(registrarsTemplate)
+	if err != nil {
+		return err
+	}
+	tmpl, err := newTemplate(registrarsTemplate).Parse(content)
+	if err != nil {
+		return fmt.Errorf("解析模板 %s 失败: %v", registrarsTemplate, err)
+	}
+	g.recordTemplate(registrarsTemplate, content)
+	raw, err := execute(tmpl, data)
+	if err != nil {
+		return err
+	}
+	formatted, err := g.format(path, raw)
+	if err != nil {
+		return err
+	}
+	return g.writeFile(gen, path, formatted, nil, registrarsTemplate)
+}
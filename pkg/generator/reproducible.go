@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// stablePath reproducible=true 时将绝对路径转换为相对于当前目录的路径，
+// 不在当前目录下时只保留文件名，保证不同机器上的输出一致
+func (c *PluginConfig) stablePath(p string) string {
+	if !c.Reproducible || !filepath.IsAbs(p) {
+		return p
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, p); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.Base(p)
+}
+
+// parameter 返回写入报告、清单与模板数据导出的插件参数，reproducible=true 时其中的绝对路径按 stablePath 转换
+func (g *Generator) parameter(gen *protogen.Plugin) string {
+	param := gen.Request.GetParameter()
+	if !g.config.Reproducible {
+		return param
+	}
+	pairs := strings.Split(param, ",")
+	for i, pair := range pairs {
+		if key, value, ok := strings.Cut(pair, "="); ok {
+			pairs[i] = key + "=" + g.config.stablePath(value)
+		}
+	}
+	return strings.Join(pairs, ",")
+}
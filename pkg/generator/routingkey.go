@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// RoutingKeyInfo 方法的分片路由键
+type RoutingKeyInfo struct {
+	Field string // 路由键所在的请求字段路径，如 order.shard_key
+	Value string // 从请求 req 中取路由键的 Go 表达式，结果为 string，如 strconv.FormatInt(req.GetOrder().GetShardKey(), 10)
+
+	err error // 配置不合法，生成时报错
+}
+
+// newRoutingKey 转换 (registry.routing_key)，按请求消息 input 解析字段路径
+func newRoutingKey(path string, input *protogen.Message) *RoutingKeyInfo {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil
+	}
+	info := &RoutingKeyInfo{Field: path}
+	field, value, err := requestField(input, path)
+	if err != nil {
+		info.err = err
+		return info
+	}
+	if field.Desc.IsList() || field.Desc.IsMap() {
+		info.err = fmt.Errorf("字段 %s 须为非 repeated 的字符串、整数或枚举字段", path)
+		return info
+	}
+	switch field.Desc.Kind() {
+	case protoreflect.StringKind:
+		info.Value = value
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		info.Value = "strconv.FormatInt(int64(" + value + "), 10)"
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		info.Value = "strconv.FormatInt(" + value + ", 10)"
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		info.Value = "strconv.FormatUint(uint64(" + value + "), 10)"
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		info.Value = "strconv.FormatUint(" + value + ", 10)"
+	case protoreflect.EnumKind:
+		info.Value = value + ".String()"
+	default:
+		info.err = fmt.Errorf("字段 %s 须为非 repeated 的字符串、整数或枚举字段", path)
+	}
+	return info
+}
@@ -0,0 +1,177 @@
+package generator
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// FieldDoc 模板数据字段说明
+type FieldDoc struct {
+	Name string // 字段名，模板中以 {{.Name}} 引用
+	Type string // Go 类型
+
+	Deprecated string // 废弃说明，如 data_version=2 起废弃，改用 .Proto.File；未废弃时为空
+}
+
+// TypeDoc 模板数据中出现的结构体类型说明
+type TypeDoc struct {
+	Name   string     // 类型名
+	Fields []FieldDoc // 导出字段
+}
+
+// DataSchema 返回模板数据结构的导出字段，直接从 Go 结构体反射得到
+func DataSchema(data any) []FieldDoc {
+	t := reflect.TypeOf(data)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var docs []FieldDoc
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		doc := FieldDoc{Name: field.Name, Type: field.Type.String()}
+		if d, ok := findDeprecatedField(t.Name(), field.Name); ok {
+			doc.Deprecated = fmt.Sprintf("data_version=%d 起废弃，改用 %s", d.Since, d.Replacement)
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// DataTypes 返回 roots 及其字段中引用到的所有结构体类型，按首次出现的顺序排列
+func DataTypes(roots ...any) []TypeDoc {
+	var docs []TypeDoc
+	for _, t := range structTypes(roots...) {
+		docs = append(docs, TypeDoc{Name: t.Name(), Fields: DataSchema(reflect.New(t).Interface())})
+	}
+	return docs
+}
+
+// JSONSchema 返回 data 的 JSON Schema（draft 2020-12），引用到的结构体放在 $defs 中
+func JSONSchema(data any) map[string]any {
+	types := structTypes(data)
+	schema := objectSchema(types[0])
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = types[0].Name()
+	if len(types) > 1 {
+		defs := map[string]any{}
+		for _, t := range types[1:] {
+			defs[t.Name()] = objectSchema(t)
+		}
+		schema["$defs"] = defs
+	}
+	return schema
+}
+
+// objectSchema 生成结构体的 object 描述
+func objectSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		schema := typeSchema(field.Type)
+		if d, ok := findDeprecatedField(t.Name(), field.Name); ok {
+			schema["deprecated"] = true
+			schema["description"] = fmt.Sprintf("data_version=%d 起废弃，改用 %s", d.Since, d.Replacement)
+		}
+		properties[field.Name] = schema
+	}
+	return map[string]any{"type": "object", "properties": properties}
+}
+
+// typeSchema 将 Go 类型映射为 JSON Schema，x-go-type 记录原始 Go 类型
+//
+// 指针、切片与 map 的零值编码为 null，因此同时允许 null。
+func typeSchema(t reflect.Type) map[string]any {
+	schema := valueTypeSchema(t)
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map:
+		if ref, ok := schema["$ref"]; ok {
+			delete(schema, "$ref")
+			schema["anyOf"] = []any{map[string]any{"$ref": ref}, map[string]any{"type": "null"}}
+		} else if typ, ok := schema["type"]; ok {
+			schema["type"] = []any{typ, "null"}
+		}
+	}
+	return schema
+}
+
+// valueTypeSchema 返回非 null 值的 JSON Schema
+func valueTypeSchema(t reflect.Type) map[string]any {
+	schema := map[string]any{"x-go-type": t.String()}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		schema["type"] = "integer"
+		schema["description"] = "time.Duration，模板中可直接调用 .String 等方法"
+		return schema
+	case t.Kind() == reflect.Struct:
+		schema["$ref"] = "#/$defs/" + t.Name()
+		return schema
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		schema["type"] = "string"
+	case reflect.Bool:
+		schema["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema["type"] = "integer"
+	case reflect.Float32, reflect.Float64:
+		schema["type"] = "number"
+	case reflect.Slice, reflect.Array:
+		schema["type"] = "array"
+		schema["items"] = typeSchema(t.Elem())
+	case reflect.Map:
+		schema["type"] = "object"
+		schema["additionalProperties"] = typeSchema(t.Elem())
+	}
+	return schema
+}
+
+// structType 去掉指针、切片与 map，返回其中的结构体类型，不是结构体时返回 nil
+func structType(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		case reflect.Struct:
+			return t
+		default:
+			return nil
+		}
+	}
+}
+
+// structTypes 返回 roots 及其导出字段中引用到的结构体类型，按首次出现的顺序排列
+func structTypes(roots ...any) []reflect.Type {
+	var types []reflect.Type
+	seen := map[reflect.Type]bool{}
+	var visit func(t reflect.Type)
+	visit = func(t reflect.Type) {
+		t = structType(t)
+		if t == nil || seen[t] {
+			return
+		}
+		seen[t] = true
+		types = append(types, t)
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() {
+				visit(t.Field(i).Type)
+			}
+		}
+	}
+	for _, root := range roots {
+		visit(reflect.TypeOf(root))
+	}
+	return types
+}
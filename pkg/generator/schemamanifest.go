@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// SchemaModule schema_manifest.yaml 汇总模板中的一个 schema registry 模块
+type SchemaModule struct {
+	Name     string         // 模块名称，如 buf.build/acme/payments
+	Labels   []string       // 推送时附加的标签：各服务的版本号与 (registry.schema_labels)，按首次出现的顺序去重
+	Files    []string       // 模块中定义了服务的 proto 文件，按路径排序
+	Services []*ServiceInfo // 模块中的服务，按注册顺序排列
+}
+
+// checkSchemaLabels 检查 (registry.schema_labels) 中的标签非空、不含空白且不重复
+func checkSchemaLabels(s *ServiceInfo) error {
+	for i, label := range s.SchemaLabels {
+		if label == "" || strings.ContainsFunc(label, unicode.IsSpace) {
+			return fmt.Errorf("服务 %s 的 (registry.schema_labels) 第 %d 项为空或包含空白: %q", s.FullName, i+1, label)
+		}
+		if slices.Contains(s.SchemaLabels[:i], label) {
+			return fmt.Errorf("服务 %s 的 (registry.schema_labels) 重复声明了标签 %s", s.FullName, label)
+		}
+	}
+	return nil
+}
+
+// SchemaModules 返回服务推送到的 schema registry 模块，按首次出现的顺序排列，未指定模块的服务不包含在内
+func (a *AggregateInfo) SchemaModules() []*SchemaModule {
+	var modules []*SchemaModule
+	byName := make(map[string]*SchemaModule)
+	for _, s := range a.Services {
+		if s.SchemaModule == "" {
+			continue
+		}
+		m, ok := byName[s.SchemaModule]
+		if !ok {
+			m = &SchemaModule{Name: s.SchemaModule}
+			byName[s.SchemaModule] = m
+			modules = append(modules, m)
+		}
+		m.Services = append(m.Services, s)
+		if !slices.Contains(m.Files, s.ProtoFile) {
+			m.Files = append(m.Files, s.ProtoFile)
+		}
+		for _, label := range append([]string{s.Version}, s.SchemaLabels...) {
+			if label != "" && !slices.Contains(m.Labels, label) {
+				m.Labels = append(m.Labels, label)
+			}
+		}
+	}
+	for _, m := range modules {
+		slices.Sort(m.Files)
+	}
+	return modules
+}
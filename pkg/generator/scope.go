@@ -0,0 +1,165 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// 主模板的渲染范围，来自插件参数 scope
+const (
+	scopeService = "service" // 每个服务渲染一次，数据为 ServiceInfo
+	scopeFile    = "file"    // 每个 proto 文件渲染一次，数据为 FileInfo
+	scopeAll     = "all"     // 每次生成渲染一次，数据为 AggregateInfo，其中 Files 列出全部 proto 文件
+)
+
+// FileInfo scope=file 时主模板的渲染数据，对应一个 proto 文件
+type FileInfo struct {
+	PackageName   string            // 生成的包名
+	ProtoFile     string            // proto 文件路径，如 user/user.proto
+	ProtoPackage  string            // proto 包名，如 user
+	GoPackageName string            // proto 文件的 Go 包名
+	GoImportPath  string            // proto 文件的 Go 导入路径
+	Options       map[string]string // 显式设置的文件选项，如 go_package；扩展选项的键带括号，如 (foo.bar)
+	Comment       string            // syntax 声明前的注释，已去掉注释符号
+	Values        map[string]string // 注入模板的常量，来自插件参数 set 与配置文件的 values
+
+	Services []*ServiceInfo // 文件中生成成功的服务，按声明顺序排列
+	Messages []*MessageType // 文件中定义的消息（含嵌套消息），Name 为 Go 类型名
+	Enums    []*EnumType    // 文件中定义的枚举（含嵌套枚举），Name 为 Go 类型名
+}
+
+// NewFileInfo 从 proto 文件提取 scope=file 的模板数据，services 为文件中需要生成的服务
+func NewFileInfo(file *protogen.File, services []*ServiceInfo, config *PluginConfig) *FileInfo {
+	info := &FileInfo{
+		PackageName:   config.PackageName,
+		ProtoFile:     file.Desc.Path(),
+		ProtoPackage:  string(file.Desc.Package()),
+		GoPackageName: string(file.GoPackageName),
+		GoImportPath:  string(file.GoImportPath),
+		Options:       map[string]string{},
+		Comment:       commentText(protogen.Comments(file.Desc.SourceLocations().ByPath(protoreflect.SourcePath{12}).LeadingComments)),
+		Services:      services,
+		Values:        newValues(config),
+	}
+	file.Desc.Options().ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := string(fd.Name())
+		if fd.IsExtension() {
+			name = "(" + string(fd.FullName()) + ")"
+		}
+		info.Options[name] = v.String()
+		return true
+	})
+
+	var visitEnum func(e *protogen.Enum)
+	visitEnum = func(e *protogen.Enum) {
+		t := &EnumType{Name: e.GoIdent.GoName, FullName: string(e.Desc.FullName())}
+		for _, v := range e.Values {
+			t.Values = append(t.Values, string(v.Desc.Name()))
+		}
+		info.Enums = append(info.Enums, t)
+	}
+	var visitMessage func(m *protogen.Message)
+	visitMessage = func(m *protogen.Message) {
+		t := &MessageType{Name: m.GoIdent.GoName, FullName: string(m.Desc.FullName()), MapEntry: m.Desc.IsMapEntry()}
+		for _, f := range m.Fields {
+			t.Fields = append(t.Fields, fileFieldInfo(f))
+		}
+		info.Messages = append(info.Messages, t)
+		for _, e := range m.Enums {
+			visitEnum(e)
+		}
+		for _, nested := range m.Messages {
+			visitMessage(nested)
+		}
+	}
+	for _, e := range file.Enums {
+		visitEnum(e)
+	}
+	for _, m := range file.Messages {
+		visitMessage(m)
+	}
+	return info
+}
+
+// fileFieldInfo 提取字段信息，引用的消息与枚举以 Go 类型名作为 TypeName
+func fileFieldInfo(f *protogen.Field) *FieldInfo {
+	info := newFieldInfo(f)
+	info.TypeName = fieldGoType(f)
+	if f.Desc.IsMap() {
+		info.MapValue.TypeName = fieldGoType(f.Message.Fields[1])
+	}
+	return info
+}
+
+// fieldGoType 返回消息与枚举字段引用的 Go 类型名，其他字段为空
+func fieldGoType(f *protogen.Field) string {
+	switch {
+	case f.Message != nil:
+		return f.Message.GoIdent.GoName
+	case f.Enum != nil:
+		return f.Enum.GoIdent.GoName
+	}
+	return ""
+}
+
+// fileOutputPath 返回 scope=file 时 proto 文件对应的输出路径，以 _ 连接目录避免同名文件冲突，
+// 如 order/v1/order.proto -> order_v1_order.go
+func (g *Generator) fileOutputPath(protoFile string) string {
+	name := strings.ReplaceAll(strings.TrimSuffix(protoFile, ".proto"), "/", "_")
+	return filepath.Join(g.config.OutputDir, name+g.config.OutputExt)
+}
+
+// allOutputPath 返回 scope=all 时的输出路径，文件名取自模板文件名，如 bootstrap.tmpl -> bootstrap.go、
+// mesh.yaml.tmpl -> mesh.yaml；template_inline 时为 service_registry.go
+func (g *Generator) allOutputPath() string {
+	name := "service_registry"
+	if g.config.TemplateInline == "" {
+		name = strings.TrimSuffix(filepath.Base(g.config.TemplateFile), ".tmpl")
+	}
+	if filepath.Ext(name) == "" {
+		name += g.config.OutputExt
+	}
+	return filepath.Join(g.config.OutputDir, name)
+}
+
+// generateAll scope=all 时以 AggregateInfo 渲染一次主模板
+func (g *Generator) generateAll(gen *protogen.Plugin, data *AggregateInfo) error {
+	raw, err := execute(g.tmpl, data)
+	if err != nil {
+		return err
+	}
+	path := g.allOutputPath()
+	formatted, err := g.format(path, raw)
+	if err != nil {
+		return err
+	}
+	return g.writeFile(gen, path, formatted, nil, g.source)
+}
+
+// generateFile scope=file 时以 FileInfo 渲染主模板，渲染结果为空时不生成文件
+func (g *Generator) generateFile(gen *protogen.Plugin, data *FileInfo) error {
+	raw, err := execute(g.tmpl, data)
+	if err != nil {
+		return fmt.Errorf("文件 %s: %v", data.ProtoFile, err)
+	}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		g.log.Info("模板渲染结果为空，不生成文件", "file", data.ProtoFile, "template", g.source)
+		return nil
+	}
+	path := g.fileOutputPath(data.ProtoFile)
+	formatted, err := g.format(path, raw)
+	if err != nil {
+		return fmt.Errorf("文件 %s: %v", data.ProtoFile, err)
+	}
+	if err := g.writeFile(gen, path, formatted, nil, g.source); err != nil {
+		return err
+	}
+	// 按来源 proto 文件记录，manifest 中归入对应输入
+	g.outputs[len(g.outputs)-1].Proto = data.ProtoFile
+	return nil
+}
@@ -0,0 +1,446 @@
+package generator
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/lhdbsbz/protoc-gen-service-registry/registry"
+)
+
+// ServiceInfo 服务信息结构体，用于模板渲染
+type ServiceInfo struct {
+	PackageName      string // 生成的包名
+	ServiceName      string // 服务名称
+	GoName           string // 生成代码中使用的标识符前缀，通常与 ServiceName 相同，多版本服务会追加版本号，如 OrderV1
+	FullName         string // proto 服务全限定名，如 pages.prepare_order.PrepareOrderService
+	ProtoPackageName string // proto包名（用于代码中的类型引用，如 prepare_order.PrepareOrderServiceServer），与其他 proto 包同名时为由导入路径生成的别名，导入时须写明
+	ProtoImportPath  string // proto导入路径（完整路径，用于 import 语句，如 git.dreame.tech/.../gen/proto/pages/prepare_order）
+	ProtoFile        string // 定义服务的 proto 文件路径，如 pages/prepare_order/prepare_order.proto
+	JavaGRPCClass    string // grpc-java 生成的服务类全限定名，包名取 java_package，未声明时为 proto 包名，如 pages.prepare_order.PrepareOrderServiceGrpc
+
+	// protoc-gen-go-grpc 在 ProtoPackageName 包中生成的标识符，模板应直接引用而不是由 ServiceName 拼接
+	ServerInterface   string // 服务端接口，如 OrderServiceServer
+	ClientInterface   string // 客户端接口，如 OrderServiceClient
+	RegisterFunc      string // 注册服务实现的函数，如 RegisterOrderServiceServer
+	UnimplementedType string // 服务端接口的默认实现，如 UnimplementedOrderServiceServer
+	ServiceDesc       string // grpc.ServiceDesc 变量，如 OrderService_ServiceDesc
+	NewClientFunc     string // 创建客户端的函数，如 NewOrderServiceClient
+	// HasGRPCStubs 上述标识符是否存在，由插件参数 grpc_stubs 决定；为 false 的服务只在
+	// grpc_stubs_missing=render 时渲染，模板须据此跳过对 gRPC 代码的引用
+	HasGRPCStubs bool
+
+	Proto *ProtoInfo // 服务所在 proto 文件与 Go 包，data_version=2 起可用，data_version=1 时为 nil
+
+	RegisteredName string // 对外注册的服务名称，来自 (registry.name)，未指定时为 GoName 的 kebab-case 形式
+
+	Group     string // 服务分组，来自 (registry.group)，未指定时为 proto 包名的第一段，如 pages.prepare_order -> pages
+	Namespace string // 命名空间，来自 (registry.namespace)，未指定时为完整的 proto 包名
+
+	Version       string // 从 proto 包名识别出的版本号，如 pages.order.v2 -> v2，未识别时为空
+	LatestVersion string // 同一逻辑服务的最新版本号，仅存在多个版本时非空
+	Deprecated    bool   // 服务已废弃：声明了 deprecated = true，或存在更新的版本
+
+	Weight    int32    // 服务权重，来自 (registry.weight)
+	Priority  int32    // 注册优先级，来自 (registry.priority)，数值越小越先注册
+	DependsOn []string // 依赖的服务，来自 (registry.depends_on)
+
+	DependsOnClients []ClientDependency // 启动时须先就绪的下游服务，来自 (registry.depends_on_clients)，按声明顺序排列
+
+	AllowedCallers []string // 允许调用该服务的服务的注册名称，来自 (registry.allowed_callers)，* 表示任意服务
+
+	SPIFFEID          string // 服务的 SPIFFE ID，来自 (registry.spiffe_id)，未指定时为 spiffe://<信任域>/ns/<命名空间>/sa/<注册名称>
+	SPIFFETrustDomain string // SPIFFEID 的信任域，(registry.spiffe_id) 未写信任域时来自插件参数 spiffe_trust_domain
+
+	SchemaModule string   // 推送到 schema registry 的模块，来自 (registry.schema_module) 或插件参数 schema_module，为空时不推送
+	SchemaLabels []string // 推送模块时附加的标签，来自 (registry.schema_labels)，按声明顺序排列
+
+	EnabledWhen string // 启用条件，来自 (registry.enabled_when)
+	EnabledFunc string // 判断启用条件的函数名，如 prepareOrderEnabled，仅在 EnabledWhen 非空时有效
+	FeatureFlag string // 功能开关名称，来自 (registry.feature_flag)，开关关闭时不注册服务
+
+	Middleware []string // 服务端拦截器名称，来自 (registry.middleware)，按拦截器链的执行顺序排列
+
+	HeartbeatInterval time.Duration // 服务中心心跳间隔，来自插件参数 heartbeat_interval
+
+	Registration string // 注册方式，来自插件参数 registration: lazy、eager 或空
+
+	LoadBalancing string // 客户端负载均衡策略，来自 (registry.load_balancing)，默认 round_robin
+	ServiceConfig string // 由负载均衡策略生成的 gRPC 服务配置 JSON，用于 grpc.WithDefaultServiceConfig
+	ConnPoolSize  int    // conn_manager 汇总模板中的连接数，来自 (registry.conn_pool_size) 或插件参数 conn_pool_size
+
+	Tags     []string          // 服务标签，来自 (registry.tags)
+	Metadata map[string]string // 服务元数据，来自 (registry.metadata)
+
+	Locality *LocalityInfo // 实例的区域与可用区，来自插件参数 region 与 zone，都未指定时为 nil
+
+	Tenants []*TenantInfo // 服务在各租户下的注册信息，按插件参数 tenants 的顺序排列，未指定租户时为空
+
+	Values map[string]string // 注入模板的常量，来自插件参数 set 与配置文件的 values，未设置的键可用 {{or .Values.key "默认值"}} 提供默认值
+
+	Methods []*MethodInfo // 服务方法，按声明顺序排列
+
+	GRPCWebOrigins []string // 允许通过 gRPC-Web 访问的来源，来自 (registry.grpc_web_origins)，非空时启用 gRPC-Web
+	Twirp          bool     // 服务同时生成了 Twirp 代码，来自 (registry.twirp)
+
+	GRPCPort  int32  // 服务监听的 gRPC 端口，来自 (registry.grpc_port)，未指定时为 0
+	DNSDomain string // 集群域名，来自插件参数 dns_domain，istio.yaml 等模板以此拼接服务的域名
+
+	DubboInterface string // Dubbo 注册中心中的接口名，来自 (registry.dubbo_interface)，未指定时为 FullName
+	DubboVersion   string // Dubbo 注册中心中的版本，来自 (registry.dubbo_version)，未指定时为 Version
+
+	Comment string   // 服务的前置注释，已去掉注释符号
+	Owners  []string // 服务负责人，来自 (registry.owner)
+	OnCall  string   // 值班联系方式，来自 (registry.oncall)
+
+	pos            string   // 服务在 proto 文件中的位置，用于警告
+	outputDir      string   // package_mode=per_proto 时服务文件的输出目录，为空时使用 output_dir
+	logicalName    string   // 去掉版本号后的服务全限定名，用于识别同一服务的不同版本
+	nameOverridden bool     // RegisteredName 是否来自 (registry.name)
+	clientDeps     []string // (registry.depends_on_clients) 中声明的服务名，由 resolveClientDependencies 解析
+	callers        []string // (registry.allowed_callers) 中声明的服务名，由 resolveAllowedCallers 解析
+	spiffeID       string   // (registry.spiffe_id) 的原始值，由 resolveSPIFFEID 解析
+	goPackage      string   // proto 文件的 Go 包名，ProtoPackageName 可能是 resolvePackageNames 设置的别名
+	template       string   // (registry.template) 为服务指定的内置模板，未指定时为空
+}
+
+// NewServiceInfo 从 proto 文件与服务定义中提取模板数据
+func NewServiceInfo(file *protogen.File, service *protogen.Service, config *PluginConfig) *ServiceInfo {
+	// 服务名称（去掉 Service 后缀）
+	serviceName := strings.TrimSuffix(string(service.Desc.Name()), "Service")
+
+	registeredName := serviceOption[string](service, registry.E_Name)
+
+	protoPackage := string(file.Desc.Package())
+
+	group := serviceOption[string](service, registry.E_Group)
+	if group == "" {
+		group, _, _ = strings.Cut(protoPackage, ".")
+	}
+
+	namespace := serviceOption[string](service, registry.E_Namespace)
+	if namespace == "" {
+		namespace = protoPackage
+	}
+
+	loadBalancing := serviceOption[string](service, registry.E_LoadBalancing)
+	if loadBalancing == "" {
+		loadBalancing = "round_robin"
+	}
+
+	version, logicalPackage := detectVersion(protoPackage)
+	deprecated := false
+	if opts, ok := service.Desc.Options().(*descriptorpb.ServiceOptions); ok {
+		deprecated = opts.GetDeprecated()
+	}
+
+	info := &ServiceInfo{
+		PackageName:    config.PackageName,
+		ServiceName:    serviceName,
+		GoName:         serviceName,
+		FullName:       string(service.Desc.FullName()),
+		RegisteredName: registeredName,
+		// 使用 protogen 解析的包名（用于代码中的类型引用）
+		ProtoPackageName: string(file.GoPackageName),
+		// 获取完整的导入路径（支持嵌套目录）
+		ProtoImportPath: string(file.GoImportPath),
+		ProtoFile:       file.Desc.Path(),
+		JavaGRPCClass:   javaGRPCClass(file, service),
+
+		ServerInterface:   service.GoName + "Server",
+		ClientInterface:   service.GoName + "Client",
+		RegisterFunc:      "Register" + service.GoName + "Server",
+		UnimplementedType: "Unimplemented" + service.GoName + "Server",
+		ServiceDesc:       service.GoName + "_ServiceDesc",
+		NewClientFunc:     "New" + service.GoName + "Client",
+
+		Group:       group,
+		Namespace:   namespace,
+		Version:     version,
+		Deprecated:  deprecated,
+		Weight:      serviceOption[int32](service, registry.E_Weight),
+		Priority:    serviceOption[int32](service, registry.E_Priority),
+		DependsOn:   serviceOption[[]string](service, registry.E_DependsOn),
+		EnabledWhen: serviceOption[string](service, registry.E_EnabledWhen),
+		FeatureFlag: strings.TrimSpace(serviceOption[string](service, registry.E_FeatureFlag)),
+		Middleware:  serviceMiddleware(service),
+
+		HeartbeatInterval: config.HeartbeatInterval,
+		Registration:      config.Registration,
+
+		LoadBalancing: loadBalancing,
+		ServiceConfig: fmt.Sprintf(`{"loadBalancingConfig":[{%q:{}}]}`, loadBalancing),
+		ConnPoolSize:  connPoolSize(service, config),
+
+		Tags:     serviceOption[[]string](service, registry.E_Tags),
+		Metadata: serviceMetadata(service),
+		Tenants:  newTenants(config),
+		Locality: newLocality(config),
+		Values:   newValues(config),
+
+		Methods: newMethods(file, service),
+
+		GRPCWebOrigins: serviceOption[[]string](service, registry.E_GrpcWebOrigins),
+		Twirp:          serviceOption[bool](service, registry.E_Twirp),
+
+		GRPCPort:  serviceOption[int32](service, registry.E_GrpcPort),
+		DNSDomain: config.DNSDomain,
+
+		SPIFFETrustDomain: config.SPIFFETrustDomain,
+
+		SchemaModule: cmp.Or(strings.TrimSpace(serviceOption[string](service, registry.E_SchemaModule)), config.SchemaModule),
+		SchemaLabels: trimAll(serviceOption[[]string](service, registry.E_SchemaLabels)),
+
+		DubboInterface: cmp.Or(serviceOption[string](service, registry.E_DubboInterface), string(service.Desc.FullName())),
+		DubboVersion:   cmp.Or(serviceOption[string](service, registry.E_DubboVersion), version),
+
+		Comment: commentText(service.Comments.Leading),
+		Owners:  serviceOption[[]string](service, registry.E_Owner),
+		OnCall:  serviceOption[string](service, registry.E_Oncall),
+
+		pos:            sourcePosition(service.Desc),
+		logicalName:    logicalPackage + "." + string(service.Desc.Name()),
+		nameOverridden: registeredName != "",
+		clientDeps:     serviceOption[[]string](service, registry.E_DependsOnClients),
+		callers:        serviceOption[[]string](service, registry.E_AllowedCallers),
+		spiffeID:       strings.TrimSpace(serviceOption[string](service, registry.E_SpiffeId)),
+		goPackage:      string(file.GoPackageName),
+		template:       serviceOption[string](service, registry.E_Template),
+	}
+	info.setGoName(serviceName)
+	applyDataVersion(info, config.DataVersion, protoPackage)
+	applyPackageMode(info, file, config)
+	return info
+}
+
+// connPoolSize 返回服务的连接数，(registry.conn_pool_size) 未声明或不是正数时使用插件参数
+func connPoolSize(service *protogen.Service, config *PluginConfig) int {
+	if size := serviceOption[int32](service, registry.E_ConnPoolSize); size > 0 {
+		return int(size)
+	}
+	return config.ConnPoolSize
+}
+
+// javaGRPCClass 返回 grpc-java 为服务生成的类的全限定名
+func javaGRPCClass(file *protogen.File, service *protogen.Service) string {
+	pkg := string(file.Desc.Package())
+	if opts, ok := file.Desc.Options().(*descriptorpb.FileOptions); ok && opts.GetJavaPackage() != "" {
+		pkg = opts.GetJavaPackage()
+	}
+	class := string(service.Desc.Name()) + "Grpc"
+	if pkg == "" {
+		return class
+	}
+	return pkg + "." + class
+}
+
+// setGoName 设置标识符前缀，并同步更新由其推导出的字段
+func (s *ServiceInfo) setGoName(goName string) {
+	s.GoName = goName
+	if !s.nameOverridden {
+		s.RegisteredName = toKebabCase(goName)
+	}
+	for _, t := range s.Tenants {
+		t.RegisteredName = t.Name + "-" + s.RegisteredName
+	}
+	if s.EnabledWhen != "" {
+		s.EnabledFunc = toCamelCase(goName) + "Enabled"
+	}
+}
+
+// HasHTTP 是否有方法声明了 google.api.http 路由
+func (s *ServiceInfo) HasHTTP() bool {
+	for _, m := range s.Methods {
+		if len(m.HTTP) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// HasHTTPRules 同 HasHTTP，与 HasStreaming 等命名一致
+func (s *ServiceInfo) HasHTTPRules() bool {
+	return s.HasHTTP()
+}
+
+// HasStreaming 是否有客户端流式或服务端流式方法
+func (s *ServiceInfo) HasStreaming() bool {
+	for _, m := range s.Methods {
+		if m.ClientStreaming || m.ServerStreaming {
+			return true
+		}
+	}
+	return false
+}
+
+// HasUnary 是否有一元方法
+func (s *ServiceInfo) HasUnary() bool {
+	for _, m := range s.Methods {
+		if !m.ClientStreaming && !m.ServerStreaming {
+			return true
+		}
+	}
+	return false
+}
+
+// HasDeprecatedMethods 是否有方法声明了 deprecated = true
+func (s *ServiceInfo) HasDeprecatedMethods() bool {
+	for _, m := range s.Methods {
+		if m.Deprecated {
+			return true
+		}
+	}
+	return false
+}
+
+// HasCircuitBreaker 是否有方法声明了客户端熔断策略
+func (s *ServiceInfo) HasCircuitBreaker() bool {
+	for _, m := range s.Methods {
+		if m.CircuitBreaker != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// HasMethodFeatureFlags 是否有方法声明了 (registry.method_feature_flag)
+func (s *ServiceInfo) HasMethodFeatureFlags() bool {
+	for _, m := range s.Methods {
+		if m.FeatureFlag != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAudit 是否有方法声明了 (registry.audit) 或 (registry.audit_resource)
+func (s *ServiceInfo) HasAudit() bool {
+	for _, m := range s.Methods {
+		if m.Audit != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTenantField 是否有方法声明了 (registry.tenant_field)
+func (s *ServiceInfo) HasTenantField() bool {
+	for _, m := range s.Methods {
+		if m.TenantField != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesFeatureFlags 服务或其方法是否声明了功能开关
+func (s *ServiceInfo) UsesFeatureFlags() bool {
+	return s.FeatureFlag != "" || s.HasMethodFeatureFlags()
+}
+
+// featureFlagsTemplate 功能开关汇总模板，内置服务模板中的开关判断依赖其中的函数
+const featureFlagsTemplate = "feature_flags"
+
+// checkFeatureFlags 服务声明了功能开关，但基础配置与各渲染阶段都未启用 feature_flags 汇总模板时：
+// 服务使用的内置模板（包括 (registry.template) 指定的）引用的 FeatureEnabled 不存在，生成的代码无法编译，返回错误；
+// 自定义模板只提示，开关判断函数须自行提供
+func (g *Generator) checkFeatureFlags(s *ServiceInfo) error {
+	if !s.UsesFeatureFlags() || g.usesAggregateTemplate(featureFlagsTemplate) {
+		return nil
+	}
+	if g.usesBuiltinTemplate(s) {
+		return fmt.Errorf("服务 %s 声明了功能开关，内置服务模板依赖 feature_flags 汇总模板中的 FeatureEnabled，须启用 aggregate_template=feature_flags", s.FullName)
+	}
+	g.warn(s.pos, "服务声明了功能开关，但未启用 feature_flags 汇总模板，开关判断函数须自行提供", "service", s.FullName)
+	return nil
+}
+
+// UnaryInputImports 返回一元方法请求消息所在、且不是服务所在包的 Go 包，按首次出现的顺序去重
+func (s *ServiceInfo) UnaryInputImports() []ImportInfo {
+	var imports []ImportInfo
+	seen := map[string]bool{s.ProtoImportPath: true}
+	for _, m := range s.Methods {
+		if m.ClientStreaming || m.ServerStreaming || seen[m.Input.ImportPath] {
+			continue
+		}
+		seen[m.Input.ImportPath] = true
+		imports = append(imports, ImportInfo{Name: m.Input.PackageName, Path: m.Input.ImportPath})
+	}
+	return imports
+}
+
+// commentText 去掉 proto 注释每行开头的空格，首尾空行一并去掉
+func commentText(c protogen.Comments) string {
+	lines := strings.Split(strings.TrimSpace(string(c)), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(strings.TrimRight(line, " \t"), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// checkTwirp 校验声明了 (registry.twirp) 的服务，Twirp 只支持一元方法
+func checkTwirp(s *ServiceInfo) error {
+	if !s.Twirp {
+		return nil
+	}
+	for _, m := range s.Methods {
+		if m.ClientStreaming || m.ServerStreaming {
+			return fmt.Errorf("服务 %s 声明了 (registry.twirp)，但 Twirp 不支持流式方法 %s", s.FullName, m.Name)
+		}
+	}
+	return nil
+}
+
+// middlewareTemplate 拦截器链汇总模板，内置服务模板中的拦截器查找依赖其中的函数
+const middlewareTemplate = "middleware"
+
+// checkMiddleware 检查 (registry.middleware) 中的拦截器名称非空且不重复
+//
+// 基础配置与各渲染阶段都未启用 middleware 汇总模板时：服务使用的内置模板（包括 (registry.template) 指定的）
+// 引用的 CheckMiddleware 与 UnaryMiddleware 不存在，生成的代码无法编译，返回错误；自定义模板只提示
+func (g *Generator) checkMiddleware(s *ServiceInfo) error {
+	for i, name := range s.Middleware {
+		if name == "" {
+			return fmt.Errorf("服务 %s 的 (registry.middleware) 第 %d 项为空", s.FullName, i+1)
+		}
+		if slices.Contains(s.Middleware[:i], name) {
+			return fmt.Errorf("服务 %s 的 (registry.middleware) 重复声明了拦截器 %s", s.FullName, name)
+		}
+	}
+	if len(s.Middleware) == 0 || g.usesAggregateTemplate(middlewareTemplate) {
+		return nil
+	}
+	if g.usesBuiltinTemplate(s) {
+		return fmt.Errorf("服务 %s 声明了拦截器链，内置服务模板依赖 middleware 汇总模板中的 CheckMiddleware 与 UnaryMiddleware，须启用 aggregate_template=middleware", s.FullName)
+	}
+	g.warn(s.pos, "服务声明了拦截器链，但未启用 middleware 汇总模板，拦截器查找函数须自行提供", "service", s.FullName)
+	return nil
+}
+
+// serviceMiddleware 返回 (registry.middleware) 声明的拦截器名称，去除首尾空白
+func serviceMiddleware(service *protogen.Service) []string {
+	return trimAll(serviceOption[[]string](service, registry.E_Middleware))
+}
+
+// trimAll 去掉每一项首尾的空白
+func trimAll(items []string) []string {
+	var trimmed []string
+	for _, item := range items {
+		trimmed = append(trimmed, strings.TrimSpace(item))
+	}
+	return trimmed
+}
+
+// serviceMetadata 将 (registry.metadata) 键值对转换为 map，重复的 key 以后声明的为准
+func serviceMetadata(service *protogen.Service) map[string]string {
+	metadata := make(map[string]string)
+	for _, entry := range serviceOption[[]*registry.MetadataEntry](service, registry.E_Metadata) {
+		metadata[entry.GetKey()] = entry.GetValue()
+	}
+	return metadata
+}
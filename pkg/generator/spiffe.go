@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultSPIFFETrustDomain 默认的信任域，与 Istio 默认的信任域一致
+const defaultSPIFFETrustDomain = "cluster.local"
+
+// spiffeScheme SPIFFE ID 的 URI 前缀
+const spiffeScheme = "spiffe://"
+
+// spiffeIDChars 返回 s 中的字符是否都是 SPIFFE ID 允许的字符：字母数字与 .、-、_，信任域只允许小写字母
+func spiffeIDChars(s string, lower bool) bool {
+	for _, c := range []byte(s) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '.', c == '-', c == '_':
+		case c >= 'A' && c <= 'Z' && !lower:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// parseSPIFFETrustDomain 解析 spiffe_trust_domain 参数
+func parseSPIFFETrustDomain(v string) (string, error) {
+	if v == "" || !spiffeIDChars(v, true) {
+		return "", fmt.Errorf("spiffe_trust_domain 只能由小写字母、数字与 .、-、_ 组成: %s", v)
+	}
+	return v, nil
+}
+
+// parseSPIFFEID 解析 (registry.spiffe_id)，只写路径时使用信任域 trustDomain，返回完整的 SPIFFE ID 与其信任域
+func parseSPIFFEID(v, trustDomain string) (id, td string, err error) {
+	td, path := trustDomain, v
+	if rest, ok := strings.CutPrefix(v, spiffeScheme); ok {
+		td, path, _ = strings.Cut(rest, "/")
+		path = "/" + path
+		if _, err := parseSPIFFETrustDomain(td); err != nil {
+			return "", "", fmt.Errorf("SPIFFE ID %s 的信任域不合法，只能由小写字母、数字与 .、-、_ 组成", v)
+		}
+	} else if !strings.HasPrefix(v, "/") {
+		return "", "", fmt.Errorf("SPIFFE ID 应为 spiffe://<信任域>/<路径> 或以 / 开头的路径: %s", v)
+	}
+	if path == "/" {
+		return "", "", fmt.Errorf("SPIFFE ID %s 缺少工作负载路径", v)
+	}
+	for _, seg := range strings.Split(path[1:], "/") {
+		if seg == "" || seg == "." || seg == ".." || !spiffeIDChars(seg, false) {
+			return "", "", fmt.Errorf("SPIFFE ID %s 的路径段 %q 不合法，只能由字母、数字与 .、-、_ 组成且不能为空、. 或 ..", v, seg)
+		}
+	}
+	return spiffeScheme + td + path, td, nil
+}
+
+// resolveSPIFFEID 计算服务的 SPIFFE ID，须在注册名称确定后调用
+func resolveSPIFFEID(s *ServiceInfo) error {
+	if s.spiffeID != "" {
+		id, td, err := parseSPIFFEID(s.spiffeID, s.SPIFFETrustDomain)
+		if err != nil {
+			return fmt.Errorf("服务 %s 的 (registry.spiffe_id) 不合法: %v", s.FullName, err)
+		}
+		s.SPIFFEID, s.SPIFFETrustDomain = id, td
+		return nil
+	}
+	namespace, err := dnsLabel(s.Namespace)
+	if err != nil {
+		return fmt.Errorf("服务 %s 无法生成 SPIFFE ID: %v", s.FullName, err)
+	}
+	account, err := dnsLabel(s.RegisteredName)
+	if err != nil {
+		return fmt.Errorf("服务 %s 无法生成 SPIFFE ID: %v", s.FullName, err)
+	}
+	s.SPIFFEID = spiffeScheme + s.SPIFFETrustDomain + "/ns/" + namespace + "/sa/" + account
+	return nil
+}
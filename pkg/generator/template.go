@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"embed"
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// builtinTemplates 内置模板，按文件名（去掉 .tmpl 后缀）引用
+//
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// NoTemplate 不使用服务模板，用于只生成汇总文件等非 Go 输出的插件调用
+const NoTemplate = "none"
+
+// LoadTemplate 加载模板内容，优先级: template_inline > template_file > template
+//
+// template 为 none 时返回空内容。
+func LoadTemplate(config *PluginConfig) (string, error) {
+	if config.TemplateInline != "" {
+		content, err := base64.StdEncoding.DecodeString(config.TemplateInline)
+		if err != nil {
+			return "", fmt.Errorf("template_inline 不是合法的 base64 内容: %v", err)
+		}
+		return string(content), nil
+	}
+
+	if config.TemplateFile == "" {
+		if config.Template == NoTemplate {
+			return "", nil
+		}
+		return LoadBuiltinTemplate(config.Template)
+	}
+	return loadTemplateFile(config.TemplateFile)
+}
+
+// usesBuiltinTemplate 服务模板是否为内置模板，template=none 不算
+func (c *PluginConfig) usesBuiltinTemplate() bool {
+	return c.TemplateInline == "" && c.TemplateFile == "" && c.Template != NoTemplate
+}
+
+// usesBuiltinTemplate 服务 s 实际使用的服务模板是否为内置模板：(registry.template) 只能指定内置模板，
+// 未指定时取决于插件配置
+func (g *Generator) usesBuiltinTemplate(s *ServiceInfo) bool {
+	return s.template != "" || g.config.usesBuiltinTemplate()
+}
+
+// templateSource 描述服务模板的来源，用于 dry_run 等输出
+func templateSource(config *PluginConfig) string {
+	switch {
+	case config.TemplateInline != "":
+		return "template_inline"
+	case config.TemplateFile != "":
+		return config.TemplateFile
+	default:
+		return config.Template
+	}
+}
+
+// loadTemplateFile 从本地路径读取模板
+func loadTemplateFile(path string) (string, error) {
+	// 检查模板文件是否存在
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return "", fmt.Errorf("模板文件不存在: %s", path)
+	}
+
+	// 读取模板文件
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("读取模板文件失败: %v", err)
+	}
+
+	return string(content), nil
+}
+
+// LoadBuiltinTemplate 加载内置模板
+func LoadBuiltinTemplate(name string) (string, error) {
+	content, err := builtinTemplates.ReadFile("templates/" + name + ".tmpl")
+	if err != nil {
+		return "", fmt.Errorf("内置模板不存在: %s", name)
+	}
+	return string(content), nil
+}
+
+// BuiltinTemplates 返回所有内置模板名称
+func BuiltinTemplates() []string {
+	entries, _ := fs.ReadDir(builtinTemplates, "templates")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".tmpl"))
+	}
+	return names
+}
@@ -0,0 +1,54 @@
+package generator
+
+import "testing"
+
+// TestCheckAggregateDependencies 内置服务模板依赖 audit_events、tenant_context、feature_flags 与 middleware 汇总模板中的函数，
+// 服务通过 (registry.template) 指定的内置模板同样如此
+func TestCheckAggregateDependencies(t *testing.T) {
+	// template_inline 为 base64 编码的 package x
+	tests := []struct {
+		name     string
+		param    string
+		template string // 服务的 (registry.template)
+		wantErr  bool
+	}{
+		{name: "内置模板", param: "", wantErr: true},
+		{name: "启用汇总模板", param: "aggregate_template=audit_events+tenant_context+feature_flags+middleware"},
+		{name: "自定义模板", param: "template_inline=cGFja2FnZSB4"},
+		{name: "自定义模板，服务指定内置模板", param: "template_inline=cGFja2FnZSB4", template: "registry_backend", wantErr: true},
+		{name: "template=none", param: "template=none"},
+		{name: "template=none，服务指定内置模板", param: "template=none", template: "registry_backend", wantErr: true},
+		{name: "服务指定内置模板并启用汇总模板", param: "aggregate_template=audit_events+tenant_context+feature_flags+middleware", template: "registry_backend"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ParsePluginOptions(tt.param)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g, err := New(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := &ServiceInfo{
+				FullName:    "shop.v1.OrderService",
+				FeatureFlag: "orders",
+				Middleware:  []string{"auth"},
+				Methods:     []*MethodInfo{{Name: "CreateOrder", Audit: &AuditInfo{}, TenantField: &TenantFieldInfo{}}},
+				template:    tt.template,
+			}
+			if err := g.checkAudit(s); (err != nil) != tt.wantErr {
+				t.Errorf("checkAudit 的错误为 %v，期望出错: %v", err, tt.wantErr)
+			}
+			if err := g.checkTenantContext(s); (err != nil) != tt.wantErr {
+				t.Errorf("checkTenantContext 的错误为 %v，期望出错: %v", err, tt.wantErr)
+			}
+			if err := g.checkFeatureFlags(s); (err != nil) != tt.wantErr {
+				t.Errorf("checkFeatureFlags 的错误为 %v，期望出错: %v", err, tt.wantErr)
+			}
+			if err := g.checkMiddleware(s); (err != nil) != tt.wantErr {
+				t.Errorf("checkMiddleware 的错误为 %v，期望出错: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
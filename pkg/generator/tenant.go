@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// tenantName 合法的租户名：小写字母开头，由小写字母、数字与 - 组成，可直接用作注册名称的前缀
+var tenantName = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// TenantInfo 服务在某个租户下的注册信息，来自插件参数 tenants 与 tenant_metadata
+type TenantInfo struct {
+	Name           string            // 租户名，如 acme
+	GoName         string            // 租户名对应的 Go 标识符，如 Acme
+	RegisteredName string            // 租户下的注册名称，为 租户名-服务注册名称，如 acme-order-api
+	Metadata       map[string]string // 租户下附加的服务元数据，含 tenant 与 tenant_metadata 中为该租户指定的键值
+}
+
+// parseTenantMetadata 解析 tenant_metadata 参数，格式为 租户名:key=value
+func parseTenantMetadata(v string) (tenant, key, value string, err error) {
+	tenant, kv, ok := strings.Cut(v, ":")
+	key, value, hasValue := strings.Cut(kv, "=")
+	if !ok || !hasValue || tenant == "" || key == "" {
+		return "", "", "", fmt.Errorf("tenant_metadata 格式错误，应为 租户名:key=value: %s", v)
+	}
+	return strings.TrimSpace(tenant), strings.TrimSpace(key), strings.TrimSpace(value), nil
+}
+
+// validateTenants 校验租户名，tenant_metadata 只能为 tenants 中的租户指定
+func (c *PluginConfig) validateTenants() error {
+	for i, t := range c.Tenants {
+		if !tenantName.MatchString(t) {
+			return fmt.Errorf("租户名只能包含小写字母、数字与 -，且以字母开头: %s", t)
+		}
+		if slices.Contains(c.Tenants[:i], t) {
+			return fmt.Errorf("tenants 中的租户重复: %s", t)
+		}
+	}
+	for _, t := range slices.Sorted(maps.Keys(c.TenantMetadata)) {
+		if !slices.Contains(c.Tenants, t) {
+			return fmt.Errorf("tenant_metadata 中的租户 %s 不在 tenants 中", t)
+		}
+	}
+	return nil
+}
+
+// newTenants 按 tenants 的顺序生成服务的租户信息，RegisteredName 由 setGoName 填充
+func newTenants(config *PluginConfig) []*TenantInfo {
+	tenants := make([]*TenantInfo, 0, len(config.Tenants))
+	for _, name := range config.Tenants {
+		metadata := maps.Clone(config.TenantMetadata[name])
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		metadata["tenant"] = name
+		tenants = append(tenants, &TenantInfo{Name: name, GoName: goExported(name), Metadata: metadata})
+	}
+	return tenants
+}
@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// tenantMetadataPrefix (registry.tenant_field) 中表示从 gRPC metadata 取租户的前缀，如 metadata:x-tenant-id
+const tenantMetadataPrefix = "metadata:"
+
+// metadataKey 合法的 gRPC metadata 键：小写字母、数字与 -、_、.
+var metadataKey = regexp.MustCompile(`^[0-9a-z_.-]+$`)
+
+// tenantContextTemplate 租户上下文汇总模板，内置服务模板注册声明了租户来源的服务时依赖其中的拦截器
+const tenantContextTemplate = "tenant_context"
+
+// checkTenantContext 服务有方法声明了租户来源，但基础配置与各渲染阶段都未启用 tenant_context 汇总模板时：
+// 服务使用的内置模板（包括 (registry.template) 指定的）引用的租户拦截器不存在，生成的代码无法编译，返回错误；自定义模板只提示
+func (g *Generator) checkTenantContext(s *ServiceInfo) error {
+	if !s.HasTenantField() || g.usesAggregateTemplate(tenantContextTemplate) {
+		return nil
+	}
+	if g.usesBuiltinTemplate(s) {
+		return fmt.Errorf("服务 %s 有方法声明了租户来源，内置服务模板依赖 tenant_context 汇总模板中的租户拦截器，须启用 aggregate_template=tenant_context", s.FullName)
+	}
+	g.warn(s.pos, "服务有方法声明了租户来源，但未启用 tenant_context 汇总模板，租户拦截器须自行提供", "service", s.FullName)
+	return nil
+}
+
+// TenantFieldInfo 方法的租户来源
+type TenantFieldInfo struct {
+	Source   string // (registry.tenant_field) 的值，metadata 键转为小写，如 tenant_id、metadata:x-tenant-id
+	Field    string // 存放租户的请求字段路径，如 meta.tenant_id，从 metadata 取租户时为空
+	Value    string // 从请求 req 中取租户的 Go 表达式，如 req.GetMeta().GetTenantId()，从 metadata 取租户时为空
+	Metadata string // 存放租户的 metadata 键，如 x-tenant-id，从请求字段取租户时为空
+
+	err error // 配置不合法，生成时报错
+}
+
+// newTenantField 转换 (registry.tenant_field)，按请求消息 input 解析字段路径；流式方法只能从 metadata 取租户
+func newTenantField(source string, input *protogen.Message, streaming bool) *TenantFieldInfo {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return nil
+	}
+	info := &TenantFieldInfo{Source: source}
+	if key, ok := strings.CutPrefix(source, tenantMetadataPrefix); ok {
+		info.Metadata = strings.ToLower(strings.TrimSpace(key))
+		info.Source = tenantMetadataPrefix + info.Metadata
+		switch {
+		case !metadataKey.MatchString(info.Metadata):
+			info.err = fmt.Errorf("metadata 键只能由字母、数字与 -、_、. 组成: %q", key)
+		case strings.HasPrefix(info.Metadata, "grpc-") || strings.HasSuffix(info.Metadata, "-bin"):
+			info.err = fmt.Errorf("metadata 键不能以 grpc- 开头或以 -bin 结尾: %s", info.Metadata)
+		}
+		return info
+	}
+	if streaming {
+		info.err = fmt.Errorf("流式方法只能从 metadata 取租户，如 %sx-tenant-id", tenantMetadataPrefix)
+		return info
+	}
+	field, value, err := requestField(input, source)
+	switch {
+	case err != nil:
+		info.err = err
+	case field.Desc.Kind() != protoreflect.StringKind || field.Desc.IsList():
+		info.err = fmt.Errorf("字段 %s 须为非 repeated 的 string 字段", source)
+	}
+	info.Field, info.Value = source, value
+	return info
+}
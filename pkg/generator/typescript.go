@@ -0,0 +1,57 @@
+package generator
+
+// tsWellKnown well-known types 在 protojson 编码下对应的 TypeScript 类型
+var tsWellKnown = map[string]string{
+	"google.protobuf.Timestamp":   "string",
+	"google.protobuf.Duration":    "string",
+	"google.protobuf.FieldMask":   "string",
+	"google.protobuf.StringValue": "string",
+	"google.protobuf.BytesValue":  "string",
+	"google.protobuf.Int32Value":  "number",
+	"google.protobuf.UInt32Value": "number",
+	"google.protobuf.Int64Value":  "string",
+	"google.protobuf.UInt64Value": "string",
+	"google.protobuf.FloatValue":  "number",
+	"google.protobuf.DoubleValue": "number",
+	"google.protobuf.BoolValue":   "boolean",
+	"google.protobuf.Struct":      "{ [key: string]: unknown }",
+	"google.protobuf.Value":       "unknown",
+	"google.protobuf.ListValue":   "unknown[]",
+	"google.protobuf.Any":         `{ "@type": string; [key: string]: unknown }`,
+	"google.protobuf.Empty":       "Record<string, never>",
+}
+
+// tsMessageType 返回消息的 TypeScript 类型：well-known types 为对应的 JSON 类型，其他消息为 typeName
+func tsMessageType(fullName, typeName string) string {
+	if t, ok := tsWellKnown[fullName]; ok {
+		return t
+	}
+	return typeName
+}
+
+// tsType 返回字段的 TypeScript 类型，与 protojson 的编码方式一致
+func tsType(f *FieldInfo) string {
+	if f.Map && f.MapValue != nil {
+		return "{ [key: string]: " + tsValueType(f.MapValue) + " }"
+	}
+	if f.Repeated {
+		return tsValueType(f) + "[]"
+	}
+	return tsValueType(f)
+}
+
+// tsValueType 返回单个值（不考虑 repeated）的 TypeScript 类型
+func tsValueType(f *FieldInfo) string {
+	switch f.Kind {
+	case "message":
+		return tsMessageType(f.TypeFull, f.TypeName)
+	case "enum":
+		return f.TypeName
+	case "bool":
+		return "boolean"
+	case "int32", "sint32", "sfixed32", "uint32", "fixed32", "float", "double":
+		return "number"
+	}
+	// string、bytes（base64）与 protojson 编码为字符串的 64 位整数
+	return "string"
+}
@@ -0,0 +1,91 @@
+package generator
+
+import (
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// 校验约束选项的扩展字段编号，插件不依赖 protovalidate 与 PGV，按编号识别
+const (
+	protovalidateExtension = 1159 // buf.validate.field / buf.validate.message / buf.validate.oneof
+	pgvExtension           = 1071 // validate.rules / validate.required
+)
+
+// hasConstraints 判断消息本身或其字段引用的消息（直接或间接）是否声明了校验约束
+//
+// protovalidate 与 PGV 都会递归校验嵌套消息，因此只要可达的消息中有约束，
+// 请求就需要校验。
+func hasConstraints(message *protogen.Message) bool {
+	return reachesConstraints(message, map[*protogen.Message]bool{})
+}
+
+func reachesConstraints(message *protogen.Message, visited map[*protogen.Message]bool) bool {
+	if visited[message] {
+		return false
+	}
+	visited[message] = true
+
+	if hasConstraintOption(message.Desc.Options(), protovalidateExtension) {
+		return true
+	}
+	for _, oneof := range message.Oneofs {
+		if hasConstraintOption(oneof.Desc.Options(), protovalidateExtension, pgvExtension) {
+			return true
+		}
+	}
+	for _, field := range message.Fields {
+		if hasConstraintOption(field.Desc.Options(), protovalidateExtension, pgvExtension) {
+			return true
+		}
+		// map 字段的 Message 为 map entry，其 value 字段引用值消息
+		if field.Message != nil && reachesConstraints(field.Message, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasConstraintOption 判断选项中是否设置了指定编号的扩展
+//
+// 插件进程未注册对应扩展时，扩展以未知字段的形式保留在选项中，两种情况都需要检查。
+func hasConstraintOption(opts proto.Message, numbers ...protowire.Number) bool {
+	if opts == nil {
+		return false
+	}
+	m := opts.ProtoReflect()
+	if !m.IsValid() {
+		return false
+	}
+
+	wanted := func(n protowire.Number) bool {
+		for _, number := range numbers {
+			if n == number {
+				return true
+			}
+		}
+		return false
+	}
+
+	found := false
+	m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		found = fd.IsExtension() && wanted(fd.Number())
+		return !found
+	})
+	if found {
+		return true
+	}
+
+	for b := m.GetUnknown(); len(b) > 0; {
+		n, _, length := protowire.ConsumeField(b)
+		if length < 0 {
+			return false
+		}
+		if wanted(n) {
+			return true
+		}
+		b = b[length:]
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// valuesKey 配置文件中声明注入模板常量的键，等价于逐项指定 set 参数:
+//
+//	values:
+//	  cluster: prod-sh
+//	  default_namespace: payments
+const valuesKey = "values"
+
+// valueKey 常量名须为合法的模板字段名，才能以 .Values.key 引用
+var valueKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// parseValue 解析 set 参数，格式为 key=value，value 可为空
+func parseValue(v string) (key, value string, err error) {
+	key, value, ok := strings.Cut(v, "=")
+	key = strings.TrimSpace(key)
+	if !ok || !valueKey.MatchString(key) {
+		return "", "", fmt.Errorf("set 格式错误，应为 key=value，key 只能包含字母、数字与下划线且不以数字开头: %s", v)
+	}
+	return key, value, nil
+}
+
+// applyValues 按配置文件中的 values 映射设置注入模板的常量，值只能为标量
+func (c *PluginConfig) applyValues(v any) error {
+	values, ok := v.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s 应为 key: value 映射", valuesKey)
+	}
+	for key, value := range values {
+		switch value.(type) {
+		case map[string]any, []any:
+			return fmt.Errorf("%s.%s 只能为字符串、数字或布尔值", valuesKey, key)
+		}
+		if value == nil {
+			value = ""
+		}
+		if err := c.set("set", key+"="+fmt.Sprint(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newValues 返回模板数据中的常量，未设置时为空映射，保证 .Values.key 总是可用
+func newValues(config *PluginConfig) map[string]string {
+	if config.Values == nil {
+		return map[string]string{}
+	}
+	return config.Values
+}
@@ -0,0 +1,104 @@
+package generator
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// versionPattern 匹配 proto 包名中的版本段，如 v1、v2beta1、v3alpha
+var versionPattern = regexp.MustCompile(`^v(\d+)(?:(alpha|beta)(\d*))?$`)
+
+// VersionGroup 同一逻辑服务的多个版本
+type VersionGroup struct {
+	Name     string         // 逻辑服务名称（kebab-case），如 order
+	Latest   *ServiceInfo   // 最新版本
+	Services []*ServiceInfo // 按版本从新到旧排列
+}
+
+// detectVersion 从 proto 包名的最后一段识别版本号，返回版本号与去掉版本段的包名
+// 例如: "pages.order.v2" -> ("v2", "pages.order"), "pages.order" -> ("", "pages.order")
+func detectVersion(protoPackage string) (string, string) {
+	i := strings.LastIndex(protoPackage, ".")
+	last := protoPackage[i+1:]
+	if !versionPattern.MatchString(last) {
+		return "", protoPackage
+	}
+	if i < 0 {
+		return last, ""
+	}
+	return last, protoPackage[:i]
+}
+
+// compareVersions 比较两个版本号，稳定版高于 beta，beta 高于 alpha
+func compareVersions(a, b string) int {
+	ka, kb := versionKey(a), versionKey(b)
+	for i := range ka {
+		if ka[i] != kb[i] {
+			if ka[i] < kb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionKey 将版本号转换为可比较的 [主版本, 阶段, 阶段序号]
+func versionKey(version string) [3]int {
+	m := versionPattern.FindStringSubmatch(version)
+	if m == nil {
+		return [3]int{}
+	}
+	major, _ := strconv.Atoi(m[1])
+	stage := map[string]int{"alpha": 0, "beta": 1, "": 2}[m[2]]
+	n, _ := strconv.Atoi(m[3])
+	return [3]int{major, stage, n}
+}
+
+// versionGroups 找出存在多个版本的逻辑服务，保持首次出现的顺序
+func versionGroups(services []*ServiceInfo) []*VersionGroup {
+	var groups []*VersionGroup
+	byName := make(map[string]*VersionGroup)
+	for _, s := range services {
+		if s.Version == "" {
+			continue
+		}
+		group, ok := byName[s.logicalName]
+		if !ok {
+			group = &VersionGroup{}
+			byName[s.logicalName] = group
+			groups = append(groups, group)
+		}
+		group.Services = append(group.Services, s)
+	}
+
+	result := groups[:0]
+	for _, group := range groups {
+		if len(group.Services) < 2 {
+			continue
+		}
+		sort.SliceStable(group.Services, func(i, j int) bool {
+			return compareVersions(group.Services[i].Version, group.Services[j].Version) > 0
+		})
+		group.Latest = group.Services[0]
+		group.Name = toKebabCase(group.Latest.ServiceName)
+		result = append(result, group)
+	}
+	return result
+}
+
+// resolveVersions 为多版本服务追加版本后缀，避免生成的标识符与文件名冲突，并将旧版本标记为废弃
+// 例如 pages.order.v1.OrderService 与 pages.order.v2.OrderService 的 GoName 分别为 OrderV1、OrderV2
+func resolveVersions(services []*ServiceInfo) {
+	for _, group := range versionGroups(services) {
+		for _, s := range group.Services {
+			s.setGoName(s.ServiceName + strings.ToUpper(s.Version[:1]) + s.Version[1:])
+			s.LatestVersion = group.Latest.Version
+			if s != group.Latest {
+				s.Deprecated = true
+			}
+		}
+	}
+}
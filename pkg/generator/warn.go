@@ -0,0 +1,158 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// 警告级别，来自插件参数 warnings
+const (
+	warningsWarn  = "warn"  // 输出到 stderr，不影响生成
+	warningsError = "error" // 存在警告时生成失败，用于严格的 CI
+	warningsOff   = "off"   // 不输出警告
+)
+
+// warn 记录非致命问题，pos 为 proto 文件或模板中的位置，如 user/user.proto:15:1；
+// args 为 slog 风格的键值对
+func (g *Generator) warn(pos, msg string, args ...any) {
+	text := pos + ": " + msg
+	if len(args) > 0 {
+		pairs := make([]string, 0, len(args)/2)
+		for i := 0; i+1 < len(args); i += 2 {
+			pairs = append(pairs, fmt.Sprintf("%v=%v", args[i], args[i+1]))
+		}
+		text += " (" + strings.Join(pairs, ", ") + ")"
+	}
+	// 渲染阶段的警告记录到所属生成器，各阶段对同一服务的相同警告只输出一次
+	if g.parent != nil {
+		g.parent.warn(pos, msg, args...)
+		return
+	}
+	if slices.Contains(g.warnings, text) {
+		return
+	}
+	g.warnings = append(g.warnings, text)
+	if g.config.Warnings != warningsOff {
+		g.log.Warn(msg, append([]any{"pos", pos}, args...)...)
+	}
+}
+
+// checkWarnings warnings=error 时存在警告则返回错误，列出全部警告
+func (g *Generator) checkWarnings() error {
+	if g.config.Warnings != warningsError || len(g.warnings) == 0 {
+		return nil
+	}
+	return fmt.Errorf("warnings=error，共 %d 条警告:\n  %s", len(g.warnings), strings.Join(g.warnings, "\n  "))
+}
+
+// warnService 检查服务定义中的可疑之处：没有方法、服务名没有 Service 后缀
+func (g *Generator) warnService(s *ServiceInfo) {
+	if len(s.Methods) == 0 {
+		g.warn(s.pos, "服务没有声明任何方法", "service", s.FullName)
+	}
+	if !strings.HasSuffix(s.FullName, "Service") {
+		g.warn(s.pos, "服务名没有 Service 后缀，生成代码使用完整的服务名", "service", s.FullName, "go_name", s.GoName)
+	}
+}
+
+// usesAggregateTemplate 基础配置或任一渲染阶段是否启用了汇总模板 name
+func (g *Generator) usesAggregateTemplate(name string) bool {
+	root := g
+	for root.parent != nil {
+		root = root.parent
+	}
+	if slices.Contains(root.config.AggregateTemplates, name) {
+		return true
+	}
+	for _, pass := range root.config.Passes {
+		if slices.Contains(pass.AggregateTemplates, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// warnUnusedVariables 检查模板中声明后从未使用的变量，range 的下标变量除外；
+// source 为模板来源，用于替换位置中的模板名，如模板文件路径
+func (g *Generator) warnUnusedVariables(tmpl *template.Template, source string) {
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil || t.Tree.Root == nil {
+			continue
+		}
+		declared := map[string]*parse.VariableNode{}
+		var order []string
+		used := map[string]bool{}
+		var walk func(node parse.Node)
+		walkPipe := func(pipe *parse.PipeNode, isRange bool) {
+			if pipe == nil {
+				return
+			}
+			for i, v := range pipe.Decl {
+				if pipe.IsAssign {
+					used[v.Ident[0]] = true
+					continue
+				}
+				if isRange && len(pipe.Decl) == 2 && i == 0 {
+					continue
+				}
+				if _, ok := declared[v.Ident[0]]; !ok {
+					order = append(order, v.Ident[0])
+				}
+				declared[v.Ident[0]] = v
+			}
+			for _, cmd := range pipe.Cmds {
+				walk(cmd)
+			}
+		}
+		walk = func(node parse.Node) {
+			switch n := node.(type) {
+			case *parse.ListNode:
+				if n == nil {
+					return
+				}
+				for _, child := range n.Nodes {
+					walk(child)
+				}
+			case *parse.ActionNode:
+				walkPipe(n.Pipe, false)
+			case *parse.IfNode:
+				walkPipe(n.Pipe, false)
+				walk(n.List)
+				walk(n.ElseList)
+			case *parse.WithNode:
+				walkPipe(n.Pipe, false)
+				walk(n.List)
+				walk(n.ElseList)
+			case *parse.RangeNode:
+				walkPipe(n.Pipe, true)
+				walk(n.List)
+				walk(n.ElseList)
+			case *parse.TemplateNode:
+				walkPipe(n.Pipe, false)
+			case *parse.CommandNode:
+				for _, arg := range n.Args {
+					walk(arg)
+				}
+			case *parse.PipeNode:
+				walkPipe(n, false)
+			case *parse.ChainNode:
+				walk(n.Node)
+			case *parse.VariableNode:
+				used[n.Ident[0]] = true
+			}
+		}
+		walk(t.Tree.Root)
+		for _, name := range order {
+			if !used[name] {
+				location, _ := t.Tree.ErrorContext(declared[name])
+				if t.Name() == tmpl.Name() {
+					location = source + strings.TrimPrefix(location, t.Name())
+				}
+				g.warn(location, "模板变量声明后未使用", "variable", name)
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// defaultZookeeperRoot zookeeper_registry 汇总模板默认的根节点
+const defaultZookeeperRoot = "/services"
+
+// ZookeeperInfo zookeeper_registry 汇总模板的配置，来自插件参数 zk_root 与 zk_acl
+//
+// 实例注册为临时节点 <Root>/<注册名称>/<host:port>，节点内容为 JSON 格式的实例信息；
+// 根节点与服务节点为持久节点，不存在时以 ACLs 创建。
+type ZookeeperInfo struct {
+	Root string          // 根节点路径，如 /services
+	ACLs []*ZookeeperACL // 创建节点使用的 ACL，按参数顺序排列，为空时模板使用 world:anyone 全部权限
+}
+
+// ZookeeperACL 一条 Zookeeper ACL，参数格式为 scheme:id:perms，如 world:anyone:r、digest:user:BASE64HASH:crwda
+type ZookeeperACL struct {
+	Scheme    string   // 认证方式，如 world、digest、ip、auth
+	ID        string   // 认证标识，如 anyone、user:BASE64HASH、10.0.0.0/8
+	Perms     string   // 权限字母，由 c（create）、r（read）、w（write）、d（delete）、a（admin）组成
+	PermNames []string // 权限名称，与 go-zookeeper 的常量对应，如 Read 对应 zk.PermRead
+}
+
+// zookeeperPerms 权限字母与权限名称
+var zookeeperPerms = []struct {
+	letter byte
+	name   string
+}{
+	{'c', "Create"},
+	{'r', "Read"},
+	{'w', "Write"},
+	{'d', "Delete"},
+	{'a', "Admin"},
+}
+
+// parseZookeeperACL 解析 zk_acl 参数，ID 中可以包含冒号
+func parseZookeeperACL(v string) (*ZookeeperACL, error) {
+	scheme, rest, ok := strings.Cut(v, ":")
+	i := strings.LastIndex(rest, ":")
+	if !ok || scheme == "" || i < 0 {
+		return nil, fmt.Errorf("zk_acl 格式应为 scheme:id:perms: %s", v)
+	}
+	acl := &ZookeeperACL{Scheme: scheme, ID: rest[:i], Perms: rest[i+1:]}
+	if acl.Perms == "" {
+		return nil, fmt.Errorf("zk_acl 未指定权限: %s", v)
+	}
+	for _, p := range zookeeperPerms {
+		if strings.IndexByte(acl.Perms, p.letter) >= 0 {
+			acl.PermNames = append(acl.PermNames, p.name)
+		}
+	}
+	if strings.Trim(acl.Perms, "crwda") != "" || len(acl.PermNames) != len(acl.Perms) {
+		return nil, fmt.Errorf("zk_acl 的权限只能由 c、r、w、d、a 组成且不能重复: %s", v)
+	}
+	return acl, nil
+}
+
+// parseZookeeperRoot 解析 zk_root 参数，须为绝对路径
+func parseZookeeperRoot(v string) (string, error) {
+	if !strings.HasPrefix(v, "/") || path.Clean(v) != v || v == "/" {
+		return "", fmt.Errorf("zk_root 应为不以 / 结尾的绝对路径，如 /services: %s", v)
+	}
+	return v, nil
+}
+
+// newZookeeper 由插件参数生成 zookeeper_registry 汇总模板的配置
+func newZookeeper(config *PluginConfig) *ZookeeperInfo {
+	return &ZookeeperInfo{Root: config.ZookeeperRoot, ACLs: config.ZookeeperACLs}
+}
+
+// zookeeperACLString 返回 zk_acl 参数的展示值
+func zookeeperACLString(acls []*ZookeeperACL) string {
+	items := make([]string, len(acls))
+	for i, acl := range acls {
+		items[i] = acl.Scheme + ":" + acl.ID + ":" + acl.Perms
+	}
+	return strings.Join(items, ";")
+}
@@ -0,0 +1,259 @@
+// Package registrytest 提供模板回归测试的辅助函数
+//
+// 模板作者可以在自己的仓库中编译 testdata 下的 proto 文件、运行生成器，
+// 并将输出与 golden 文件比较:
+//
+//	func TestTemplate(t *testing.T) {
+//		files := registrytest.Run(t, registrytest.Options{
+//			ImportPaths: []string{"testdata"},
+//			Files:       []string{"user/user.proto"},
+//			Parameter:   "template_file=../templates/service.tmpl",
+//		})
+//		registrytest.Golden(t, "testdata/golden", files)
+//	}
+//
+// 设置环境变量 UPDATE_GOLDEN=1 运行测试时会重写 golden 文件。
+// golden 目录下只有 .golden 文件由 Golden 管理，其他文件不受影响。
+package registrytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"github.com/lhdbsbz/protoc-gen-service-registry/pkg/generator"
+	"github.com/lhdbsbz/protoc-gen-service-registry/registry"
+)
+
+// UpdateEnv 设置为 1 时 Golden 重写 golden 文件而不是比较
+const UpdateEnv = "UPDATE_GOLDEN"
+
+// Options 生成参数
+type Options struct {
+	ImportPaths []string // proto 搜索路径，默认为 testdata
+	Files       []string // 需要生成的 proto 文件，相对于搜索路径
+	Parameter   string   // 插件参数，与 --service-registry_opt 相同
+}
+
+// Run 编译 proto 并运行生成器，返回输出文件名到内容的映射，失败时终止测试
+//
+// 插入点代码以 "目标文件#插入点" 为键返回。
+func Run(t testing.TB, opts Options) map[string]string {
+	t.Helper()
+	files, err := Generate(opts)
+	if err != nil {
+		t.Fatalf("生成失败: %v", err)
+	}
+	return files
+}
+
+// Generate 编译 proto 并运行生成器，返回输出文件名到内容的映射
+//
+// registry/annotations.proto 无需放在搜索路径中，会直接使用本模块内置的定义。
+func Generate(opts Options) (map[string]string, error) {
+	req, err := Request(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := generator.ParsePluginOptions(req.GetParameter())
+	if err != nil {
+		return nil, fmt.Errorf("解析插件参数失败: %v", err)
+	}
+	gen, err := protogen.Options{}.New(req)
+	if err != nil {
+		return nil, err
+	}
+	g, err := generator.New(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.Generate(gen); err != nil {
+		return nil, err
+	}
+
+	resp := g.Response(gen)
+	if resp.Error != nil {
+		return nil, errors.New(resp.GetError())
+	}
+	files := make(map[string]string, len(resp.File))
+	for _, f := range resp.File {
+		name := f.GetName()
+		if f.GetInsertionPoint() != "" {
+			name += "#" + f.GetInsertionPoint()
+		}
+		files[name] += f.GetContent()
+	}
+	return files, nil
+}
+
+// Request 编译 proto 文件并构造 CodeGeneratorRequest，ProtoFile 按依赖顺序排列
+func Request(opts Options) (*pluginpb.CodeGeneratorRequest, error) {
+	importPaths := opts.ImportPaths
+	if len(importPaths) == 0 {
+		importPaths = []string{"testdata"}
+	}
+
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(protocompile.CompositeResolver{
+			&protocompile.SourceResolver{ImportPaths: importPaths},
+			protocompile.ResolverFunc(func(path string) (protocompile.SearchResult, error) {
+				if path == registry.File_registry_annotations_proto.Path() {
+					return protocompile.SearchResult{Desc: registry.File_registry_annotations_proto}, nil
+				}
+				return protocompile.SearchResult{}, os.ErrNotExist
+			}),
+		}),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+	compiled, err := compiler.Compile(context.Background(), opts.Files...)
+	if err != nil {
+		return nil, fmt.Errorf("编译 proto 失败: %v", err)
+	}
+
+	var protoFiles []*descriptorpb.FileDescriptorProto
+	seen := map[string]bool{}
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		protoFiles = append(protoFiles, protodesc.ToFileDescriptorProto(fd))
+	}
+	for _, fd := range compiled {
+		add(fd)
+	}
+
+	// 经过一次序列化，与 protoc 传给插件的请求保持一致：
+	// 编译得到的自定义选项是动态消息，需要按已注册的扩展重新解析
+	raw, err := proto.Marshal(&pluginpb.CodeGeneratorRequest{
+		FileToGenerate: opts.Files,
+		Parameter:      proto.String(opts.Parameter),
+		ProtoFile:      protoFiles,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req := &pluginpb.CodeGeneratorRequest{}
+	if err := proto.Unmarshal(raw, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Golden 将生成结果与 dir 下的 golden 文件逐个比较，文件名为输出文件名加 .golden 后缀
+//
+// 设置了 UPDATE_GOLDEN=1 时重写 golden 文件，并删除生成结果中已不存在的 golden 文件。
+// dir 下多出的 golden 文件同样视为不一致；Golden 只读写以 .golden 结尾的文件，其他文件保持不变。
+func Golden(t testing.TB, dir string, files map[string]string) {
+	t.Helper()
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	expected := map[string]bool{}
+	for _, name := range names {
+		expected[filepath.Clean(goldenPath(dir, name))] = true
+	}
+	stale, err := staleGoldenFiles(dir, expected)
+	if err != nil {
+		t.Fatalf("读取 golden 目录失败: %v", err)
+	}
+
+	if os.Getenv(UpdateEnv) == "1" {
+		for _, path := range stale {
+			if err := os.Remove(path); err != nil {
+				t.Fatalf("删除多余的 golden 文件失败: %v", err)
+			}
+		}
+		for _, name := range names {
+			path := goldenPath(dir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatalf("创建目录失败: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(files[name]), 0o644); err != nil {
+				t.Fatalf("写入 golden 文件失败: %v", err)
+			}
+		}
+		return
+	}
+
+	for _, name := range names {
+		path := goldenPath(dir, name)
+		want, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("读取 golden 文件 %s 失败（设置 %s=1 生成）: %v", path, UpdateEnv, err)
+			continue
+		}
+		if got := files[name]; got != string(want) {
+			t.Errorf("%s 与 golden 文件 %s 不一致（设置 %s=1 更新）:\n%s", name, path, UpdateEnv, firstDifference(string(want), got))
+		}
+	}
+	for _, path := range stale {
+		t.Errorf("多余的 golden 文件 %s，生成结果中不存在对应文件（设置 %s=1 删除）", path, UpdateEnv)
+	}
+}
+
+// staleGoldenFiles 返回 dir 下不在 expected 中的 golden 文件，dir 不存在时返回空
+func staleGoldenFiles(dir string, expected map[string]bool) ([]string, error) {
+	var stale []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".golden") && !expected[filepath.Clean(path)] {
+			stale = append(stale, path)
+		}
+		return nil
+	})
+	return stale, err
+}
+
+// goldenPath 返回输出文件对应的 golden 文件路径，插入点中的 # 替换为 .
+func goldenPath(dir, name string) string {
+	return filepath.Join(dir, filepath.FromSlash(strings.ReplaceAll(name, "#", "."))+".golden")
+}
+
+// firstDifference 返回第一处不一致的行，便于定位
+func firstDifference(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("第 %d 行\n  want: %q\n  got:  %q", i+1, w, g)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+package registrytest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recorder 记录 Golden 报告的错误，不让测试本身失败
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.TB.Fatalf(format, args...)
+}
+
+func generateGreeter(t *testing.T) map[string]string {
+	t.Helper()
+	return Run(t, Options{Files: []string{"greeter/greeter.proto"}})
+}
+
+func TestGoldenRoundTrip(t *testing.T) {
+	files := generateGreeter(t)
+	if len(files) == 0 {
+		t.Fatal("没有生成任何文件")
+	}
+	dir := t.TempDir()
+
+	t.Setenv(UpdateEnv, "1")
+	Golden(t, dir, files)
+
+	t.Setenv(UpdateEnv, "")
+	r := &recorder{TB: t}
+	Golden(r, dir, files)
+	if len(r.errors) > 0 {
+		t.Errorf("重写后比较应一致，实际报告:\n%s", strings.Join(r.errors, "\n"))
+	}
+}
+
+func TestGoldenMismatch(t *testing.T) {
+	files := generateGreeter(t)
+	dir := t.TempDir()
+	t.Setenv(UpdateEnv, "1")
+	Golden(t, dir, files)
+	t.Setenv(UpdateEnv, "")
+
+	changed := make(map[string]string, len(files))
+	for name, content := range files {
+		changed[name] = content + "// 修改\n"
+	}
+	r := &recorder{TB: t}
+	Golden(r, dir, changed)
+	if len(r.errors) != len(files) {
+		t.Fatalf("应报告 %d 处不一致，实际报告:\n%s", len(files), strings.Join(r.errors, "\n"))
+	}
+	if !strings.Contains(r.errors[0], "不一致") || !strings.Contains(r.errors[0], "// 修改") {
+		t.Errorf("错误信息应包含不一致的行: %s", r.errors[0])
+	}
+}
+
+func TestGoldenStaleFiles(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "old.go.golden")
+	other := filepath.Join(dir, "README.md")
+	for _, path := range []string{stale, other} {
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{"a/b.go": "package b\n"}
+
+	r := &recorder{TB: t}
+	Golden(r, dir, files)
+	if len(r.errors) != 2 || !strings.Contains(strings.Join(r.errors, "\n"), "多余的 golden 文件 "+stale) {
+		t.Errorf("应报告缺少的 golden 文件与多余的 %s，实际报告:\n%s", stale, strings.Join(r.errors, "\n"))
+	}
+
+	t.Setenv(UpdateEnv, "1")
+	Golden(t, dir, files)
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("重写时应删除多余的 golden 文件 %s", stale)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("重写时不应删除非 golden 文件 %s: %v", other, err)
+	}
+	if got, err := os.ReadFile(filepath.Join(dir, "a", "b.go.golden")); err != nil || string(got) != files["a/b.go"] {
+		t.Errorf("golden 文件内容为 %q（%v），期望 %q", got, err, files["a/b.go"])
+	}
+}
+
+func TestGoldenMissingDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "missing")
+	r := &recorder{TB: t}
+	Golden(r, dir, map[string]string{"a.go": "package a\n"})
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "读取 golden 文件") {
+		t.Errorf("golden 目录不存在时应只报告缺少的文件，实际报告:\n%s", strings.Join(r.errors, "\n"))
+	}
+}
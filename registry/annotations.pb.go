@@ -0,0 +1,1195 @@
+// 服务注册相关的自定义选项
+//
+// 下游 proto 通过 import "registry/annotations.proto" 引入后即可在服务上声明:
+//
+//   service OrderService {
+//     option (registry.name) = "order-api";
+//     option (registry.tags) = "payments";
+//     option (registry.metadata) = {key: "owner" value: "team-x"};
+//   }
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: registry/annotations.proto
+
+package registry
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// MetadataEntry 服务元数据键值对
+type MetadataEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetadataEntry) Reset() {
+	*x = MetadataEntry{}
+	mi := &file_registry_annotations_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetadataEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetadataEntry) ProtoMessage() {}
+
+func (x *MetadataEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_registry_annotations_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetadataEntry.ProtoReflect.Descriptor instead.
+func (*MetadataEntry) Descriptor() ([]byte, []int) {
+	return file_registry_annotations_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MetadataEntry) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *MetadataEntry) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+// RateLimit 方法限流配置，按服务实例生效
+type RateLimit struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 每秒允许的请求数，必须大于 0
+	Qps float64 `protobuf:"fixed64,1,opt,name=qps,proto3" json:"qps,omitempty"`
+	// 允许的突发请求数，默认为 qps 向上取整
+	Burst         int32 `protobuf:"varint,2,opt,name=burst,proto3" json:"burst,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RateLimit) Reset() {
+	*x = RateLimit{}
+	mi := &file_registry_annotations_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RateLimit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateLimit) ProtoMessage() {}
+
+func (x *RateLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_registry_annotations_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateLimit.ProtoReflect.Descriptor instead.
+func (*RateLimit) Descriptor() ([]byte, []int) {
+	return file_registry_annotations_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RateLimit) GetQps() float64 {
+	if x != nil {
+		return x.Qps
+	}
+	return 0
+}
+
+func (x *RateLimit) GetBurst() int32 {
+	if x != nil {
+		return x.Burst
+	}
+	return 0
+}
+
+// CircuitBreaker 客户端熔断策略，仅对一元方法生效
+type CircuitBreaker struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 连续失败（UNAVAILABLE、DEADLINE_EXCEEDED 等服务端故障）达到该次数时熔断，必须大于 0
+	FailureThreshold uint32 `protobuf:"varint,1,opt,name=failure_threshold,json=failureThreshold,proto3" json:"failure_threshold,omitempty"`
+	// 熔断持续时间，之后进入半开状态放行探测请求，如 10s，默认 30s
+	OpenTimeout string `protobuf:"bytes,2,opt,name=open_timeout,json=openTimeout,proto3" json:"open_timeout,omitempty"`
+	// 半开状态下放行的探测请求数，全部成功后恢复，默认 1
+	HalfOpenRequests uint32 `protobuf:"varint,3,opt,name=half_open_requests,json=halfOpenRequests,proto3" json:"half_open_requests,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *CircuitBreaker) Reset() {
+	*x = CircuitBreaker{}
+	mi := &file_registry_annotations_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CircuitBreaker) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CircuitBreaker) ProtoMessage() {}
+
+func (x *CircuitBreaker) ProtoReflect() protoreflect.Message {
+	mi := &file_registry_annotations_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CircuitBreaker.ProtoReflect.Descriptor instead.
+func (*CircuitBreaker) Descriptor() ([]byte, []int) {
+	return file_registry_annotations_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CircuitBreaker) GetFailureThreshold() uint32 {
+	if x != nil {
+		return x.FailureThreshold
+	}
+	return 0
+}
+
+func (x *CircuitBreaker) GetOpenTimeout() string {
+	if x != nil {
+		return x.OpenTimeout
+	}
+	return ""
+}
+
+func (x *CircuitBreaker) GetHalfOpenRequests() uint32 {
+	if x != nil {
+		return x.HalfOpenRequests
+	}
+	return 0
+}
+
+// Retry 客户端重试策略，用于 istio.yaml 等网格配置
+type Retry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 最多重试次数，必须大于 0
+	Attempts uint32 `protobuf:"varint,1,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	// 每次尝试的超时时间，如 2s，未指定时由网格决定
+	PerTryTimeout string `protobuf:"bytes,2,opt,name=per_try_timeout,json=perTryTimeout,proto3" json:"per_try_timeout,omitempty"`
+	// 触发重试的条件，如 unavailable、deadline-exceeded、5xx，未指定时由网格决定
+	RetryOn       []string `protobuf:"bytes,3,rep,name=retry_on,json=retryOn,proto3" json:"retry_on,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Retry) Reset() {
+	*x = Retry{}
+	mi := &file_registry_annotations_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Retry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Retry) ProtoMessage() {}
+
+func (x *Retry) ProtoReflect() protoreflect.Message {
+	mi := &file_registry_annotations_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Retry.ProtoReflect.Descriptor instead.
+func (*Retry) Descriptor() ([]byte, []int) {
+	return file_registry_annotations_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Retry) GetAttempts() uint32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *Retry) GetPerTryTimeout() string {
+	if x != nil {
+		return x.PerTryTimeout
+	}
+	return ""
+}
+
+func (x *Retry) GetRetryOn() []string {
+	if x != nil {
+		return x.RetryOn
+	}
+	return nil
+}
+
+// Cache 一元方法的响应缓存
+type Cache struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 缓存时长，如 30s，必须为正时长
+	Ttl string `protobuf:"bytes,1,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	// 组成缓存键的请求字段路径，如 name、filter.tags，只能是标量、枚举或它们的 repeated 字段；
+	// 未指定时以整个请求消息为键
+	KeyFields     []string `protobuf:"bytes,2,rep,name=key_fields,json=keyFields,proto3" json:"key_fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Cache) Reset() {
+	*x = Cache{}
+	mi := &file_registry_annotations_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Cache) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Cache) ProtoMessage() {}
+
+func (x *Cache) ProtoReflect() protoreflect.Message {
+	mi := &file_registry_annotations_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Cache.ProtoReflect.Descriptor instead.
+func (*Cache) Descriptor() ([]byte, []int) {
+	return file_registry_annotations_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Cache) GetTtl() string {
+	if x != nil {
+		return x.Ttl
+	}
+	return ""
+}
+
+func (x *Cache) GetKeyFields() []string {
+	if x != nil {
+		return x.KeyFields
+	}
+	return nil
+}
+
+// Idempotency 一元方法的幂等键，相同幂等键的重复请求返回首次成功调用的响应
+type Idempotency struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 存放幂等键的请求字段路径，如 request_id、meta.idempotency_key，须为非 repeated 的 string 字段
+	KeyField string `protobuf:"bytes,1,opt,name=key_field,json=keyField,proto3" json:"key_field,omitempty"`
+	// 幂等键的有效期，如 24h，未指定时为 24h
+	Ttl           string `protobuf:"bytes,2,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Idempotency) Reset() {
+	*x = Idempotency{}
+	mi := &file_registry_annotations_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Idempotency) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Idempotency) ProtoMessage() {}
+
+func (x *Idempotency) ProtoReflect() protoreflect.Message {
+	mi := &file_registry_annotations_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Idempotency.ProtoReflect.Descriptor instead.
+func (*Idempotency) Descriptor() ([]byte, []int) {
+	return file_registry_annotations_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Idempotency) GetKeyField() string {
+	if x != nil {
+		return x.KeyField
+	}
+	return ""
+}
+
+func (x *Idempotency) GetTtl() string {
+	if x != nil {
+		return x.Ttl
+	}
+	return ""
+}
+
+// Limits 方法的消息大小与截止时间限制，由 method_limits 汇总模板中的拦截器执行
+type Limits struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 请求消息编码后的最大字节数，超过时返回 RESOURCE_EXHAUSTED，0 表示不限制
+	MaxRequestBytes uint32 `protobuf:"varint,1,opt,name=max_request_bytes,json=maxRequestBytes,proto3" json:"max_request_bytes,omitempty"`
+	// 响应消息编码后的最大字节数，超过时返回 RESOURCE_EXHAUSTED，0 表示不限制
+	MaxResponseBytes uint32 `protobuf:"varint,2,opt,name=max_response_bytes,json=maxResponseBytes,proto3" json:"max_response_bytes,omitempty"`
+	// 请求未携带截止时间时使用的截止时间，如 5s，未指定时不设置
+	DefaultDeadline string `protobuf:"bytes,3,opt,name=default_deadline,json=defaultDeadline,proto3" json:"default_deadline,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Limits) Reset() {
+	*x = Limits{}
+	mi := &file_registry_annotations_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Limits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Limits) ProtoMessage() {}
+
+func (x *Limits) ProtoReflect() protoreflect.Message {
+	mi := &file_registry_annotations_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Limits.ProtoReflect.Descriptor instead.
+func (*Limits) Descriptor() ([]byte, []int) {
+	return file_registry_annotations_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Limits) GetMaxRequestBytes() uint32 {
+	if x != nil {
+		return x.MaxRequestBytes
+	}
+	return 0
+}
+
+func (x *Limits) GetMaxResponseBytes() uint32 {
+	if x != nil {
+		return x.MaxResponseBytes
+	}
+	return 0
+}
+
+func (x *Limits) GetDefaultDeadline() string {
+	if x != nil {
+		return x.DefaultDeadline
+	}
+	return ""
+}
+
+var file_registry_annotations_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         52001,
+		Name:          "registry.name",
+		Tag:           "bytes,52001,opt,name=name",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: ([]string)(nil),
+		Field:         52002,
+		Name:          "registry.tags",
+		Tag:           "bytes,52002,rep,name=tags",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*int32)(nil),
+		Field:         52003,
+		Name:          "registry.weight",
+		Tag:           "varint,52003,opt,name=weight",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         52004,
+		Name:          "registry.namespace",
+		Tag:           "bytes,52004,opt,name=namespace",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*bool)(nil),
+		Field:         52005,
+		Name:          "registry.skip",
+		Tag:           "varint,52005,opt,name=skip",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         52006,
+		Name:          "registry.template",
+		Tag:           "bytes,52006,opt,name=template",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: ([]*MetadataEntry)(nil),
+		Field:         52007,
+		Name:          "registry.metadata",
+		Tag:           "bytes,52007,rep,name=metadata",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         52008,
+		Name:          "registry.group",
+		Tag:           "bytes,52008,opt,name=group",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*int32)(nil),
+		Field:         52009,
+		Name:          "registry.priority",
+		Tag:           "varint,52009,opt,name=priority",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: ([]string)(nil),
+		Field:         52010,
+		Name:          "registry.depends_on",
+		Tag:           "bytes,52010,rep,name=depends_on",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         52011,
+		Name:          "registry.enabled_when",
+		Tag:           "bytes,52011,opt,name=enabled_when",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         52012,
+		Name:          "registry.load_balancing",
+		Tag:           "bytes,52012,opt,name=load_balancing",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: ([]string)(nil),
+		Field:         52013,
+		Name:          "registry.grpc_web_origins",
+		Tag:           "bytes,52013,rep,name=grpc_web_origins",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*bool)(nil),
+		Field:         52014,
+		Name:          "registry.twirp",
+		Tag:           "varint,52014,opt,name=twirp",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         52015,
+		Name:          "registry.default_error_code",
+		Tag:           "bytes,52015,opt,name=default_error_code",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*CircuitBreaker)(nil),
+		Field:         52016,
+		Name:          "registry.default_circuit_breaker",
+		Tag:           "bytes,52016,opt,name=default_circuit_breaker",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*int32)(nil),
+		Field:         52017,
+		Name:          "registry.grpc_port",
+		Tag:           "varint,52017,opt,name=grpc_port",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: ([]string)(nil),
+		Field:         52018,
+		Name:          "registry.owner",
+		Tag:           "bytes,52018,rep,name=owner",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         52019,
+		Name:          "registry.oncall",
+		Tag:           "bytes,52019,opt,name=oncall",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         52020,
+		Name:          "registry.feature_flag",
+		Tag:           "bytes,52020,opt,name=feature_flag",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*int32)(nil),
+		Field:         52021,
+		Name:          "registry.conn_pool_size",
+		Tag:           "varint,52021,opt,name=conn_pool_size",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: ([]string)(nil),
+		Field:         52022,
+		Name:          "registry.middleware",
+		Tag:           "bytes,52022,rep,name=middleware",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: ([]string)(nil),
+		Field:         52023,
+		Name:          "registry.depends_on_clients",
+		Tag:           "bytes,52023,rep,name=depends_on_clients",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         52024,
+		Name:          "registry.dubbo_interface",
+		Tag:           "bytes,52024,opt,name=dubbo_interface",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         52025,
+		Name:          "registry.dubbo_version",
+		Tag:           "bytes,52025,opt,name=dubbo_version",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: ([]string)(nil),
+		Field:         52026,
+		Name:          "registry.allowed_callers",
+		Tag:           "bytes,52026,rep,name=allowed_callers",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         52027,
+		Name:          "registry.spiffe_id",
+		Tag:           "bytes,52027,opt,name=spiffe_id",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         52028,
+		Name:          "registry.schema_module",
+		Tag:           "bytes,52028,opt,name=schema_module",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: ([]string)(nil),
+		Field:         52029,
+		Name:          "registry.schema_labels",
+		Tag:           "bytes,52029,rep,name=schema_labels",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*bool)(nil),
+		Field:         52101,
+		Name:          "registry.grpc_web",
+		Tag:           "varint,52101,opt,name=grpc_web",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         52102,
+		Name:          "registry.error_code",
+		Tag:           "bytes,52102,opt,name=error_code",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*RateLimit)(nil),
+		Field:         52103,
+		Name:          "registry.rate_limit",
+		Tag:           "bytes,52103,opt,name=rate_limit",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*CircuitBreaker)(nil),
+		Field:         52104,
+		Name:          "registry.circuit_breaker",
+		Tag:           "bytes,52104,opt,name=circuit_breaker",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         52105,
+		Name:          "registry.method_feature_flag",
+		Tag:           "bytes,52105,opt,name=method_feature_flag",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         52106,
+		Name:          "registry.timeout",
+		Tag:           "bytes,52106,opt,name=timeout",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*Retry)(nil),
+		Field:         52107,
+		Name:          "registry.retry",
+		Tag:           "bytes,52107,opt,name=retry",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*Cache)(nil),
+		Field:         52108,
+		Name:          "registry.cache",
+		Tag:           "bytes,52108,opt,name=cache",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*Idempotency)(nil),
+		Field:         52109,
+		Name:          "registry.idempotency",
+		Tag:           "bytes,52109,opt,name=idempotency",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*bool)(nil),
+		Field:         52110,
+		Name:          "registry.audit",
+		Tag:           "varint,52110,opt,name=audit",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: ([]string)(nil),
+		Field:         52111,
+		Name:          "registry.audit_resource",
+		Tag:           "bytes,52111,rep,name=audit_resource",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*Limits)(nil),
+		Field:         52112,
+		Name:          "registry.limits",
+		Tag:           "bytes,52112,opt,name=limits",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         52113,
+		Name:          "registry.routing_key",
+		Tag:           "bytes,52113,opt,name=routing_key",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         52114,
+		Name:          "registry.tenant_field",
+		Tag:           "bytes,52114,opt,name=tenant_field",
+		Filename:      "registry/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*bool)(nil),
+		Field:         52201,
+		Name:          "registry.sensitive",
+		Tag:           "varint,52201,opt,name=sensitive",
+		Filename:      "registry/annotations.proto",
+	},
+}
+
+// Extension fields to descriptorpb.ServiceOptions.
+var (
+	// 对外注册的服务名称，覆盖由 proto 服务名推导出的名称
+	//
+	// optional string name = 52001;
+	E_Name = &file_registry_annotations_proto_extTypes[0]
+	// 服务标签
+	//
+	// repeated string tags = 52002;
+	E_Tags = &file_registry_annotations_proto_extTypes[1]
+	// 服务权重
+	//
+	// optional int32 weight = 52003;
+	E_Weight = &file_registry_annotations_proto_extTypes[2]
+	// 服务所属命名空间
+	//
+	// optional string namespace = 52004;
+	E_Namespace = &file_registry_annotations_proto_extTypes[3]
+	// 为 true 时不为该服务生成任何代码
+	//
+	// optional bool skip = 52005;
+	E_Skip = &file_registry_annotations_proto_extTypes[4]
+	// 为该服务使用的内置模板名称，覆盖插件参数中的 template
+	//
+	// optional string template = 52006;
+	E_Template = &file_registry_annotations_proto_extTypes[5]
+	// 服务元数据
+	//
+	// repeated registry.MetadataEntry metadata = 52007;
+	E_Metadata = &file_registry_annotations_proto_extTypes[6]
+	// 服务分组，覆盖由 proto 包名推导出的分组
+	//
+	// optional string group = 52008;
+	E_Group = &file_registry_annotations_proto_extTypes[7]
+	// 注册优先级，数值越小越先注册，决定 RegisterAll 等汇总输出中的服务顺序
+	//
+	// optional int32 priority = 52009;
+	E_Priority = &file_registry_annotations_proto_extTypes[8]
+	// 依赖的服务，可以是 proto 服务名（UserService）或全限定名（user.UserService），
+	// 被依赖的服务会先于当前服务注册
+	//
+	// repeated string depends_on = 52010;
+	E_DependsOn = &file_registry_annotations_proto_extTypes[9]
+	// 启用条件，不满足时生成的注册函数不注册该服务:
+	//   env:NAME        环境变量 NAME 非空时启用
+	//   env:NAME=value  环境变量 NAME 等于 value 时启用
+	//   build:expr      满足构建约束 expr（如 canary && !prod）时启用
+	//
+	// optional string enabled_when = 52011;
+	E_EnabledWhen = &file_registry_annotations_proto_extTypes[10]
+	// 客户端负载均衡策略，对应 gRPC 服务配置中的 loadBalancingConfig，默认 round_robin
+	//
+	// optional string load_balancing = 52012;
+	E_LoadBalancing = &file_registry_annotations_proto_extTypes[11]
+	// 允许通过 gRPC-Web 访问该服务的来源（Origin），* 表示任意来源；非空时启用 gRPC-Web
+	//
+	// repeated string grpc_web_origins = 52013;
+	E_GrpcWebOrigins = &file_registry_annotations_proto_extTypes[12]
+	// 服务同时生成了 Twirp 代码（protoc-gen-twirp），twirp 汇总模板会为其生成处理器；Twirp 不支持流式方法
+	//
+	// optional bool twirp = 52014;
+	E_Twirp = &file_registry_annotations_proto_extTypes[13]
+	// 处理函数返回非 gRPC 状态错误时使用的状态码，取值为 gRPC 状态码名称，如 UNAVAILABLE，默认 UNKNOWN
+	//
+	// optional string default_error_code = 52015;
+	E_DefaultErrorCode = &file_registry_annotations_proto_extTypes[14]
+	// 客户端调用该服务各方法时的熔断策略，方法上的 (registry.circuit_breaker) 优先
+	//
+	// optional registry.CircuitBreaker default_circuit_breaker = 52016;
+	E_DefaultCircuitBreaker = &file_registry_annotations_proto_extTypes[15]
+	// 服务监听的 gRPC 端口，用于部署描述等非代码输出，未指定时由模板决定默认值
+	//
+	// optional int32 grpc_port = 52017;
+	E_GrpcPort = &file_registry_annotations_proto_extTypes[16]
+	// 服务负责人（个人或团队），可重复指定，写入 docs_dir 下的 OWNERS 与 README.md
+	//
+	// repeated string owner = 52018;
+	E_Owner = &file_registry_annotations_proto_extTypes[17]
+	// 值班联系方式，如值班表或告警频道
+	//
+	// optional string oncall = 52019;
+	E_Oncall = &file_registry_annotations_proto_extTypes[18]
+	// 功能开关名称，开关关闭时生成的注册函数不注册该服务，用于暗发布；
+	// 开关状态由 feature_flags 汇总模板中的 FeatureFlags 查询
+	//
+	// optional string feature_flag = 52020;
+	E_FeatureFlag = &file_registry_annotations_proto_extTypes[19]
+	// conn_manager 汇总模板中该服务的连接池大小，覆盖插件参数 conn_pool_size
+	//
+	// optional int32 conn_pool_size = 52021;
+	E_ConnPoolSize = &file_registry_annotations_proto_extTypes[20]
+	// 服务端拦截器的名称，按声明顺序组成拦截器链（先声明的在外层），拦截器由 middleware 汇总模板中的
+	// Middlewares 按名称提供，如 ["auth", "ratelimit", "tracing"]
+	//
+	// repeated string middleware = 52022;
+	E_Middleware = &file_registry_annotations_proto_extTypes[21]
+	// 启动时须先就绪的下游服务，写法同 depends_on；registry_backend 模板生成的服务在这些服务的
+	// 健康检查返回 SERVING 之前报告 NOT_SERVING，依赖之间不能存在循环
+	//
+	// repeated string depends_on_clients = 52023;
+	E_DependsOnClients = &file_registry_annotations_proto_extTypes[22]
+	// dubbo_triple 汇总模板中服务在 Dubbo 注册中心的接口名，默认为 proto 服务全限定名
+	//
+	// optional string dubbo_interface = 52024;
+	E_DubboInterface = &file_registry_annotations_proto_extTypes[23]
+	// dubbo_triple 汇总模板中服务在 Dubbo 注册中心的版本，默认为从 proto 包名识别出的版本号
+	//
+	// optional string dubbo_version = 52025;
+	E_DubboVersion = &file_registry_annotations_proto_extTypes[24]
+	// 允许调用该服务的服务，写法同 depends_on，未参与本次生成的服务按注册名称处理，* 表示任意服务；
+	// consul_intentions.json 服务模板据此生成 Consul Connect intentions，其余调用方默认拒绝
+	//
+	// repeated string allowed_callers = 52026;
+	E_AllowedCallers = &file_registry_annotations_proto_extTypes[25]
+	// 服务的 SPIFFE ID，可写完整的 spiffe://<信任域>/<路径>，或只写路径（如 /ns/pay/sa/pay-server，信任域来自
+	// 插件参数 spiffe_trust_domain）；未指定时为 spiffe://<信任域>/ns/<命名空间>/sa/<注册名称>，
+	// 命名空间与注册名称转换为 DNS 标签，与 Istio 的工作负载身份一致
+	//
+	// optional string spiffe_id = 52027;
+	E_SpiffeId = &file_registry_annotations_proto_extTypes[26]
+	// schema_manifest.yaml 汇总模板中服务所在 proto 文件推送到的 schema registry 模块，如 buf.build/acme/payments，
+	// 覆盖插件参数 schema_module；都未指定的服务不写入清单
+	//
+	// optional string schema_module = 52028;
+	E_SchemaModule = &file_registry_annotations_proto_extTypes[27]
+	// 推送模块时附加的标签，如 release-2024-06；从 proto 包名识别出的版本号自动作为标签
+	//
+	// repeated string schema_labels = 52029;
+	E_SchemaLabels = &file_registry_annotations_proto_extTypes[28]
+)
+
+// Extension fields to descriptorpb.MethodOptions.
+var (
+	// 通过 gRPC-Web 暴露该方法；服务中没有任何方法声明时，启用了 gRPC-Web 的服务暴露全部方法
+	//
+	// optional bool grpc_web = 52101;
+	E_GrpcWeb = &file_registry_annotations_proto_extTypes[29]
+	// 处理函数返回非 gRPC 状态错误时使用的状态码，优先于服务上的 (registry.default_error_code)
+	//
+	// optional string error_code = 52102;
+	E_ErrorCode = &file_registry_annotations_proto_extTypes[30]
+	// 方法限流，超过时返回 RESOURCE_EXHAUSTED
+	//
+	// optional registry.RateLimit rate_limit = 52103;
+	E_RateLimit = &file_registry_annotations_proto_extTypes[31]
+	// 客户端调用该方法时的熔断策略，优先于服务上的 (registry.default_circuit_breaker)
+	//
+	// optional registry.CircuitBreaker circuit_breaker = 52104;
+	E_CircuitBreaker = &file_registry_annotations_proto_extTypes[32]
+	// 方法的功能开关名称，开关关闭时调用返回 UNIMPLEMENTED，服务本身照常注册
+	//
+	// optional string method_feature_flag = 52105;
+	E_MethodFeatureFlag = &file_registry_annotations_proto_extTypes[33]
+	// 客户端调用该方法的超时时间，如 3s，用于 istio.yaml 等网格配置
+	//
+	// optional string timeout = 52106;
+	E_Timeout = &file_registry_annotations_proto_extTypes[34]
+	// 客户端调用该方法的重试策略，用于 istio.yaml 等网格配置
+	//
+	// optional registry.Retry retry = 52107;
+	E_Retry = &file_registry_annotations_proto_extTypes[35]
+	// 一元方法的响应缓存，response_cache 汇总模板据此生成包装服务实现的缓存装饰器
+	//
+	// optional registry.Cache cache = 52108;
+	E_Cache = &file_registry_annotations_proto_extTypes[36]
+	// 一元方法的幂等键，idempotency 汇总模板据此生成校验幂等键的拦截器，缺少幂等键的请求返回 INVALID_ARGUMENT
+	//
+	// optional registry.Idempotency idempotency = 52109;
+	E_Idempotency = &file_registry_annotations_proto_extTypes[37]
+	// 调用结束后输出审计事件（调用方、方法、资源与结果），registry_backend 与 local_service_center 模板
+	// 注册服务时总是挂载 audit_events 汇总模板中的审计拦截器，未设置 AuditEvents 时注册失败
+	//
+	// optional bool audit = 52110;
+	E_Audit = &file_registry_annotations_proto_extTypes[38]
+	// 审计事件中的资源，为请求字段路径，如 order_id、order.id，只能是标量、枚举或它们的 repeated 字段；
+	// 声明时即视为 (registry.audit) = true，流式方法不能声明
+	//
+	// repeated string audit_resource = 52111;
+	E_AuditResource = &file_registry_annotations_proto_extTypes[39]
+	// 方法的请求大小、响应大小与默认截止时间
+	//
+	// optional registry.Limits limits = 52112;
+	E_Limits = &file_registry_annotations_proto_extTypes[40]
+	// 路由键所在的请求字段路径，如 user_id、order.shard_key，须为非 repeated 的字符串、整数或枚举字段；
+	// routing_key 汇总模板据此生成从请求中取路由键的函数，供分片调度使用
+	//
+	// optional string routing_key = 52113;
+	E_RoutingKey = &file_registry_annotations_proto_extTypes[41]
+	// 租户的来源：请求字段路径，如 tenant_id、meta.tenant_id，须为非 repeated 的 string 字段；
+	// 或 metadata:<键>，如 metadata:x-tenant-id，从 gRPC metadata 取租户，流式方法只能使用这种形式。
+	// registry_backend 与 local_service_center 模板注册服务时挂载 tenant_context 汇总模板中的拦截器，
+	// 将租户写入 context，缺少租户的请求返回 INVALID_ARGUMENT
+	//
+	// optional string tenant_field = 52114;
+	E_TenantField = &file_registry_annotations_proto_extTypes[42]
+)
+
+// Extension fields to descriptorpb.FieldOptions.
+var (
+	// 敏感字段，生成的日志装饰器记录请求与响应时会脱敏
+	//
+	// optional bool sensitive = 52201;
+	E_Sensitive = &file_registry_annotations_proto_extTypes[43]
+)
+
+var File_registry_annotations_proto protoreflect.FileDescriptor
+
+const file_registry_annotations_proto_rawDesc = "" +
+	"\n" +
+	"\x1aregistry/annotations.proto\x12\bregistry\x1a google/protobuf/descriptor.proto\"7\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"3\n" +
+	"\tRateLimit\x12\x10\n" +
+	"\x03qps\x18\x01 \x01(\x01R\x03qps\x12\x14\n" +
+	"\x05burst\x18\x02 \x01(\x05R\x05burst\"\x8e\x01\n" +
+	"\x0eCircuitBreaker\x12+\n" +
+	"\x11failure_threshold\x18\x01 \x01(\rR\x10failureThreshold\x12!\n" +
+	"\fopen_timeout\x18\x02 \x01(\tR\vopenTimeout\x12,\n" +
+	"\x12half_open_requests\x18\x03 \x01(\rR\x10halfOpenRequests\"f\n" +
+	"\x05Retry\x12\x1a\n" +
+	"\battempts\x18\x01 \x01(\rR\battempts\x12&\n" +
+	"\x0fper_try_timeout\x18\x02 \x01(\tR\rperTryTimeout\x12\x19\n" +
+	"\bretry_on\x18\x03 \x03(\tR\aretryOn\"8\n" +
+	"\x05Cache\x12\x10\n" +
+	"\x03ttl\x18\x01 \x01(\tR\x03ttl\x12\x1d\n" +
+	"\n" +
+	"key_fields\x18\x02 \x03(\tR\tkeyFields\"<\n" +
+	"\vIdempotency\x12\x1b\n" +
+	"\tkey_field\x18\x01 \x01(\tR\bkeyField\x12\x10\n" +
+	"\x03ttl\x18\x02 \x01(\tR\x03ttl\"\x8d\x01\n" +
+	"\x06Limits\x12*\n" +
+	"\x11max_request_bytes\x18\x01 \x01(\rR\x0fmaxRequestBytes\x12,\n" +
+	"\x12max_response_bytes\x18\x02 \x01(\rR\x10maxResponseBytes\x12)\n" +
+	"\x10default_deadline\x18\x03 \x01(\tR\x0fdefaultDeadline:5\n" +
+	"\x04name\x12\x1f.google.protobuf.ServiceOptions\x18\xa1\x96\x03 \x01(\tR\x04name:5\n" +
+	"\x04tags\x12\x1f.google.protobuf.ServiceOptions\x18\xa2\x96\x03 \x03(\tR\x04tags:9\n" +
+	"\x06weight\x12\x1f.google.protobuf.ServiceOptions\x18\xa3\x96\x03 \x01(\x05R\x06weight:?\n" +
+	"\tnamespace\x12\x1f.google.protobuf.ServiceOptions\x18\xa4\x96\x03 \x01(\tR\tnamespace:5\n" +
+	"\x04skip\x12\x1f.google.protobuf.ServiceOptions\x18\xa5\x96\x03 \x01(\bR\x04skip:=\n" +
+	"\btemplate\x12\x1f.google.protobuf.ServiceOptions\x18\xa6\x96\x03 \x01(\tR\btemplate:V\n" +
+	"\bmetadata\x12\x1f.google.protobuf.ServiceOptions\x18\xa7\x96\x03 \x03(\v2\x17.registry.MetadataEntryR\bmetadata:7\n" +
+	"\x05group\x12\x1f.google.protobuf.ServiceOptions\x18\xa8\x96\x03 \x01(\tR\x05group:=\n" +
+	"\bpriority\x12\x1f.google.protobuf.ServiceOptions\x18\xa9\x96\x03 \x01(\x05R\bpriority:@\n" +
+	"\n" +
+	"depends_on\x12\x1f.google.protobuf.ServiceOptions\x18\xaa\x96\x03 \x03(\tR\tdependsOn:D\n" +
+	"\fenabled_when\x12\x1f.google.protobuf.ServiceOptions\x18\xab\x96\x03 \x01(\tR\venabledWhen:H\n" +
+	"\x0eload_balancing\x12\x1f.google.protobuf.ServiceOptions\x18\xac\x96\x03 \x01(\tR\rloadBalancing:K\n" +
+	"\x10grpc_web_origins\x12\x1f.google.protobuf.ServiceOptions\x18\xad\x96\x03 \x03(\tR\x0egrpcWebOrigins:7\n" +
+	"\x05twirp\x12\x1f.google.protobuf.ServiceOptions\x18\xae\x96\x03 \x01(\bR\x05twirp:O\n" +
+	"\x12default_error_code\x12\x1f.google.protobuf.ServiceOptions\x18\xaf\x96\x03 \x01(\tR\x10defaultErrorCode:s\n" +
+	"\x17default_circuit_breaker\x12\x1f.google.protobuf.ServiceOptions\x18\xb0\x96\x03 \x01(\v2\x18.registry.CircuitBreakerR\x15defaultCircuitBreaker:>\n" +
+	"\tgrpc_port\x12\x1f.google.protobuf.ServiceOptions\x18\xb1\x96\x03 \x01(\x05R\bgrpcPort:7\n" +
+	"\x05owner\x12\x1f.google.protobuf.ServiceOptions\x18\xb2\x96\x03 \x03(\tR\x05owner:9\n" +
+	"\x06oncall\x12\x1f.google.protobuf.ServiceOptions\x18\xb3\x96\x03 \x01(\tR\x06oncall:D\n" +
+	"\ffeature_flag\x12\x1f.google.protobuf.ServiceOptions\x18\xb4\x96\x03 \x01(\tR\vfeatureFlag:G\n" +
+	"\x0econn_pool_size\x12\x1f.google.protobuf.ServiceOptions\x18\xb5\x96\x03 \x01(\x05R\fconnPoolSize:A\n" +
+	"\n" +
+	"middleware\x12\x1f.google.protobuf.ServiceOptions\x18\xb6\x96\x03 \x03(\tR\n" +
+	"middleware:O\n" +
+	"\x12depends_on_clients\x12\x1f.google.protobuf.ServiceOptions\x18\xb7\x96\x03 \x03(\tR\x10dependsOnClients:J\n" +
+	"\x0fdubbo_interface\x12\x1f.google.protobuf.ServiceOptions\x18\xb8\x96\x03 \x01(\tR\x0edubboInterface:F\n" +
+	"\rdubbo_version\x12\x1f.google.protobuf.ServiceOptions\x18\xb9\x96\x03 \x01(\tR\fdubboVersion:J\n" +
+	"\x0fallowed_callers\x12\x1f.google.protobuf.ServiceOptions\x18\xba\x96\x03 \x03(\tR\x0eallowedCallers:>\n" +
+	"\tspiffe_id\x12\x1f.google.protobuf.ServiceOptions\x18\xbb\x96\x03 \x01(\tR\bspiffeId:F\n" +
+	"\rschema_module\x12\x1f.google.protobuf.ServiceOptions\x18\xbc\x96\x03 \x01(\tR\fschemaModule:F\n" +
+	"\rschema_labels\x12\x1f.google.protobuf.ServiceOptions\x18\xbd\x96\x03 \x03(\tR\fschemaLabels:;\n" +
+	"\bgrpc_web\x12\x1e.google.protobuf.MethodOptions\x18\x85\x97\x03 \x01(\bR\agrpcWeb:?\n" +
+	"\n" +
+	"error_code\x12\x1e.google.protobuf.MethodOptions\x18\x86\x97\x03 \x01(\tR\terrorCode:T\n" +
+	"\n" +
+	"rate_limit\x12\x1e.google.protobuf.MethodOptions\x18\x87\x97\x03 \x01(\v2\x13.registry.RateLimitR\trateLimit:c\n" +
+	"\x0fcircuit_breaker\x12\x1e.google.protobuf.MethodOptions\x18\x88\x97\x03 \x01(\v2\x18.registry.CircuitBreakerR\x0ecircuitBreaker:P\n" +
+	"\x13method_feature_flag\x12\x1e.google.protobuf.MethodOptions\x18\x89\x97\x03 \x01(\tR\x11methodFeatureFlag::\n" +
+	"\atimeout\x12\x1e.google.protobuf.MethodOptions\x18\x8a\x97\x03 \x01(\tR\atimeout:G\n" +
+	"\x05retry\x12\x1e.google.protobuf.MethodOptions\x18\x8b\x97\x03 \x01(\v2\x0f.registry.RetryR\x05retry:G\n" +
+	"\x05cache\x12\x1e.google.protobuf.MethodOptions\x18\x8c\x97\x03 \x01(\v2\x0f.registry.CacheR\x05cache:Y\n" +
+	"\vidempotency\x12\x1e.google.protobuf.MethodOptions\x18\x8d\x97\x03 \x01(\v2\x15.registry.IdempotencyR\vidempotency:6\n" +
+	"\x05audit\x12\x1e.google.protobuf.MethodOptions\x18\x8e\x97\x03 \x01(\bR\x05audit:G\n" +
+	"\x0eaudit_resource\x12\x1e.google.protobuf.MethodOptions\x18\x8f\x97\x03 \x03(\tR\rauditResource:J\n" +
+	"\x06limits\x12\x1e.google.protobuf.MethodOptions\x18\x90\x97\x03 \x01(\v2\x10.registry.LimitsR\x06limits:A\n" +
+	"\vrouting_key\x12\x1e.google.protobuf.MethodOptions\x18\x91\x97\x03 \x01(\tR\n" +
+	"routingKey:C\n" +
+	"\ftenant_field\x12\x1e.google.protobuf.MethodOptions\x18\x92\x97\x03 \x01(\tR\vtenantField:=\n" +
+	"\tsensitive\x12\x1d.google.protobuf.FieldOptions\x18\xe9\x97\x03 \x01(\bR\tsensitiveBBZ@github.com/lhdbsbz/protoc-gen-service-registry/registry;registryb\x06proto3"
+
+var (
+	file_registry_annotations_proto_rawDescOnce sync.Once
+	file_registry_annotations_proto_rawDescData []byte
+)
+
+func file_registry_annotations_proto_rawDescGZIP() []byte {
+	file_registry_annotations_proto_rawDescOnce.Do(func() {
+		file_registry_annotations_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_registry_annotations_proto_rawDesc), len(file_registry_annotations_proto_rawDesc)))
+	})
+	return file_registry_annotations_proto_rawDescData
+}
+
+var file_registry_annotations_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_registry_annotations_proto_goTypes = []any{
+	(*MetadataEntry)(nil),               // 0: registry.MetadataEntry
+	(*RateLimit)(nil),                   // 1: registry.RateLimit
+	(*CircuitBreaker)(nil),              // 2: registry.CircuitBreaker
+	(*Retry)(nil),                       // 3: registry.Retry
+	(*Cache)(nil),                       // 4: registry.Cache
+	(*Idempotency)(nil),                 // 5: registry.Idempotency
+	(*Limits)(nil),                      // 6: registry.Limits
+	(*descriptorpb.ServiceOptions)(nil), // 7: google.protobuf.ServiceOptions
+	(*descriptorpb.MethodOptions)(nil),  // 8: google.protobuf.MethodOptions
+	(*descriptorpb.FieldOptions)(nil),   // 9: google.protobuf.FieldOptions
+}
+var file_registry_annotations_proto_depIdxs = []int32{
+	7,  // 0: registry.name:extendee -> google.protobuf.ServiceOptions
+	7,  // 1: registry.tags:extendee -> google.protobuf.ServiceOptions
+	7,  // 2: registry.weight:extendee -> google.protobuf.ServiceOptions
+	7,  // 3: registry.namespace:extendee -> google.protobuf.ServiceOptions
+	7,  // 4: registry.skip:extendee -> google.protobuf.ServiceOptions
+	7,  // 5: registry.template:extendee -> google.protobuf.ServiceOptions
+	7,  // 6: registry.metadata:extendee -> google.protobuf.ServiceOptions
+	7,  // 7: registry.group:extendee -> google.protobuf.ServiceOptions
+	7,  // 8: registry.priority:extendee -> google.protobuf.ServiceOptions
+	7,  // 9: registry.depends_on:extendee -> google.protobuf.ServiceOptions
+	7,  // 10: registry.enabled_when:extendee -> google.protobuf.ServiceOptions
+	7,  // 11: registry.load_balancing:extendee -> google.protobuf.ServiceOptions
+	7,  // 12: registry.grpc_web_origins:extendee -> google.protobuf.ServiceOptions
+	7,  // 13: registry.twirp:extendee -> google.protobuf.ServiceOptions
+	7,  // 14: registry.default_error_code:extendee -> google.protobuf.ServiceOptions
+	7,  // 15: registry.default_circuit_breaker:extendee -> google.protobuf.ServiceOptions
+	7,  // 16: registry.grpc_port:extendee -> google.protobuf.ServiceOptions
+	7,  // 17: registry.owner:extendee -> google.protobuf.ServiceOptions
+	7,  // 18: registry.oncall:extendee -> google.protobuf.ServiceOptions
+	7,  // 19: registry.feature_flag:extendee -> google.protobuf.ServiceOptions
+	7,  // 20: registry.conn_pool_size:extendee -> google.protobuf.ServiceOptions
+	7,  // 21: registry.middleware:extendee -> google.protobuf.ServiceOptions
+	7,  // 22: registry.depends_on_clients:extendee -> google.protobuf.ServiceOptions
+	7,  // 23: registry.dubbo_interface:extendee -> google.protobuf.ServiceOptions
+	7,  // 24: registry.dubbo_version:extendee -> google.protobuf.ServiceOptions
+	7,  // 25: registry.allowed_callers:extendee -> google.protobuf.ServiceOptions
+	7,  // 26: registry.spiffe_id:extendee -> google.protobuf.ServiceOptions
+	7,  // 27: registry.schema_module:extendee -> google.protobuf.ServiceOptions
+	7,  // 28: registry.schema_labels:extendee -> google.protobuf.ServiceOptions
+	8,  // 29: registry.grpc_web:extendee -> google.protobuf.MethodOptions
+	8,  // 30: registry.error_code:extendee -> google.protobuf.MethodOptions
+	8,  // 31: registry.rate_limit:extendee -> google.protobuf.MethodOptions
+	8,  // 32: registry.circuit_breaker:extendee -> google.protobuf.MethodOptions
+	8,  // 33: registry.method_feature_flag:extendee -> google.protobuf.MethodOptions
+	8,  // 34: registry.timeout:extendee -> google.protobuf.MethodOptions
+	8,  // 35: registry.retry:extendee -> google.protobuf.MethodOptions
+	8,  // 36: registry.cache:extendee -> google.protobuf.MethodOptions
+	8,  // 37: registry.idempotency:extendee -> google.protobuf.MethodOptions
+	8,  // 38: registry.audit:extendee -> google.protobuf.MethodOptions
+	8,  // 39: registry.audit_resource:extendee -> google.protobuf.MethodOptions
+	8,  // 40: registry.limits:extendee -> google.protobuf.MethodOptions
+	8,  // 41: registry.routing_key:extendee -> google.protobuf.MethodOptions
+	8,  // 42: registry.tenant_field:extendee -> google.protobuf.MethodOptions
+	9,  // 43: registry.sensitive:extendee -> google.protobuf.FieldOptions
+	0,  // 44: registry.metadata:type_name -> registry.MetadataEntry
+	2,  // 45: registry.default_circuit_breaker:type_name -> registry.CircuitBreaker
+	1,  // 46: registry.rate_limit:type_name -> registry.RateLimit
+	2,  // 47: registry.circuit_breaker:type_name -> registry.CircuitBreaker
+	3,  // 48: registry.retry:type_name -> registry.Retry
+	4,  // 49: registry.cache:type_name -> registry.Cache
+	5,  // 50: registry.idempotency:type_name -> registry.Idempotency
+	6,  // 51: registry.limits:type_name -> registry.Limits
+	52, // [52:52] is the sub-list for method output_type
+	52, // [52:52] is the sub-list for method input_type
+	44, // [44:52] is the sub-list for extension type_name
+	0,  // [0:44] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_registry_annotations_proto_init() }
+func file_registry_annotations_proto_init() {
+	if File_registry_annotations_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_registry_annotations_proto_rawDesc), len(file_registry_annotations_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 44,
+			NumServices:   0,
+		},
+		GoTypes:           file_registry_annotations_proto_goTypes,
+		DependencyIndexes: file_registry_annotations_proto_depIdxs,
+		MessageInfos:      file_registry_annotations_proto_msgTypes,
+		ExtensionInfos:    file_registry_annotations_proto_extTypes,
+	}.Build()
+	File_registry_annotations_proto = out.File
+	file_registry_annotations_proto_goTypes = nil
+	file_registry_annotations_proto_depIdxs = nil
+}
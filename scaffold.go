@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/lhdbsbz/protoc-gen-service-registry/pkg/generator"
+)
+
+const (
+	scaffoldTemplate = "service_registry.tmpl"         // 示例模板文件名
+	scaffoldConfig   = "service-registry.yaml"         // 示例配置文件名
+	scaffoldBufGen   = "buf.gen.yaml"                  // buf 生成配置文件名
+	scaffoldBufGenEx = "buf.gen.service-registry.yaml" // buf.gen.yaml 已存在时写入的示例片段
+)
+
+// scaffoldConfigContent 示例配置文件，键名与插件参数相同
+const scaffoldConfigContent = `# protoc-gen-service-registry 配置文件
+# 通过插件参数 config=service-registry.yaml 引用，命令行插件参数优先于本文件。
+
+# 模板文件，由 init 从内置模板 ` + generator.DefaultTemplate + ` 复制而来
+template_file: ` + scaffoldTemplate + `
+
+# 输出目录与包名
+output_dir: service_registry
+package_name: service_registry
+
+# 汇总模板，可选，例如:
+# aggregate_template: [registry_lifecycle, consul_registry]
+
+# 服务中心心跳间隔，可选
+# heartbeat_interval: 10s
+`
+
+// scaffoldBufGenContent buf.gen.yaml 中的插件配置片段
+const scaffoldBufGenContent = `version: v2
+plugins:
+  - local: protoc-gen-go
+    out: .
+    opt: paths=source_relative
+  - local: protoc-gen-service-registry
+    out: .
+    opt: config=` + scaffoldConfig + `
+`
+
+// runInit 执行 init 子命令，在目标目录写入示例模板、配置文件与 buf.gen.yaml
+func runInit(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("init", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	dir := flags.String("dir", ".", "写入目录")
+	force := flags.Bool("force", false, "覆盖已存在的文件")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("init 不接受位置参数: %v", flags.Args())
+	}
+
+	tmpl, err := generator.LoadBuiltinTemplate(generator.DefaultTemplate)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+
+	files := []struct{ name, content string }{
+		{scaffoldTemplate, tmpl},
+		{scaffoldConfig, scaffoldConfigContent},
+	}
+	// 已有 buf.gen.yaml 时不做合并，写入单独的示例文件供手动合并
+	bufGen := scaffoldBufGen
+	if exists(filepath.Join(*dir, bufGen)) && !*force {
+		bufGen = scaffoldBufGenEx
+	}
+	files = append(files, struct{ name, content string }{bufGen, scaffoldBufGenContent})
+
+	for _, f := range files {
+		path := filepath.Join(*dir, f.name)
+		if exists(path) && !*force {
+			fmt.Fprintf(stdout, "跳过 %s: 文件已存在，使用 -force 覆盖\n", path)
+			continue
+		}
+		if err := os.WriteFile(path, []byte(f.content), 0o644); err != nil {
+			return fmt.Errorf("写入 %s 失败: %v", path, err)
+		}
+		fmt.Fprintf(stdout, "已写入 %s\n", path)
+	}
+	if bufGen == scaffoldBufGenEx {
+		fmt.Fprintf(stdout, "%s 已存在，请将 %s 中的插件配置合并进去\n", scaffoldBufGen, scaffoldBufGenEx)
+	}
+	return nil
+}
+
+// exists 判断文件是否存在
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, fs.ErrNotExist)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/lhdbsbz/protoc-gen-service-registry/pkg/generator"
+)
+
+// runSchema 执行 schema 子命令，输出模板数据结构与模板函数
+//
+// 内容均从 Go 结构体与函数表反射得到，不会与实现脱节。
+func runSchema(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("schema", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	format := flags.String("format", "markdown", "输出格式: markdown、json 或 dump_data")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	switch *format {
+	case "markdown", "md":
+		writeMarkdownSchema(stdout)
+		return nil
+	case "json":
+		return writeJSONSchema(stdout)
+	case "dump_data":
+		return writeDumpDataSchema(stdout)
+	default:
+		return fmt.Errorf("不支持的输出格式: %s", *format)
+	}
+}
+
+// writeMarkdownSchema 以 Markdown 表格输出模板数据与模板函数
+func writeMarkdownSchema(w io.Writer) {
+	fmt.Fprintln(w, "# protoc-gen-service-registry 模板数据")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "服务模板以 `ServiceInfo` 为数据渲染（每个服务一次），汇总模板以 `AggregateInfo` 为数据渲染（每次生成一次），"+
+		"scope=file 时主模板以 `FileInfo` 为数据渲染（每个 proto 文件一次），scope=all 时主模板以 `AggregateInfo` 为数据渲染一次。")
+
+	for _, typ := range generator.DataTypes(&generator.ServiceInfo{}, &generator.AggregateInfo{}, &generator.FileInfo{}) {
+		fmt.Fprintf(w, "\n## %s\n\n", typ.Name)
+		fmt.Fprintln(w, "| 字段 | 类型 | 说明 |")
+		fmt.Fprintln(w, "| --- | --- | --- |")
+		for _, field := range typ.Fields {
+			note := "-"
+			if field.Deprecated != "" {
+				note = "已废弃: " + field.Deprecated
+			}
+			fmt.Fprintf(w, "| `.%s` | `%s` | %s |\n", field.Name, field.Type, note)
+		}
+	}
+
+	fmt.Fprintln(w, "\n## 模板函数")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| 函数 | 签名 | 说明 |")
+	fmt.Fprintln(w, "| --- | --- | --- |")
+	for _, fn := range generator.TemplateFuncs() {
+		signature := "-"
+		if fn.Signature != "" {
+			signature = "`" + fn.Signature + "`"
+		}
+		usage := fn.Usage
+		if fn.Builtin {
+			usage = "text/template 内置: " + usage
+		}
+		fmt.Fprintf(w, "| `%s` | %s | %s |\n", fn.Name, signature, strings.ReplaceAll(usage, "|", `\|`))
+	}
+}
+
+// writeJSONSchema 输出 ServiceInfo、AggregateInfo 与 FileInfo 的 JSON Schema 及模板函数列表
+func writeJSONSchema(w io.Writer) error {
+	out := map[string]any{
+		"service":   generator.JSONSchema(&generator.ServiceInfo{}),
+		"aggregate": generator.JSONSchema(&generator.AggregateInfo{}),
+		"file":      generator.JSONSchema(&generator.FileInfo{}),
+		"functions": generator.TemplateFuncs(),
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+//go:generate sh -c "go run . schema -format dump_data > schema/dump_data.v1.json"
+
+// writeDumpDataSchema 输出 dump_data 参数生成的 JSON 的 JSON Schema
+//
+// 仓库中的 schema/dump_data.v<版本>.json 由此生成，版本号变化时新增文件，旧版本保留。
+func writeDumpDataSchema(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(generator.DumpDataSchema())
+}
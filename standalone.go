@@ -0,0 +1,176 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"github.com/lhdbsbz/protoc-gen-service-registry/pkg/generator"
+)
+
+// standaloneOptions 独立运行（不经过 protoc）时的命令行参数
+type standaloneOptions struct {
+	descriptorSet string   // FileDescriptorSet 文件路径
+	param         string   // 插件参数
+	out           string   // 输出目录
+	files         []string // 需要生成的 proto 文件
+	verify        bool     // 写出后对生成的 Go 代码做类型检查，仅 generate 支持
+}
+
+// parseStandalone 解析 --check 与 generate 共用的命令行参数
+func parseStandalone(name string, args []string, stdout io.Writer) (*standaloneOptions, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	opts := &standaloneOptions{}
+	flags.StringVar(&opts.descriptorSet, "descriptor_set", "", "FileDescriptorSet 文件路径，需包含所有依赖")
+	flags.StringVar(&opts.param, "param", "", "插件参数，与 --service-registry_opt 相同")
+	flags.StringVar(&opts.out, "out", ".", "输出目录，与 --service-registry_out 相同")
+	if name == "generate" {
+		flags.BoolVar(&opts.verify, "verify", false, "写出并执行 post_hook 后，连同引用的 pb 包对生成的 Go 代码做类型检查")
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if opts.descriptorSet == "" {
+		return nil, fmt.Errorf("必须通过 -descriptor_set 指定 FileDescriptorSet")
+	}
+	opts.files = flags.Args()
+	return opts, nil
+}
+
+// standaloneResponse 在内存中执行生成，返回插件响应
+//
+// 输入为 protoc --descriptor_set_out --include_imports 或 buf build -o 输出的 FileDescriptorSet。
+// fail_fast=false 且部分服务失败时，同时返回包含已生成文件的响应与错误。
+func standaloneResponse(opts *standaloneOptions) (*pluginpb.CodeGeneratorResponse, error) {
+	req, err := descriptorRequest(opts.descriptorSet, opts.param, opts.files)
+	if err != nil {
+		return nil, err
+	}
+	gen, err := newPlugin(req)
+	if err != nil {
+		return nil, err
+	}
+	resp := generate(gen, true)
+	if resp.Error != nil {
+		if len(resp.File) == 0 {
+			return nil, errors.New(resp.GetError())
+		}
+		return resp, errors.New(resp.GetError())
+	}
+	return resp, nil
+}
+
+// descriptorRequest 由 FileDescriptorSet 构造 CodeGeneratorRequest
+//
+// 未指定 files 时，为描述符集合中所有定义了服务的文件生成。
+func descriptorRequest(path, param string, files []string) (*pluginpb.CodeGeneratorRequest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取描述符集合失败: %v", err)
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(content, set); err != nil {
+		return nil, fmt.Errorf("解析描述符集合失败: %v", err)
+	}
+
+	if len(files) == 0 {
+		for _, f := range set.File {
+			if len(f.Service) > 0 {
+				files = append(files, f.GetName())
+			}
+		}
+	}
+	return &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: files,
+		Parameter:      proto.String(param),
+		ProtoFile:      set.File,
+	}, nil
+}
+
+// runGenerate 执行 generate 子命令，不经过 protoc 直接写出生成文件，并依次执行 post_hook；
+// 指定 -verify 时最后对生成的 Go 代码做类型检查
+func runGenerate(args []string, stdout io.Writer) error {
+	opts, err := parseStandalone("generate", args, stdout)
+	if err != nil {
+		return err
+	}
+	resp, genErr := standaloneResponse(opts)
+	if resp == nil {
+		return genErr
+	}
+
+	// 参数已在生成时校验过，这里不会出错
+	config, _ := generator.ParsePluginOptions(opts.param)
+
+	var written []string
+	for _, f := range resp.File {
+		// 插入点的目标文件由其他插件生成，单独运行时无法注入
+		if f.GetInsertionPoint() != "" {
+			fmt.Fprintf(stdout, "跳过插入点 %s#%s\n", f.GetName(), f.GetInsertionPoint())
+			continue
+		}
+		path := filepath.Join(opts.out, f.GetName())
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("创建目录失败: %v", err)
+		}
+		content := []byte(f.GetContent())
+		// 更新已有的 go.mod 时保留 go mod tidy 补充的依赖与 replace 等指令
+		if config.GoMod != "" && f.GetName() == filepath.Join(config.OutputDir, "go.mod") {
+			if existing, err := os.ReadFile(path); err == nil {
+				content = generator.MergeGoMod(existing, content)
+			}
+		}
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			return fmt.Errorf("写入 %s 失败: %v", path, err)
+		}
+		written = append(written, path)
+		fmt.Fprintf(stdout, "已写入 %s\n", path)
+	}
+	// 部分服务生成失败时写出其余文件，但不执行 post_hook
+	if genErr != nil {
+		return genErr
+	}
+
+	for _, hook := range config.PostHooks {
+		if err := runPostHook(hook, opts.out, written, stdout); err != nil {
+			return err
+		}
+	}
+	if opts.verify {
+		return verifyGenerated(opts.out, written, stdout)
+	}
+	return nil
+}
+
+// runPostHook 通过 shell 执行生成后钩子命令
+//
+// 命令在当前目录执行，可通过环境变量 SERVICE_REGISTRY_OUT 获取输出目录，
+// SERVICE_REGISTRY_FILES 获取本次写出的文件（以换行分隔）。
+func runPostHook(command, out string, files []string, stdout io.Writer) error {
+	fmt.Fprintf(stdout, "执行 post_hook: %s\n", command)
+	cmd := exec.Command("sh", "-c", command)
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"SERVICE_REGISTRY_OUT="+out,
+		"SERVICE_REGISTRY_FILES="+strings.Join(files, "\n"),
+	)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("post_hook %q 执行失败: %v", command, err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// verifyGenerated 对本次写出的 Go 文件所在的包做类型检查，引用的 pb 包等依赖按已编译的结果加载
+//
+// 输出目录须位于 Go 模块中，且 protoc-gen-go 等生成的依赖代码已经写出；
+// 存在错误时逐条输出位置与原因，并返回错误。
+func verifyGenerated(out string, written []string, stdout io.Writer) error {
+	var patterns []string
+	for _, path := range written {
+		if filepath.Ext(path) != ".go" {
+			continue
+		}
+		rel, err := filepath.Rel(out, filepath.Dir(path))
+		if err != nil {
+			return fmt.Errorf("校验生成代码失败: %v", err)
+		}
+		pattern := "./" + filepath.ToSlash(rel)
+		if !slices.Contains(patterns, pattern) {
+			patterns = append(patterns, pattern)
+		}
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedTypes,
+		Dir:  out,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return fmt.Errorf("校验生成代码失败: %v", err)
+	}
+	count := 0
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			fmt.Fprintln(stdout, e)
+			count++
+		}
+	}
+	if count > 0 {
+		return fmt.Errorf("校验生成代码失败: %d 个错误", count)
+	}
+	fmt.Fprintf(stdout, "已校验 %d 个包\n", len(pkgs))
+	return nil
+}